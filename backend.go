@@ -0,0 +1,278 @@
+package rq_go
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+)
+
+// Backend performs the operations of RaptorQ sessions. It mirrors the C API of
+// the native library: operations report a return code, and the detailed
+// message of a failure is retrieved with LastError.
+//
+// The default backend is the native library (see NativeBackend). Other
+// implementations, such as the pure-Go backend (see NewPureGoBackend) or the
+// fake in the rqtest package, can be used with NewBackendProcessor.
+//
+// Backend values are used as map keys to tell their sessions apart, so an
+// implementation must be comparable; pointer types are.
+type Backend interface {
+	// InitSession creates a session with the given configuration and returns
+	// its ID, or 0 on failure.
+	InitSession(cfg ProcessorConfig) uintptr
+
+	// FreeSession frees a session and reports whether it existed.
+	FreeSession(sessionID uintptr) bool
+
+	// EncodeFile encodes inputPath into symbol files under outputDir and
+	// writes the layout file into outputDir.
+	EncodeFile(sessionID uintptr, inputPath, outputDir string, blockSize int) (*ProcessResult, ErrorCode)
+
+	// CreateMetadata writes the layout of inputPath to layoutFile without
+	// writing symbols.
+	CreateMetadata(sessionID uintptr, inputPath, layoutFile string, blockSize int) (*ProcessResult, ErrorCode)
+
+	// DecodeSymbols reconstructs the file described by layoutPath from the
+	// symbols in symbolsDir.
+	DecodeSymbols(sessionID uintptr, symbolsDir, outputPath, layoutPath string) ErrorCode
+
+	// RecommendedBlockSize returns the block size recommended for a file of
+	// fileSize bytes.
+	RecommendedBlockSize(sessionID uintptr, fileSize uint64) int
+
+	// LastError returns the message of the last failure of a session. ok is
+	// false if the message cannot be retrieved.
+	LastError(sessionID uintptr) (msg string, ok bool)
+
+	// Version returns the version of the implementation. ok is false if it
+	// cannot be retrieved.
+	Version() (version string, ok bool)
+}
+
+// MemoryBackend is a Backend that also encodes and decodes buffers without
+// touching the filesystem. RaptorQProcessor.EncodeBytes and DecodeBytes use it
+// when the backend of the processor implements it; otherwise they stage the
+// data in a temporary directory and go through the path-based operations.
+//
+// The pure-Go backend implements MemoryBackend. The native library does not,
+// since its C API only takes paths.
+type MemoryBackend interface {
+	Backend
+
+	// EncodeBytes encodes data exactly like EncodeFile encodes a file holding
+	// data, and returns the layout file content and the symbols instead of
+	// writing them. The paths of the result are empty.
+	EncodeBytes(sessionID uintptr, data []byte, blockSize int) (*EncodedObject, ErrorCode)
+
+	// DecodeBytes reconstructs the data described by layout from symbols,
+	// keyed by symbol ID.
+	DecodeBytes(sessionID uintptr, layout []byte, symbols map[string][]byte) ([]byte, ErrorCode)
+}
+
+// DefaultBackend returns the backend used by NewRaptorQProcessor and the other
+// constructors that do not take a backend. In builds without cgo, processors
+// cannot be created on it and the constructors fail with
+// ErrNativeLibraryUnavailable.
+func DefaultBackend() Backend {
+	return defaultBackend
+}
+
+// NewBackendProcessor validates cfg and creates a processor whose session
+// lives on backend.
+//
+// Parameters:
+//   - backend: The backend performing the operations of the session.
+//   - cfg: The configuration of the session.
+//
+// Returns:
+//   - *RaptorQProcessor: A new processor instance if successful.
+//   - error: The errors of ProcessorConfig.Validate if cfg is invalid, or an
+//     error if the backend cannot create the session.
+//
+// Example:
+//
+//	processor, err := raptorq.NewBackendProcessor(raptorq.NewPureGoBackend(), raptorq.DefaultProcessorConfig())
+//	if err != nil {
+//	    return err
+//	}
+//	defer processor.Free()
+func NewBackendProcessor(backend Backend, cfg ProcessorConfig) (*RaptorQProcessor, error) {
+	if backend == nil {
+		backend = defaultBackend
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return newProcessor(backend, cfg)
+}
+
+// Backend returns the backend of the processor's session.
+func (p *RaptorQProcessor) Backend() Backend {
+	return p.backend
+}
+
+// sessionKey returns the key of the processor's session in the sessions map.
+func (p *RaptorQProcessor) sessionKey() sessionKey {
+	return sessionKey{backend: p.backend, id: p.SessionID}
+}
+
+// goBackend implements Backend with goCodec, the pure-Go implementation.
+type goBackend struct {
+	mu       sync.Mutex
+	sessions map[uintptr]*goSession
+	nextID   uintptr
+}
+
+// goSession is the state of a pure-Go session.
+type goSession struct {
+	codec     goCodec
+	lastError string
+}
+
+// NewPureGoBackend returns a Backend implemented in pure Go. It is not a
+// RaptorQ implementation and never the default backend, but it produces the
+// native library's source symbols and can be selected explicitly to work with
+// them, including in builds without cgo. It only handles source symbols: encoding fails with
+// ErrInvalidParameters unless the redundancy factor is 0, which only
+// NewRaptorQProcessor accepts, and decoding needs every source symbol. See
+// goCodec for details.
+func NewPureGoBackend() Backend {
+	return &goBackend{sessions: make(map[uintptr]*goSession)}
+}
+
+// session returns the session with the given ID, or nil.
+func (b *goBackend) session(sessionID uintptr) *goSession {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sessions[sessionID]
+}
+
+// fail records err as the last error of s and returns its return code.
+func (b *goBackend) fail(s *goSession, err error) ErrorCode {
+	var cerr *codecError
+	if !errors.As(err, &cerr) {
+		cerr = &codecError{code: CodeGeneric, msg: err.Error()}
+	}
+	b.mu.Lock()
+	s.lastError = cerr.msg
+	b.mu.Unlock()
+	return cerr.code
+}
+
+// InitSession implements Backend.
+func (b *goBackend) InitSession(cfg ProcessorConfig) uintptr {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	b.sessions[b.nextID] = &goSession{codec: goCodec{config: cfg}}
+	return b.nextID
+}
+
+// FreeSession implements Backend.
+func (b *goBackend) FreeSession(sessionID uintptr) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.sessions[sessionID]; !ok {
+		return false
+	}
+	delete(b.sessions, sessionID)
+	return true
+}
+
+// EncodeFile implements Backend.
+func (b *goBackend) EncodeFile(sessionID uintptr, inputPath, outputDir string, blockSize int) (*ProcessResult, ErrorCode) {
+	s := b.session(sessionID)
+	if s == nil {
+		return nil, CodeInvalidSession
+	}
+	if inputPath == "" || outputDir == "" {
+		return nil, CodeInvalidParameters
+	}
+	result, err := s.codec.encode(inputPath, outputDir, filepath.Join(outputDir, layoutFileName), blockSize, true)
+	if err != nil {
+		return nil, b.fail(s, err)
+	}
+	return result, CodeSuccess
+}
+
+// CreateMetadata implements Backend.
+func (b *goBackend) CreateMetadata(sessionID uintptr, inputPath, layoutFile string, blockSize int) (*ProcessResult, ErrorCode) {
+	s := b.session(sessionID)
+	if s == nil {
+		return nil, CodeInvalidSession
+	}
+	if inputPath == "" || layoutFile == "" {
+		return nil, CodeInvalidParameters
+	}
+	result, err := s.codec.encode(inputPath, "", layoutFile, blockSize, false)
+	if err != nil {
+		return nil, b.fail(s, err)
+	}
+	return result, CodeSuccess
+}
+
+// DecodeSymbols implements Backend.
+func (b *goBackend) DecodeSymbols(sessionID uintptr, symbolsDir, outputPath, layoutPath string) ErrorCode {
+	s := b.session(sessionID)
+	if s == nil {
+		return CodeInvalidSession
+	}
+	if err := s.codec.decode(symbolsDir, outputPath, layoutPath); err != nil {
+		return b.fail(s, err)
+	}
+	return CodeSuccess
+}
+
+// EncodeBytes implements MemoryBackend.
+func (b *goBackend) EncodeBytes(sessionID uintptr, data []byte, blockSize int) (*EncodedObject, ErrorCode) {
+	s := b.session(sessionID)
+	if s == nil {
+		return nil, CodeInvalidSession
+	}
+	obj, err := s.codec.encodeBytes(data, blockSize)
+	if err != nil {
+		return nil, b.fail(s, err)
+	}
+	return obj, CodeSuccess
+}
+
+// DecodeBytes implements MemoryBackend.
+func (b *goBackend) DecodeBytes(sessionID uintptr, layout []byte, symbols map[string][]byte) ([]byte, ErrorCode) {
+	s := b.session(sessionID)
+	if s == nil {
+		return nil, CodeInvalidSession
+	}
+	data, err := s.codec.decodeBytes(layout, symbols)
+	if err != nil {
+		return nil, b.fail(s, err)
+	}
+	return data, CodeSuccess
+}
+
+// RecommendedBlockSize implements Backend.
+func (b *goBackend) RecommendedBlockSize(sessionID uintptr, fileSize uint64) int {
+	s := b.session(sessionID)
+	if s == nil {
+		return 0
+	}
+	return int(s.codec.recommendedBlockSize(fileSize))
+}
+
+// LastError implements Backend.
+func (b *goBackend) LastError(sessionID uintptr) (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.sessions[sessionID]
+	if !ok {
+		return "", false
+	}
+	return s.lastError, true
+}
+
+// Version implements Backend.
+func (b *goBackend) Version() (string, bool) {
+	return pureGoVersion, true
+}
+
+// pureGoVersion is the version reported by the pure-Go backend.
+const pureGoVersion = "rq-go pure-Go (systematic symbols only)"
@@ -0,0 +1,70 @@
+package rq_go
+
+import (
+	"errors"
+	"testing"
+)
+
+// Unit test for processors on explicit backends
+func TestBackendProcessor(t *testing.T) {
+	if _, err := NewBackendProcessor(NewPureGoBackend(), ProcessorConfig{}); err == nil {
+		t.Fatal("Expected an invalid configuration to be rejected")
+	}
+
+	// Two backends hand out the same session IDs, which must not collide in
+	// the session registry
+	p1, err := NewBackendProcessor(NewPureGoBackend(), DefaultProcessorConfig())
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer p1.Free()
+	p2, err := NewBackendProcessor(NewPureGoBackend(), DefaultProcessorConfig())
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer p2.Free()
+	if p1.SessionID != p2.SessionID {
+		t.Fatalf("Expected both backends to start at the same session ID, got %d and %d", p1.SessionID, p2.SessionID)
+	}
+
+	p1.Free()
+	sessionMutex.Lock()
+	_, freed := sessions[p1.sessionKey()]
+	_, live := sessions[p2.sessionKey()]
+	sessionMutex.Unlock()
+	if freed || !live {
+		t.Fatalf("Expected only the freed session to be unregistered (freed %v, live %v)", freed, live)
+	}
+
+	// The pure-Go backend generates no repair symbols, so it only encodes
+	// sessions without redundancy
+	ctx := NewTestContext(t, 100*1024)
+	defer ctx.Cleanup()
+	if _, err := p2.EncodeFile(ctx.InputFile, ctx.SymbolsDir, 0); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters for a redundancy factor of %d, got: %v", p2.Config().RedundancyFactor, err)
+	}
+	cfg := DefaultProcessorConfig()
+	cfg.RedundancyFactor = 0
+	p3, err := newProcessor(p2.Backend(), cfg)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer p3.Free()
+	res, err := p3.EncodeFile(ctx.InputFile, ctx.SymbolsDir, 0)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	if err := p2.DecodeSymbols(ctx.SymbolsDir, ctx.OutputFile, res.LayoutFilePath); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	if !ctx.VerifyFilesMatch(t) {
+		t.Fatal("Decoded file does not match the original")
+	}
+
+	if version, ok := p2.Backend().Version(); !ok || version != pureGoVersion {
+		t.Fatalf("Unexpected version %q", version)
+	}
+	if p2.Backend() == DefaultBackend() {
+		t.Fatal("Expected the processor to use its own backend")
+	}
+}
@@ -0,0 +1,158 @@
+package rq_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// layoutFileName is the name of the layout file written by EncodeFile into the
+// symbols directory.
+const layoutFileName = "_raptorq_layout.json"
+
+// blockDirName returns the name of the directory holding the symbols of a block,
+// relative to the symbols directory.
+func blockDirName(blockID uint64) string {
+	return fmt.Sprintf("block_%d", blockID)
+}
+
+// writeFileAtomic writes data to a temporary file in the target directory and
+// renames it into place, so readers never observe a partially written file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// blockSpan is a contiguous range of the input that is processed as one block.
+type blockSpan struct {
+	id     uint64
+	offset uint64
+	size   uint64
+}
+
+// splitBlocks cuts an input of fileSize bytes into blocks of blockSize bytes,
+// the same way the native library splits files. The last block holds the
+// remainder.
+func splitBlocks(fileSize uint64, blockSize uint64) []blockSpan {
+	if blockSize == 0 || fileSize <= blockSize {
+		return []blockSpan{{id: 0, offset: 0, size: fileSize}}
+	}
+
+	spans := make([]blockSpan, 0, (fileSize+blockSize-1)/blockSize)
+	for offset := uint64(0); offset < fileSize; offset += blockSize {
+		size := blockSize
+		if fileSize-offset < size {
+			size = fileSize - offset
+		}
+		spans = append(spans, blockSpan{id: uint64(len(spans)), offset: offset, size: size})
+	}
+	return spans
+}
+
+// effectiveBlockSize resolves the block size used for a file, falling back to
+// the recommended block size when blockSize is not positive. A result of 0 means
+// the file is processed as a single block.
+func (p *RaptorQProcessor) effectiveBlockSize(ctx context.Context, fileSize uint64, blockSize int) uint64 {
+	if blockSize > 0 {
+		return uint64(blockSize)
+	}
+	return uint64(p.recommendedBlockSize(ctx, fileSize))
+}
+
+// stageSpan copies the bytes of span from src into a new file at path.
+func stageSpan(src io.ReaderAt, span blockSpan, path string) error {
+	dst, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, io.NewSectionReader(src, int64(span.offset), int64(span.size)))
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// moveBlockSymbols moves the symbol files produced for a staged block from
+// srcDir into the block directory dstDir. It returns the paths it created so
+// they can be removed if the operation is abandoned.
+func moveBlockSymbols(srcDir, dstDir string) ([]string, error) {
+	var created []string
+
+	// A freshly created block directory is rolled back as a whole; otherwise
+	// only the files moved into the existing directory are tracked.
+	newDir := false
+	if _, err := os.Stat(dstDir); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(dstDir, 0755); err != nil {
+			return nil, err
+		}
+		created = append(created, dstDir)
+		newDir = true
+	}
+
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return created, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		dst := filepath.Join(dstDir, entry.Name())
+		if err := os.Rename(filepath.Join(srcDir, entry.Name()), dst); err != nil {
+			return created, err
+		}
+		if !newDir {
+			created = append(created, dst)
+		}
+	}
+	return created, nil
+}
+
+// removePaths removes every path in the list, ignoring errors. It is used to
+// roll back partially written outputs.
+func removePaths(paths []string) {
+	for i := len(paths) - 1; i >= 0; i-- {
+		os.RemoveAll(paths[i])
+	}
+}
+
+// ioError converts a Go file system error into a *RaptorQError using the same
+// code table as the native library, so callers can handle both uniformly.
+func ioError(op string, err error) error {
+	code := CodeIO
+	if errors.Is(err, os.ErrNotExist) {
+		code = CodeFileNotFound
+	}
+	return newRaptorQError(op, code, err.Error)
+}
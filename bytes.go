@@ -0,0 +1,261 @@
+package rq_go
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EncodedObject holds the output of encoding data in memory.
+//
+// Its Layout is byte-for-byte the _raptorq_layout.json that EncodeFile writes
+// for the same input and block size, and Symbols holds the content of the
+// symbol files, so an EncodedObject can be written to disk and decoded with
+// DecodeSymbols, and vice versa.
+type EncodedObject struct {
+	// Result summarises the encoding, as returned by EncodeFile. Its
+	// SymbolsDirectory and LayoutFilePath are empty because nothing is kept on disk.
+	Result *ProcessResult
+
+	// Layout is the content of the layout file describing the encoded blocks.
+	Layout []byte
+
+	// Symbols maps each symbol ID (as listed in the layout) to the symbol data.
+	Symbols map[string][]byte
+}
+
+// EncodeBytes encodes data in memory using the RaptorQ erasure coding algorithm.
+//
+// The data is split into blocks exactly like EncodeFile splits a file of the
+// same size, so the returned layout and symbols are interchangeable with the
+// output of EncodeFile.
+//
+// If the backend of the processor is a MemoryBackend, such as the pure-Go
+// backend, the data is encoded without touching the filesystem. The native
+// library only exposes path-based entry points, so with it the data and the
+// symbols are staged in a private temporary directory, which is removed
+// before EncodeBytes returns. Staging needs room for the data and its symbols
+// in os.TempDir.
+//
+// Parameters:
+//   - data: The data to encode.
+//   - blockSize: Size of each block in bytes. If 0, a recommended block size will be used.
+//
+// Returns:
+//   - *EncodedObject: The layout and the symbols keyed by symbol ID.
+//   - error: An error if the encoding fails, with the same error values as EncodeFile.
+//
+// Example:
+//
+//	obj, err := processor.EncodeBytes(payload, 0)
+//	if err != nil {
+//	    return err
+//	}
+//	for id, symbol := range obj.Symbols {
+//	    store.Put(id, symbol)
+//	}
+func (p *RaptorQProcessor) EncodeBytes(data []byte, blockSize int) (*EncodedObject, error) {
+	if p.SessionID == 0 {
+		return nil, ErrSessionClosed
+	}
+	if mb, ok := p.backend.(MemoryBackend); ok {
+		return p.encodeBytes(mb, data, blockSize)
+	}
+
+	stageDir, err := os.MkdirTemp("", "raptorq-bytes-")
+	if err != nil {
+		return nil, ioError("EncodeFile", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	inputPath := filepath.Join(stageDir, "input.bin")
+	if err := os.WriteFile(inputPath, data, 0600); err != nil {
+		return nil, ioError("EncodeFile", err)
+	}
+
+	symbolsDir := filepath.Join(stageDir, "symbols")
+	if err := os.MkdirAll(symbolsDir, 0755); err != nil {
+		return nil, ioError("EncodeFile", err)
+	}
+
+	result, err := p.EncodeFile(inputPath, symbolsDir, blockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	layout, err := os.ReadFile(filepath.Join(symbolsDir, layoutFileName))
+	if err != nil {
+		return nil, ioError("EncodeFile", err)
+	}
+
+	symbols := make(map[string][]byte, result.TotalSymbolsCount)
+	for _, block := range result.Blocks {
+		if err := readBlockSymbols(symbolsDir, block.BlockID, symbols); err != nil {
+			return nil, ioError("EncodeFile", err)
+		}
+	}
+
+	result.SymbolsDirectory = ""
+	result.LayoutFilePath = ""
+
+	return &EncodedObject{
+		Result:  result,
+		Layout:  layout,
+		Symbols: symbols,
+	}, nil
+}
+
+// DecodeBytes reconstructs the original data from a layout and a set of symbols.
+//
+// layout is the content of a layout file, as returned in EncodedObject.Layout or
+// read from a _raptorq_layout.json written by EncodeFile. symbols maps symbol IDs
+// to symbol data; it may hold any subset of the symbols listed in the layout as
+// long as every block keeps enough of them to be recovered. Symbols that are not
+// listed in the layout are ignored. Like EncodeBytes, DecodeBytes works in
+// memory with a MemoryBackend, and otherwise stages the symbols and the
+// decoded data in a private temporary directory for the duration of the call.
+//
+// Parameters:
+//   - layout: The layout describing the encoded blocks.
+//   - symbols: The available symbols keyed by symbol ID.
+//
+// Returns:
+//   - []byte: The reconstructed data.
+//   - error: An error if the decoding fails, with the same error values as DecodeSymbols.
+func (p *RaptorQProcessor) DecodeBytes(layout []byte, symbols map[string][]byte) ([]byte, error) {
+	if p.SessionID == 0 {
+		return nil, ErrSessionClosed
+	}
+
+	doc, err := ParseLayout(layout)
+	if err != nil {
+		return nil, err
+	}
+	if mb, ok := p.backend.(MemoryBackend); ok {
+		return p.decodeBytes(mb, doc, layout, symbols)
+	}
+
+	stageDir, err := os.MkdirTemp("", "raptorq-bytes-")
+	if err != nil {
+		return nil, ioError("DecodeSymbols", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	symbolsDir := filepath.Join(stageDir, "symbols")
+	for _, block := range doc.Blocks {
+		blockDir := filepath.Join(symbolsDir, blockDirName(block.BlockID))
+		if err := os.MkdirAll(blockDir, 0755); err != nil {
+			return nil, ioError("DecodeSymbols", err)
+		}
+
+		for _, id := range block.Symbols {
+			data, ok := symbols[id]
+			if !ok {
+				continue
+			}
+			if !isValidSymbolFileName(id) {
+				return nil, fmt.Errorf("%w: invalid symbol ID %q in block %d", ErrInvalidParameters, id, block.BlockID)
+			}
+			if err := os.WriteFile(filepath.Join(blockDir, id), data, 0644); err != nil {
+				return nil, ioError("DecodeSymbols", err)
+			}
+		}
+	}
+
+	layoutPath := filepath.Join(stageDir, layoutFileName)
+	if err := os.WriteFile(layoutPath, layout, 0644); err != nil {
+		return nil, ioError("DecodeSymbols", err)
+	}
+
+	outputPath := filepath.Join(stageDir, "output.bin")
+	if err := p.DecodeSymbols(symbolsDir, outputPath, layoutPath); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		return nil, ioError("DecodeSymbols", err)
+	}
+	return data, nil
+}
+
+// encodeBytes implements EncodeBytes with a MemoryBackend.
+func (p *RaptorQProcessor) encodeBytes(mb MemoryBackend, data []byte, blockSize int) (*EncodedObject, error) {
+	_, trace := p.startOperation(context.Background(), "EncodeBytes", intAttr(AttrBlockSize, int64(blockSize)))
+	start := time.Now()
+	obj, res := mb.EncodeBytes(p.SessionID, data, blockSize)
+	err := p.resultError("EncodeBytes", res)
+	var result *ProcessResult
+	if err == nil {
+		result = obj.Result
+	}
+	observeEncodeBytes(start, obj, err)
+	trace.endResult(result, err)
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// decodeBytes implements DecodeBytes with a MemoryBackend. doc is the parsed
+// layout.
+func (p *RaptorQProcessor) decodeBytes(mb MemoryBackend, doc *Layout, layout []byte, symbols map[string][]byte) ([]byte, error) {
+	_, trace := p.startOperation(context.Background(), "DecodeBytes", layoutAttributes(doc)...)
+	start := time.Now()
+	if err := p.CheckLayout(doc); err != nil {
+		err = fmt.Errorf("DecodeBytes: %w", err)
+		observeDecode("DecodeBytes", start, doc, err)
+		trace.end(err)
+		return nil, err
+	}
+	data, res := mb.DecodeBytes(p.SessionID, layout, symbols)
+	err := p.resultError("DecodeBytes", res)
+	observeDecode("DecodeBytes", start, doc, err)
+	trace.end(err)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// readBlockSymbols reads the symbol files of a block into symbols, keyed by
+// file name. Like the native decoder, it falls back to the symbols directory
+// itself when the block has no directory of its own.
+func readBlockSymbols(symbolsDir string, blockID uint64, symbols map[string][]byte) error {
+	dir := blockSymbolsDir(symbolsDir, blockID)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == layoutFileName {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		symbols[entry.Name()] = data
+	}
+	return nil
+}
+
+// blockSymbolsDir returns the directory holding the symbols of a block: the
+// block_N subdirectory when it exists, otherwise the symbols directory itself.
+func blockSymbolsDir(symbolsDir string, blockID uint64) string {
+	dir := filepath.Join(symbolsDir, blockDirName(blockID))
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return dir
+	}
+	return symbolsDir
+}
+
+// isValidSymbolFileName reports whether a symbol ID can be used as a file name
+// inside a block directory without escaping it.
+func isValidSymbolFileName(id string) bool {
+	return id != "" && id != "." && id != ".." && !strings.ContainsAny(id, `/\`) && id != layoutFileName
+}
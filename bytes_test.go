@@ -0,0 +1,133 @@
+package rq_go
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Unit test for symbol ID file name validation
+func TestIsValidSymbolFileName(t *testing.T) {
+	valid := []string{"9yCaAXSexMsaWDP6pzK4wZ4w9Hqrr6QPjJZ86wJMGoq9", "abc"}
+	invalid := []string{"", ".", "..", "../escape", `dir\name`, "a/b", layoutFileName}
+
+	for _, id := range valid {
+		if !isValidSymbolFileName(id) {
+			t.Errorf("Expected %q to be valid", id)
+		}
+	}
+	for _, id := range invalid {
+		if isValidSymbolFileName(id) {
+			t.Errorf("Expected %q to be invalid", id)
+		}
+	}
+}
+
+// System test for encoding/decoding in memory (3MB, 1MB blocks)
+func TestSysEncodeDecodeBytes(t *testing.T) {
+	processor, err := NewDefaultRaptorQProcessor()
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	data := make([]byte, 3*1024*1024)
+	rand.New(rand.NewSource(42)).Read(data)
+
+	obj, err := processor.EncodeBytes(data, 1024*1024)
+	if err != nil {
+		t.Fatalf("Failed to encode bytes: %v", err)
+	}
+	if len(obj.Result.Blocks) != 3 {
+		t.Fatalf("Expected 3 blocks, got %d", len(obj.Result.Blocks))
+	}
+	if len(obj.Symbols) == 0 || len(obj.Layout) == 0 {
+		t.Fatal("Encoded object should contain a layout and symbols")
+	}
+
+	decoded, err := processor.DecodeBytes(obj.Layout, obj.Symbols)
+	if err != nil {
+		t.Fatalf("Failed to decode bytes: %v", err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Fatal("Decoded data does not match original")
+	}
+}
+
+// Unit test for encoding/decoding in memory with a MemoryBackend, which must not stage anything on disk
+func TestEncodeDecodeBytesInMemory(t *testing.T) {
+	cfg := DefaultProcessorConfig()
+	cfg.RedundancyFactor = 0
+	processor, err := newProcessor(NewPureGoBackend(), cfg)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+	if _, ok := processor.Backend().(MemoryBackend); !ok {
+		t.Fatal("Expected the pure-Go backend to be a MemoryBackend")
+	}
+
+	ctx := NewTestContext(t, 300*1024+5)
+	defer ctx.Cleanup()
+	data, err := os.ReadFile(ctx.InputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := processor.EncodeFile(ctx.InputFile, ctx.SymbolsDir, 128*1024)
+	if err != nil {
+		t.Fatalf("Failed to encode file: %v", err)
+	}
+	layout, err := os.ReadFile(res.LayoutFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Staging would fail without a usable temporary directory
+	t.Setenv("TMPDIR", filepath.Join(ctx.TempDir, "missing"))
+
+	obj, err := processor.EncodeBytes(data, 128*1024)
+	if err != nil {
+		t.Fatalf("Failed to encode bytes: %v", err)
+	}
+	if !bytes.Equal(obj.Layout, layout) {
+		t.Fatal("Layout differs from the layout written by EncodeFile")
+	}
+	if obj.Result.SymbolsDirectory != "" || obj.Result.LayoutFilePath != "" || len(obj.Result.Blocks) != 3 {
+		t.Fatalf("Unexpected result: %+v", obj.Result)
+	}
+	for _, block := range res.Blocks {
+		files := make(map[string][]byte)
+		if err := readBlockSymbols(ctx.SymbolsDir, block.BlockID, files); err != nil {
+			t.Fatal(err)
+		}
+		for id, symbol := range files {
+			if !bytes.Equal(obj.Symbols[id], symbol) {
+				t.Fatalf("Symbol %s of block %d differs from its file", id, block.BlockID)
+			}
+		}
+	}
+
+	decoded, err := processor.DecodeBytes(obj.Layout, obj.Symbols)
+	if err != nil {
+		t.Fatalf("Failed to decode bytes: %v", err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Fatal("Decoded data does not match original")
+	}
+
+	// A missing source symbol cannot be recovered by the pure-Go backend
+	doc, err := ParseLayout(obj.Layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(obj.Symbols, doc.Blocks[1].Symbols[0])
+	if _, err := processor.DecodeBytes(obj.Layout, obj.Symbols); !errors.Is(err, ErrInsufficientSymbols) {
+		t.Fatalf("Expected ErrInsufficientSymbols, got: %v", err)
+	}
+	if _, err := processor.EncodeBytes(nil, 0); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters for empty data, got: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+// Command rq-server serves RaptorQ encoding and decoding over HTTP.
+//
+// Usage:
+//
+//	rq-server [flags]
+//
+// Flags:
+//
+//	-addr             Address to listen on (default ":8080")
+//	-rpc-addr         Address to serve the gRPC SymbolService on; empty disables it
+//	-metrics-path     Path of the Prometheus metrics on the HTTP address; empty disables them (default "/metrics")
+//	-dir              Directory the objects are stored in (required)
+//	-config           Read the processor configuration from a JSON or YAML file
+//	-max-sessions     Maximum number of concurrent processor sessions (default 4)
+//	-block-size       Block size of uploads in bytes; 0 chooses it from the upload size
+//	-max-block-size   Maximum block size of uploads in bytes, held in memory while encoding; 0 means -block-size, or 16MB
+//	-max-object-size  Maximum upload size in bytes; 0 means no limit
+//
+// Without -config, the processor configuration is read from the RAPTORQ_*
+// environment variables. The endpoints are described in the documentation of
+// the server package. The server shuts down gracefully on SIGINT and SIGTERM.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	raptorq "github.com/LumeraProtocol/rq-go"
+	"github.com/LumeraProtocol/rq-go/server"
+)
+
+// shutdownTimeout bounds the time requests in progress get to finish on
+// shutdown.
+const shutdownTimeout = 30 * time.Second
+
+func main() {
+	opts, err := parseFlags(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "rq-server: %v\n", err)
+		os.Exit(2)
+	}
+
+	srv, err := server.New(opts.cfg)
+	if err != nil {
+		log.Fatalf("rq-server: %v", err)
+	}
+	defer srv.Close()
+
+	if opts.rpcAddr != "" {
+		l, err := net.Listen("tcp", opts.rpcAddr)
+		if err != nil {
+			log.Fatalf("rq-server: %v", err)
+		}
+		log.Printf("rq-server: serving RPC on %s", opts.rpcAddr)
+		go func() {
+			if err := srv.ServeRPC(l); err != nil {
+				log.Fatalf("rq-server: %v", err)
+			}
+		}()
+	}
+
+	handler := http.Handler(srv)
+	if opts.metricsPath != "" {
+		registry := raptorq.NewMetricsRegistry()
+		raptorq.SetMetrics(registry)
+		mux := http.NewServeMux()
+		mux.Handle(opts.metricsPath, registry)
+		mux.Handle("/", srv)
+		handler = mux
+	}
+	httpServer := &http.Server{Addr: opts.addr, Handler: handler}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdown); err != nil {
+			log.Printf("rq-server: shutdown: %v", err)
+		}
+	}()
+
+	log.Printf("rq-server: serving %s on %s", opts.cfg.Dir, opts.addr)
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("rq-server: %v", err)
+	}
+}
+
+// options are the settings of the command line.
+type options struct {
+	addr        string
+	rpcAddr     string
+	metricsPath string
+	cfg         server.Config
+}
+
+// parseFlags parses the command line.
+func parseFlags(args []string, output io.Writer) (options, error) {
+	fs := flag.NewFlagSet("rq-server", flag.ContinueOnError)
+	fs.SetOutput(output)
+	addr := fs.String("addr", ":8080", "`address` to listen on")
+	rpcAddr := fs.String("rpc-addr", "", "`address` to serve the gRPC SymbolService on; empty disables it")
+	metricsPath := fs.String("metrics-path", "/metrics", "`path` of the Prometheus metrics; empty disables them")
+	dir := fs.String("dir", "", "`directory` the objects are stored in (required)")
+	configFile := fs.String("config", "", "read the processor configuration from a JSON or YAML `file`")
+	maxSessions := fs.Int("max-sessions", server.DefaultMaxSessions, "maximum number of concurrent processor sessions")
+	blockSize := fs.Int("block-size", 0, "block size of uploads in `bytes`; 0 chooses it from the upload size")
+	maxBlockSize := fs.Int("max-block-size", 0, "maximum block size of uploads in `bytes`, held in memory while encoding; 0 means -block-size, or 16MB")
+	maxObjectSize := fs.Int64("max-object-size", 0, "maximum upload size in `bytes`; 0 means no limit")
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+	if fs.NArg() > 0 {
+		return options{}, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	if *metricsPath != "" && (!strings.HasPrefix(*metricsPath, "/") || strings.HasPrefix(*metricsPath, "/objects")) {
+		return options{}, errors.New("-metrics-path must start with / and cannot be below /objects")
+	}
+	if *dir == "" {
+		return options{}, errors.New("-dir is required")
+	}
+	if *maxSessions <= 0 || *blockSize < 0 || *maxBlockSize < 0 || *maxObjectSize < 0 {
+		return options{}, errors.New("-max-sessions must be positive, -block-size, -max-block-size and -max-object-size cannot be negative")
+	}
+
+	var processor raptorq.ProcessorConfig
+	var err error
+	if *configFile != "" {
+		processor, err = raptorq.LoadProcessorConfig(*configFile)
+	} else {
+		processor, err = raptorq.ProcessorConfigFromEnv("")
+	}
+	if err != nil {
+		return options{}, err
+	}
+	if err := processor.Validate(); err != nil {
+		return options{}, err
+	}
+
+	return options{
+		addr:        *addr,
+		rpcAddr:     *rpcAddr,
+		metricsPath: *metricsPath,
+		cfg: server.Config{
+			Dir:           *dir,
+			Processor:     processor,
+			MaxSessions:   *maxSessions,
+			BlockSize:     *blockSize,
+			MaxBlockSize:  *maxBlockSize,
+			MaxObjectSize: *maxObjectSize,
+		},
+	}, nil
+}
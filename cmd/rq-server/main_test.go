@@ -0,0 +1,48 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	raptorq "github.com/LumeraProtocol/rq-go"
+)
+
+// Unit test for parsing the command line
+func TestParseFlags(t *testing.T) {
+	opts, err := parseFlags([]string{"-dir", "objects", "-addr", ":9000", "-rpc-addr", ":9090", "-max-sessions", "2", "-max-block-size", "4096", "-max-object-size", "1000"}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if opts.addr != ":9000" || opts.rpcAddr != ":9090" || opts.metricsPath != "/metrics" || opts.cfg.Dir != "objects" || opts.cfg.MaxSessions != 2 || opts.cfg.MaxBlockSize != 4096 || opts.cfg.MaxObjectSize != 1000 {
+		t.Errorf("Unexpected options: %+v", opts)
+	}
+
+	config := filepath.Join(t.TempDir(), "rq.yaml")
+	if err := os.WriteFile(config, []byte("symbol_size: 4096\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if opts, err := parseFlags([]string{"-dir", "objects", "-config", config}, io.Discard); err != nil || opts.cfg.Processor.SymbolSize != 4096 {
+		t.Errorf("Expected the symbol size of the configuration file, got %+v, %v", opts.cfg.Processor, err)
+	}
+
+	t.Setenv(raptorq.DefaultEnvPrefix+"_SYMBOL_SIZE", "8192")
+	if opts, err := parseFlags([]string{"-dir", "objects"}, io.Discard); err != nil || opts.cfg.Processor.SymbolSize != 8192 {
+		t.Errorf("Expected the symbol size of the environment, got %+v, %v", opts.cfg.Processor, err)
+	}
+
+	for _, args := range [][]string{
+		{},
+		{"-dir", "objects", "extra"},
+		{"-dir", "objects", "-max-sessions", "0"},
+		{"-dir", "objects", "-max-block-size", "-1"},
+		{"-dir", "objects", "-config", filepath.Join(t.TempDir(), "missing.json")},
+		{"-dir", "objects", "-metrics-path", "/objects/metrics"},
+		{"-unknown"},
+	} {
+		if _, err := parseFlags(args, io.Discard); err == nil {
+			t.Errorf("parseFlags(%q): expected an error", args)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	raptorq "github.com/LumeraProtocol/rq-go"
+)
+
+var benchCommand = &command{
+	name:      "bench",
+	summary:   "Measure encoding and decoding throughput",
+	processor: true,
+	setup: func(fs *flagSet) func(args []string) error {
+		var size, block uint64
+		fs.sizeVar(&size, "size", 64<<20, "size of the test file, e.g. 1GiB")
+		fs.sizeVar(&block, "block-size", 0, "block size, e.g. 64MiB (default recommended by the library)")
+		iterations := fs.Int("iterations", 3, "number of runs of each operation")
+		dir := fs.String("dir", "", "`directory` for the test files (default the system temporary directory)")
+
+		return func(args []string) error {
+			if err := expectArgs(args, 0, 0); err != nil {
+				return err
+			}
+			if size == 0 || *iterations < 1 {
+				return usagef("--size and --iterations must be positive")
+			}
+			bs, err := blockSize(block)
+			if err != nil {
+				return err
+			}
+
+			processor, err := fs.processor()
+			if err != nil {
+				return err
+			}
+			defer processor.Free()
+
+			res, err := bench(fs.c, processor, *dir, int64(size), bs, *iterations)
+			if err != nil {
+				return err
+			}
+			return fs.output(res, func(w io.Writer) {
+				fmt.Fprintf(w, "File size: %d bytes, %d iterations\n\n", res.Size, res.Iterations)
+				fmt.Fprintf(w, "%-10s %12s %12s %12s\n", "OPERATION", "BEST", "MEAN", "MB/S")
+				for _, op := range res.Operations {
+					fmt.Fprintf(w, "%-10s %12s %12s %12.1f\n", op.Name, op.Best.Round(time.Millisecond),
+						op.Mean.Round(time.Millisecond), op.MBPerSecond)
+				}
+			})
+		}
+	},
+}
+
+// benchResult is the output of the bench command.
+type benchResult struct {
+	Size       int64                   `json:"size"`
+	BlockSize  int                     `json:"block_size"`
+	Iterations int                     `json:"iterations"`
+	Config     raptorq.ProcessorConfig `json:"config"`
+	Operations []benchTiming           `json:"operations"`
+}
+
+// benchTiming holds the timings of one operation.
+type benchTiming struct {
+	Name        string        `json:"name"`
+	Best        time.Duration `json:"best_ns"`
+	Mean        time.Duration `json:"mean_ns"`
+	MBPerSecond float64       `json:"mb_per_second"`
+}
+
+// bench encodes, creates metadata for and decodes a random file of size bytes
+// iterations times each.
+func bench(c *cli, processor *raptorq.RaptorQProcessor, dir string, size int64, bs, iterations int) (*benchResult, error) {
+	tmp, err := os.MkdirTemp(dir, "rq-bench-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	input := filepath.Join(tmp, "input")
+	f, err := os.Create(input)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.CopyN(f, rand.New(rand.NewSource(time.Now().UnixNano())), size)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	symbolsDir := filepath.Join(tmp, "symbols")
+	layoutPath := filepath.Join(symbolsDir, layoutFileName)
+	ops := []struct {
+		name string
+		run  func() error
+	}{
+		{"encode", func() error {
+			if err := os.RemoveAll(symbolsDir); err != nil {
+				return err
+			}
+			_, err := processor.EncodeFileContext(c.ctx, input, symbolsDir, bs)
+			return err
+		}},
+		{"metadata", func() error {
+			_, err := processor.CreateMetadataContext(c.ctx, input, filepath.Join(tmp, "metadata.json"), bs)
+			return err
+		}},
+		{"decode", func() error {
+			return processor.DecodeSymbolsContext(c.ctx, symbolsDir, filepath.Join(tmp, "decoded"), layoutPath)
+		}},
+	}
+
+	res := &benchResult{Size: size, BlockSize: bs, Iterations: iterations, Config: processor.Config()}
+	for _, op := range ops {
+		var best, total time.Duration
+		for i := 0; i < iterations; i++ {
+			start := time.Now()
+			if err := op.run(); err != nil {
+				return nil, fmt.Errorf("%s: %w", op.name, err)
+			}
+			elapsed := time.Since(start)
+			total += elapsed
+			if best == 0 || elapsed < best {
+				best = elapsed
+			}
+		}
+		res.Operations = append(res.Operations, benchTiming{
+			Name:        op.name,
+			Best:        best,
+			Mean:        total / time.Duration(iterations),
+			MBPerSecond: float64(size) / (1 << 20) / best.Seconds(),
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	raptorq "github.com/LumeraProtocol/rq-go"
+)
+
+// layoutFileName is the name of the layout file EncodeFile writes into the
+// symbols directory.
+const layoutFileName = "_raptorq_layout.json"
+
+var encodeCommand = &command{
+	name:      "encode",
+	args:      "<input>",
+	summary:   "Encode a file into symbols",
+	processor: true,
+	setup: func(fs *flagSet) func(args []string) error {
+		var size uint64
+		output := fs.String("output", "", "symbols `directory` (default <input>.symbols)")
+		fs.sizeVar(&size, "block-size", 0, "block size, e.g. 64MiB (default recommended by the library)")
+		progress := fs.Bool("progress", false, "report progress on stderr")
+
+		return func(args []string) error {
+			if err := expectArgs(args, 1, 1); err != nil {
+				return err
+			}
+			bs, err := blockSize(size)
+			if err != nil {
+				return err
+			}
+			input := args[0]
+			symbolsDir := *output
+			if symbolsDir == "" {
+				symbolsDir = input + ".symbols"
+			}
+
+			processor, err := fs.processor()
+			if err != nil {
+				return err
+			}
+			defer processor.Free()
+
+			if err := os.MkdirAll(symbolsDir, 0755); err != nil {
+				return err
+			}
+			res, err := processor.EncodeFileContext(fs.progressContext(*progress), input, symbolsDir, bs)
+			if err != nil {
+				return err
+			}
+			return fs.output(res, func(w io.Writer) {
+				fmt.Fprintf(w, "Encoded %s into %s\n", input, symbolsDir)
+				printResult(w, res)
+			})
+		}
+	},
+}
+
+var metadataCommand = &command{
+	name:      "metadata",
+	args:      "<input> <layout_file>",
+	summary:   "Write the layout of a file without writing symbols",
+	processor: true,
+	setup: func(fs *flagSet) func(args []string) error {
+		var size uint64
+		fs.sizeVar(&size, "block-size", 0, "block size, e.g. 64MiB (default recommended by the library)")
+		progress := fs.Bool("progress", false, "report progress on stderr")
+
+		return func(args []string) error {
+			if err := expectArgs(args, 2, 2); err != nil {
+				return err
+			}
+			bs, err := blockSize(size)
+			if err != nil {
+				return err
+			}
+
+			processor, err := fs.processor()
+			if err != nil {
+				return err
+			}
+			defer processor.Free()
+
+			res, err := processor.CreateMetadataContext(fs.progressContext(*progress), args[0], args[1], bs)
+			if err != nil {
+				return err
+			}
+			return fs.output(res, func(w io.Writer) {
+				fmt.Fprintf(w, "Wrote the layout of %s\n", args[0])
+				printResult(w, res)
+			})
+		}
+	},
+}
+
+// printResult prints the summary of an encoding result.
+func printResult(w io.Writer, res *raptorq.ProcessResult) {
+	fmt.Fprintf(w, "Layout file:    %s\n", res.LayoutFilePath)
+	fmt.Fprintf(w, "Blocks:         %d\n", len(res.Blocks))
+	fmt.Fprintf(w, "Total symbols:  %d\n", res.TotalSymbolsCount)
+	fmt.Fprintf(w, "Repair symbols: %d\n", res.TotalRepairSymbols)
+}
+
+var decodeCommand = &command{
+	name:      "decode",
+	args:      "<symbols_dir> <output_file>",
+	summary:   "Decode symbols into the original file",
+	processor: true,
+	setup: func(fs *flagSet) func(args []string) error {
+		layout := fs.String("layout", "", "layout `file` (default <symbols_dir>/"+layoutFileName+")")
+		progress := fs.Bool("progress", false, "report progress on stderr")
+
+		return func(args []string) error {
+			if err := expectArgs(args, 2, 2); err != nil {
+				return err
+			}
+			symbolsDir, outputPath := args[0], args[1]
+			layoutPath := layoutPathFlag(*layout, symbolsDir)
+			l, err := raptorq.LoadLayout(layoutPath)
+			if err != nil {
+				return err
+			}
+
+			processor, err := fs.layoutProcessor(l)
+			if err != nil {
+				return err
+			}
+			defer processor.Free()
+
+			if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+				return err
+			}
+			if err := processor.DecodeSymbolsContext(fs.progressContext(*progress), symbolsDir, outputPath, layoutPath); err != nil {
+				return err
+			}
+
+			info, err := os.Stat(outputPath)
+			if err != nil {
+				return err
+			}
+			result := struct {
+				OutputPath string `json:"output_path"`
+				Size       int64  `json:"size"`
+			}{outputPath, info.Size()}
+			return fs.output(result, func(w io.Writer) {
+				fmt.Fprintf(w, "Decoded %d bytes into %s\n", info.Size(), outputPath)
+			})
+		}
+	},
+}
+
+// layoutPathFlag returns the layout file selected by a --layout flag, which
+// defaults to the layout file of the symbols directory.
+func layoutPathFlag(flagValue, symbolsDir string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return filepath.Join(symbolsDir, layoutFileName)
+}
+
+// progressContext returns the command's context, reporting progress on stderr
+// if enabled.
+func (fs *flagSet) progressContext(enabled bool) context.Context {
+	if !enabled {
+		return fs.c.ctx
+	}
+	w := fs.c.stderr
+	return raptorq.WithProgress(fs.c.ctx, func(ev raptorq.ProgressEvent) {
+		switch ev.Stage {
+		case raptorq.ProgressBlockFinished:
+			fmt.Fprintf(w, "%s: %d/%d blocks, %d/%d bytes, %s remaining\n",
+				ev.Op, ev.BlocksDone, ev.Blocks, ev.BytesProcessed, ev.TotalBytes, ev.Remaining.Round(time.Second))
+		case raptorq.ProgressFinished:
+			if ev.Err == nil {
+				fmt.Fprintf(w, "%s: done in %s\n", ev.Op, ev.Elapsed.Round(time.Millisecond))
+			}
+		}
+	})
+}
+
+var verifyCommand = &command{
+	name:    "verify",
+	args:    "<symbols_dir>",
+	summary: "Check a symbols directory against its layout",
+	setup: func(fs *flagSet) func(args []string) error {
+		layout := fs.String("layout", "", "layout `file` (default <symbols_dir>/"+layoutFileName+")")
+		decoded := fs.String("file", "", "also check a decoded `file` against the block hashes of the layout")
+
+		return func(args []string) error {
+			if err := expectArgs(args, 1, 1); err != nil {
+				return err
+			}
+			l, err := loadLayout(layoutPathFlag(*layout, args[0]))
+			if err != nil {
+				return err
+			}
+			report, err := raptorq.VerifySymbols(args[0], l)
+			if err != nil {
+				return err
+			}
+			var fileErr error
+			if *decoded != "" {
+				fileErr = raptorq.VerifyDecodedFile(*decoded, l)
+			}
+
+			if err := fs.output(report, func(w io.Writer) { printVerification(w, report) }); err != nil {
+				return err
+			}
+			switch {
+			case !report.Decodable:
+				return fmt.Errorf("%w: not enough valid symbols to decode every block", raptorq.ErrInsufficientSymbols)
+			case !report.Healthy():
+				return errUnhealthy
+			}
+			return fileErr
+		}
+	},
+}
+
+// loadLayout loads and validates a layout file.
+func loadLayout(path string) (*raptorq.Layout, error) {
+	layout, err := raptorq.LoadLayout(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := layout.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return layout, nil
+}
+
+// printVerification prints a verification report as a table.
+func printVerification(w io.Writer, report *raptorq.VerificationReport) {
+	fmt.Fprintf(w, "%-6s %8s %8s %8s %8s %10s  %s\n", "BLOCK", "VALID", "NEEDED", "MISSING", "CORRUPT", "UNEXPECTED", "STATE")
+	for i := range report.Blocks {
+		b := &report.Blocks[i]
+		state := "healthy"
+		switch {
+		case !b.Decodable:
+			state = "undecodable"
+		case !b.Healthy():
+			state = "degraded"
+		}
+		fmt.Fprintf(w, "%-6d %8d %8d %8d %8d %10d  %s\n", b.BlockID, len(b.Valid), b.SourceSymbols,
+			len(b.Missing), len(b.Corrupt), len(b.Unexpected)+len(b.Duplicate), state)
+	}
+}
+
+var inspectCommand = &command{
+	name:    "inspect",
+	args:    "<layout_file>",
+	summary: "Summarize a layout file",
+	setup: func(fs *flagSet) func(args []string) error {
+		symbolsDir := fs.String("symbols", "", "also report the presence and health of the symbols in `directory`")
+
+		return func(args []string) error {
+			if err := expectArgs(args, 1, 1); err != nil {
+				return err
+			}
+			layout, err := raptorq.LoadLayout(args[0])
+			if err != nil {
+				return err
+			}
+			summary, err := raptorq.InspectLayout(layout, *symbolsDir)
+			if err != nil {
+				return err
+			}
+			if fs.json {
+				return fs.output(summary, nil)
+			}
+			return summary.WriteTable(fs.c.stdout)
+		}
+	},
+}
+
+var versionCommand = &command{
+	name:    "version",
+	summary: "Print the library version",
+	setup: func(fs *flagSet) func(args []string) error {
+		return func(args []string) error {
+			if err := expectArgs(args, 0, 0); err != nil {
+				return err
+			}
+			result := struct {
+				Version string `json:"version"`
+			}{raptorq.GetVersion()}
+			return fs.output(result, func(w io.Writer) {
+				fmt.Fprintf(w, "RaptorQ library version: %s\n", result.Version)
+			})
+		}
+	},
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var completionCommand = &command{
+	name:    "completion",
+	args:    "<bash|zsh|fish>",
+	summary: "Print a shell completion script",
+	setup: func(fs *flagSet) func(args []string) error {
+		return func(args []string) error {
+			if err := expectArgs(args, 1, 1); err != nil {
+				return err
+			}
+			switch args[0] {
+			case "bash":
+				writeBashCompletion(fs.c.stdout, fs.c, false)
+			case "zsh":
+				writeBashCompletion(fs.c.stdout, fs.c, true)
+			case "fish":
+				writeFishCompletion(fs.c.stdout, fs.c)
+			default:
+				return usagef("unsupported shell %q", args[0])
+			}
+			return nil
+		}
+	},
+}
+
+// commandFlags returns the flags of cmd in lexical order.
+func commandFlags(c *cli, cmd *command) []*flag.Flag {
+	fs, _ := cmd.newFlags(c)
+	var flags []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, f)
+	})
+	return flags
+}
+
+// writeBashCompletion writes the bash completion script. zsh loads the same
+// script through bashcompinit.
+func writeBashCompletion(w io.Writer, c *cli, zsh bool) {
+	if zsh {
+		fmt.Fprintln(w, "autoload -U +X bashcompinit && bashcompinit")
+	}
+	names := make([]string, len(commands))
+	for i, cmd := range commands {
+		names[i] = cmd.name
+	}
+
+	fmt.Fprintln(w, "_rq() {")
+	fmt.Fprintln(w, `    local cur="${COMP_WORDS[COMP_CWORD]}"`)
+	fmt.Fprintln(w, `    if [[ $COMP_CWORD -eq 1 ]]; then`)
+	fmt.Fprintf(w, "        COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(names, " ")+" help")
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, `    if [[ "$cur" == -* ]]; then`)
+	fmt.Fprintln(w, `        case "${COMP_WORDS[1]}" in`)
+	for _, cmd := range commands {
+		var flags []string
+		for _, f := range commandFlags(c, cmd) {
+			flags = append(flags, "--"+f.Name)
+		}
+		fmt.Fprintf(w, "            %s) COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n", cmd.name, strings.Join(flags, " "))
+	}
+	fmt.Fprintln(w, "        esac")
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, `    case "${COMP_WORDS[1]}" in`)
+	fmt.Fprintln(w, `        completion) COMPREPLY=($(compgen -W "bash zsh fish" -- "$cur")) ;;`)
+	fmt.Fprintln(w, `        help) COMPREPLY=($(compgen -W "`+strings.Join(names, " ")+`" -- "$cur")) ;;`)
+	fmt.Fprintln(w, `        *) COMPREPLY=($(compgen -f -- "$cur")) ;;`)
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "complete -o filenames -F _rq rq")
+}
+
+// writeFishCompletion writes the fish completion script.
+func writeFishCompletion(w io.Writer, c *cli) {
+	fmt.Fprintln(w, "complete -c rq -f -n __fish_use_subcommand -a help -d 'Show the usage of a command'")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "complete -c rq -f -n __fish_use_subcommand -a %s -d %s\n", cmd.name, fishQuote(cmd.summary))
+	}
+	for _, cmd := range commands {
+		for _, f := range commandFlags(c, cmd) {
+			usage, _ := flag.UnquoteUsage(f)
+			fmt.Fprintf(w, "complete -c rq -n '__fish_seen_subcommand_from %s' -l %s -d %s\n", cmd.name, f.Name, fishQuote(usage))
+		}
+	}
+	fmt.Fprintln(w, "complete -c rq -f -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'")
+}
+
+// fishQuote quotes s for a fish script.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	raptorq "github.com/LumeraProtocol/rq-go"
+)
+
+// errHelp is returned by flagSet.parse when -h or --help was given and the
+// usage has been printed.
+var errHelp = errors.New("help requested")
+
+// flagSet holds the flags of a command: the flags shared by all commands, the
+// processor flags of commands that create a processor, and the flags the
+// command registers itself.
+type flagSet struct {
+	*flag.FlagSet
+	c   *cli
+	cmd *command
+
+	json bool
+
+	config      string
+	symbolSize  uint
+	redundancy  uint
+	maxMemoryMB uint64
+	concurrency uint64
+}
+
+// newFlagSet returns the flag set of cmd with the shared flags registered.
+func newFlagSet(c *cli, cmd *command) *flagSet {
+	fs := &flagSet{FlagSet: flag.NewFlagSet("rq "+cmd.name, flag.ContinueOnError), c: c, cmd: cmd}
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+
+	fs.BoolVar(&fs.json, "json", false, "print machine-readable JSON output")
+	if cmd.processor {
+		def := raptorq.DefaultProcessorConfig()
+		fs.StringVar(&fs.config, "config", "", "read the processor configuration from a JSON or YAML `file`")
+		fs.UintVar(&fs.symbolSize, "symbol-size", uint(def.SymbolSize), "symbol size in `bytes`")
+		fs.UintVar(&fs.redundancy, "redundancy", uint(def.RedundancyFactor), "repair symbols per source symbol")
+		fs.Uint64Var(&fs.maxMemoryMB, "max-memory", def.MaxMemoryMB, "memory limit in `MB`")
+		fs.Uint64Var(&fs.concurrency, "concurrency", def.ConcurrencyLimit, "maximum number of concurrent operations")
+	}
+	return fs
+}
+
+// parse parses args and returns the positional arguments. Unlike
+// flag.FlagSet.Parse, flags may follow positional arguments; everything after
+// "--" is positional.
+func (fs *flagSet) parse(args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				fs.usage(fs.c.stdout)
+				return nil, errHelp
+			}
+			return nil, &usageError{err.Error()}
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// usage prints the usage of the command and its flags to w.
+func (fs *flagSet) usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: rq %s [flags] %s\n\n%s.\n\nFlags:\n", fs.cmd.name, fs.cmd.args, fs.cmd.summary)
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+	fs.SetOutput(io.Discard)
+}
+
+// sizeValue is a flag.Value for byte sizes such as 4096, 64K or 16MiB. The
+// suffixes K, M and G and their KB, KiB, ... forms are binary units.
+type sizeValue uint64
+
+func (s *sizeValue) String() string {
+	return strconv.FormatUint(uint64(*s), 10)
+}
+
+func (s *sizeValue) Set(value string) error {
+	n, err := parseSize(value)
+	if err != nil {
+		return err
+	}
+	*s = sizeValue(n)
+	return nil
+}
+
+// sizeVar registers a size flag.
+func (fs *flagSet) sizeVar(p *uint64, name string, value uint64, usage string) {
+	*p = value
+	fs.Var((*sizeValue)(p), name, usage)
+}
+
+// parseSize parses a byte size with an optional binary unit suffix.
+func parseSize(value string) (uint64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := uint64(1)
+	for _, unit := range []struct {
+		suffix string
+		shift  uint
+	}{{"G", 30}, {"M", 20}, {"K", 10}} {
+		for _, form := range []string{unit.suffix + "IB", unit.suffix + "B", unit.suffix} {
+			if strings.HasSuffix(s, form) {
+				s = strings.TrimSpace(strings.TrimSuffix(s, form))
+				multiplier = 1 << unit.shift
+				break
+			}
+		}
+		if multiplier != 1 {
+			break
+		}
+	}
+	s = strings.TrimSuffix(s, "B")
+
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || n > math.MaxUint64/multiplier {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return n * multiplier, nil
+}
+
+// blockSize converts a block size flag to the int taken by the processor.
+func blockSize(size uint64) (int, error) {
+	if size > math.MaxInt32 {
+		return 0, usagef("block size %d is too large", size)
+	}
+	return int(size), nil
+}
+
+// processorConfig returns the processor configuration selected by the flags,
+// the configuration file and the environment, in that order of precedence.
+func (fs *flagSet) processorConfig() (raptorq.ProcessorConfig, error) {
+	cfg, err := raptorq.ProcessorConfigFromEnv("")
+	if err != nil {
+		return cfg, err
+	}
+	if fs.config != "" {
+		if cfg, err = raptorq.LoadProcessorConfig(fs.config); err != nil {
+			return cfg, err
+		}
+	}
+
+	var errs []error
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "symbol-size":
+			if fs.symbolSize > math.MaxUint16 {
+				errs = append(errs, usagef("--symbol-size must be at most %d", math.MaxUint16))
+			}
+			cfg.SymbolSize = uint16(fs.symbolSize)
+		case "redundancy":
+			if fs.redundancy > math.MaxUint8 {
+				errs = append(errs, usagef("--redundancy must be at most %d", math.MaxUint8))
+			}
+			cfg.RedundancyFactor = uint8(fs.redundancy)
+		case "max-memory":
+			cfg.MaxMemoryMB = fs.maxMemoryMB
+		case "concurrency":
+			cfg.ConcurrencyLimit = fs.concurrency
+		}
+	})
+	if len(errs) > 0 {
+		return cfg, errs[0]
+	}
+	return cfg, nil
+}
+
+// processor creates a processor with the configuration of processorConfig.
+func (fs *flagSet) processor() (*raptorq.RaptorQProcessor, error) {
+	cfg, err := fs.processorConfig()
+	if err != nil {
+		return nil, err
+	}
+	return raptorq.NewProcessorFromConfig(cfg)
+}
+
+// layoutProcessor creates a processor for decoding the files of layout. The
+// symbol size and the redundancy factor are taken from the layout, which fixes
+// them; the other settings come from processorConfig.
+func (fs *flagSet) layoutProcessor(layout *raptorq.Layout) (*raptorq.RaptorQProcessor, error) {
+	cfg, err := fs.processorConfig()
+	if err != nil {
+		return nil, err
+	}
+	if len(layout.Blocks) > 0 {
+		first := layout.Blocks[0]
+		oti, err := raptorq.ParseOTI(first.EncoderParameters)
+		if err != nil {
+			return nil, err
+		}
+		cfg.SymbolSize = oti.SymbolSize
+		if factor := len(first.Symbols)/oti.SourceSymbols() - 1; factor > 0 {
+			cfg.RedundancyFactor = uint8(factor)
+		}
+	}
+	return raptorq.NewProcessorFromConfig(cfg)
+}
+
+// output prints v as indented JSON if --json was given, and calls text
+// otherwise.
+func (fs *flagSet) output(v any, text func(w io.Writer)) error {
+	if !fs.json {
+		text(fs.c.stdout)
+		return nil
+	}
+	enc := json.NewEncoder(fs.c.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// expectArgs checks the number of positional arguments.
+func expectArgs(args []string, min, max int) error {
+	switch {
+	case len(args) < min:
+		return usagef("missing arguments")
+	case max >= 0 && len(args) > max:
+		return usagef("unexpected arguments: %s", strings.Join(args[max:], " "))
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+// Command rq encodes files into RaptorQ symbols and decodes them back.
+//
+// Usage:
+//
+//	rq <command> [flags] [arguments]
+//
+// Commands:
+//
+//	encode      Encode a file into symbols
+//	decode      Decode symbols into the original file
+//	metadata    Write the layout of a file without writing symbols
+//	verify      Check a symbols directory against its layout
+//	inspect     Summarize a layout file
+//	repair      Regenerate missing or corrupt symbols
+//	extend      Add repair symbols to every block
+//	bench       Measure encoding and decoding throughput
+//	version     Print the library version
+//	completion  Print a shell completion script
+//
+// Every command that creates a processor accepts the ProcessorConfig flags
+// --symbol-size, --redundancy, --max-memory and --concurrency, and a --config
+// file. Values are taken from the flags, then the configuration file, then the
+// RAPTORQ_* environment variables, then the library defaults. decode, repair
+// and extend take the symbol size and the redundancy factor from the layout. --json
+// prints machine-readable output instead of text.
+//
+// The exit status tells the class of failure apart, see the exit* constants.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	raptorq "github.com/LumeraProtocol/rq-go"
+)
+
+// Exit codes of rq.
+const (
+	exitOK           = 0 // Success
+	exitError        = 1 // Any failure not covered below
+	exitUsage        = 2 // Invalid command line
+	exitInvalidInput = 3 // Invalid parameters, paths, layouts or configuration
+	exitNotFound     = 4 // An input file or directory does not exist
+	exitIO           = 5 // Reading or writing files failed
+	exitUndecodable  = 6 // Decoding failed, e.g. because of missing symbols
+	exitUnhealthy    = 7 // Symbols are missing or corrupt, or a hash does not match
+	exitResource     = 8 // Memory or concurrency limit reached; retrying may help
+	exitEncoding     = 9 // Encoding failed
+	exitInterrupted  = 130
+)
+
+// command is a subcommand of rq.
+type command struct {
+	name    string
+	args    string
+	summary string
+
+	// processor is true for commands that create a processor and accept the
+	// processor configuration flags.
+	processor bool
+
+	// setup registers the flags of the command and returns the function
+	// running it with the positional arguments. It is also used to generate
+	// the usage and the completion scripts.
+	setup func(fs *flagSet) func(args []string) error
+}
+
+// newFlags returns the flag set of cmd with all its flags registered, and the
+// function running it.
+func (cmd *command) newFlags(c *cli) (*flagSet, func(args []string) error) {
+	fs := newFlagSet(c, cmd)
+	return fs, cmd.setup(fs)
+}
+
+// commands lists the subcommands in the order of the usage message.
+var commands []*command
+
+func init() {
+	commands = []*command{
+		encodeCommand,
+		decodeCommand,
+		metadataCommand,
+		verifyCommand,
+		inspectCommand,
+		repairCommand,
+		extendCommand,
+		benchCommand,
+		versionCommand,
+		completionCommand,
+	}
+}
+
+// lookupCommand returns the command with the given name, or nil.
+func lookupCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// cli holds the state of one invocation of rq.
+type cli struct {
+	ctx    context.Context
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit status.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	c := &cli{ctx: ctx, stdout: stdout, stderr: stderr}
+
+	if len(args) == 0 {
+		c.usage(stderr)
+		return exitUsage
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			if cmd := lookupCommand(args[1]); cmd != nil {
+				fs, _ := cmd.newFlags(c)
+				fs.usage(stdout)
+				return exitOK
+			}
+		}
+		c.usage(stdout)
+		return exitOK
+	}
+
+	cmd := lookupCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(stderr, "rq: unknown command %q\n", args[0])
+		c.usage(stderr)
+		return exitUsage
+	}
+
+	fs, runCommand := cmd.newFlags(c)
+	positional, err := fs.parse(args[1:])
+	if errors.Is(err, errHelp) {
+		return exitOK
+	}
+	if err == nil {
+		err = runCommand(positional)
+	}
+	if err == nil {
+		return exitOK
+	}
+
+	var uerr *usageError
+	if errors.As(err, &uerr) {
+		fmt.Fprintf(stderr, "rq %s: %v\n", cmd.name, err)
+		fmt.Fprintf(stderr, "Run 'rq help %s' for usage.\n", cmd.name)
+		return exitUsage
+	}
+	fmt.Fprintf(stderr, "rq %s: %v\n", cmd.name, err)
+	return exitCode(err)
+}
+
+// usage prints the list of commands.
+func (c *cli) usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: rq <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-11s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'rq help <command>' for the flags of a command.")
+}
+
+// exitCode returns the exit status for an error returned by a command.
+func exitCode(err error) int {
+	var cfgErr *raptorq.ConfigError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.Is(err, errUnhealthy), errors.Is(err, raptorq.ErrHashMismatch):
+		return exitUnhealthy
+	case errors.Is(err, raptorq.ErrDecodingFailed):
+		return exitUndecodable
+	case errors.Is(err, raptorq.ErrEncodingFailed):
+		return exitEncoding
+	case errors.Is(err, raptorq.ErrMemoryLimit), errors.Is(err, raptorq.ErrConcurrencyLimit):
+		return exitResource
+	case errors.Is(err, raptorq.ErrFileNotFound), errors.Is(err, os.ErrNotExist):
+		return exitNotFound
+	case errors.Is(err, raptorq.ErrIO):
+		return exitIO
+	case errors.Is(err, raptorq.ErrInvalidParameters), errors.Is(err, raptorq.ErrInvalidPath),
+		errors.Is(err, raptorq.ErrInvalidLayout), errors.As(err, &cfgErr):
+		return exitInvalidInput
+	}
+	return exitError
+}
+
+// errUnhealthy is returned by verify when symbols are missing or corrupt.
+var errUnhealthy = errors.New("symbols are not healthy")
+
+// usageError is an error in the command line.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// usagef returns a *usageError.
+func usagef(format string, args ...any) error {
+	return &usageError{fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	raptorq "github.com/LumeraProtocol/rq-go"
+)
+
+// rq runs the command line and returns its exit status and output
+func rq(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// writeInput writes size random bytes to a file in dir
+func writeInput(t *testing.T, dir string, size int) string {
+	t.Helper()
+	data := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(data)
+	path := filepath.Join(dir, "input.bin")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// Unit test for parsing byte sizes
+func TestParseSize(t *testing.T) {
+	tests := map[string]uint64{
+		"0":      0,
+		"4096":   4096,
+		"100B":   100,
+		"64K":    64 << 10,
+		"64kb":   64 << 10,
+		"16MiB":  16 << 20,
+		"2 GiB":  2 << 30,
+		"1g":     1 << 30,
+		" 512M ": 512 << 20,
+	}
+	for in, want := range tests {
+		got, err := parseSize(in)
+		if err != nil || got != want {
+			t.Errorf("parseSize(%q) = %d, %v; expected %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "-1", "1.5M", "12X", "MiB", "99999999999999999999G"} {
+		if _, err := parseSize(in); err == nil {
+			t.Errorf("parseSize(%q): expected an error", in)
+		}
+	}
+}
+
+// Unit test for the exit codes of error classes
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, exitOK},
+		{errors.New("boom"), exitError},
+		{fmt.Errorf("wrapped: %w", raptorq.ErrInvalidParameters), exitInvalidInput},
+		{raptorq.ErrInvalidLayout, exitInvalidInput},
+		{&raptorq.ConfigError{Field: "symbol_size"}, exitInvalidInput},
+		{raptorq.ErrFileNotFound, exitNotFound},
+		{os.ErrNotExist, exitNotFound},
+		{raptorq.ErrIO, exitIO},
+		{raptorq.ErrInsufficientSymbols, exitUndecodable},
+		{raptorq.ErrDecodingFailed, exitUndecodable},
+		{raptorq.ErrEncodingFailed, exitEncoding},
+		{raptorq.ErrMemoryLimit, exitResource},
+		{raptorq.ErrConcurrencyLimit, exitResource},
+		{raptorq.ErrHashMismatch, exitUnhealthy},
+		{errUnhealthy, exitUnhealthy},
+		{context.Canceled, exitInterrupted},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, expected %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+// Unit test for the command line handling
+func TestUsage(t *testing.T) {
+	if code, _, _ := rq(t); code != exitUsage {
+		t.Errorf("Expected exit code %d without arguments, got %d", exitUsage, code)
+	}
+	if code, _, _ := rq(t, "frobnicate"); code != exitUsage {
+		t.Errorf("Expected exit code %d for an unknown command, got %d", exitUsage, code)
+	}
+	if code, _, stderr := rq(t, "encode", "--no-such-flag", "x"); code != exitUsage || !strings.Contains(stderr, "no-such-flag") {
+		t.Errorf("Expected a usage error for an unknown flag, got %d: %s", code, stderr)
+	}
+	if code, _, _ := rq(t, "decode", "only-one"); code != exitUsage {
+		t.Errorf("Expected exit code %d for missing arguments, got %d", exitUsage, code)
+	}
+	if code, stdout, _ := rq(t, "help", "encode"); code != exitOK || !strings.Contains(stdout, "-symbol-size") {
+		t.Errorf("Expected the usage of encode, got %d: %s", code, stdout)
+	}
+	if code, stdout, _ := rq(t, "decode", "--help"); code != exitOK || !strings.Contains(stdout, "-layout") {
+		t.Errorf("Expected the usage of decode, got %d: %s", code, stdout)
+	}
+	if code, _, _ := rq(t, "extend", "symbols"); code != exitUsage {
+		t.Errorf("Expected exit code %d for extend without --count, got %d", exitUsage, code)
+	}
+	if code, _, stderr := rq(t, "encode", "--symbol-size", "70000", "x"); code != exitUsage {
+		t.Errorf("Expected a usage error for a symbol size out of range, got %d: %s", code, stderr)
+	}
+
+	// Flags may follow positional arguments
+	fs := newFlagSet(&cli{}, encodeCommand)
+	output := fs.String("output", "", "")
+	args, err := fs.parse([]string{"in", "--output", "dir", "--json", "--", "--literal"})
+	if err != nil || *output != "dir" || !fs.json || strings.Join(args, ",") != "in,--literal" {
+		t.Errorf("Unexpected parse result %v, %v (output %q, json %v)", args, err, *output, fs.json)
+	}
+}
+
+// Unit test for the completion scripts
+func TestCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		code, stdout, stderr := rq(t, "completion", shell)
+		if code != exitOK {
+			t.Fatalf("completion %s failed with %d: %s", shell, code, stderr)
+		}
+		for _, want := range []string{"encode", "repair", "symbol-size", "layout"} {
+			if !strings.Contains(stdout, want) {
+				t.Errorf("completion %s does not mention %q", shell, want)
+			}
+		}
+	}
+	if code, _, _ := rq(t, "completion", "tcsh"); code != exitUsage {
+		t.Errorf("Expected a usage error for an unsupported shell, got %d", code)
+	}
+}
+
+// System test encoding, verifying, inspecting, repairing and decoding a file with the rq command
+func TestSysCommands(t *testing.T) {
+	dir := t.TempDir()
+	input := writeInput(t, dir, 300*1024+17)
+	symbolsDir := filepath.Join(dir, "symbols")
+
+	code, stdout, stderr := rq(t, "encode", "--json", "--symbol-size", "4096", "--block-size", "128K", "-output", symbolsDir, input)
+	if code != exitOK {
+		t.Fatalf("encode failed with %d: %s", code, stderr)
+	}
+	var res raptorq.ProcessResult
+	if err := json.Unmarshal([]byte(stdout), &res); err != nil {
+		t.Fatalf("encode printed invalid JSON: %v\n%s", err, stdout)
+	}
+	if len(res.Blocks) != 3 {
+		t.Fatalf("Expected 3 blocks, got %d", len(res.Blocks))
+	}
+	layoutPath := filepath.Join(symbolsDir, layoutFileName)
+
+	if code, stdout, stderr := rq(t, "verify", symbolsDir); code != exitOK || !strings.Contains(stdout, "healthy") {
+		t.Fatalf("verify failed with %d: %s%s", code, stdout, stderr)
+	}
+	if code, stdout, stderr := rq(t, "inspect", "--symbols", symbolsDir, layoutPath); code != exitOK || !strings.Contains(stdout, "healthy") {
+		t.Fatalf("inspect failed with %d: %s%s", code, stdout, stderr)
+	}
+	code, stdout, stderr = rq(t, "inspect", "--json", layoutPath)
+	var summary raptorq.LayoutSummary
+	if code != exitOK || json.Unmarshal([]byte(stdout), &summary) != nil || summary.Blocks[2].Hash != res.Blocks[2].Hash {
+		t.Fatalf("inspect --json failed with %d: %s%s", code, stdout, stderr)
+	}
+
+	output := filepath.Join(dir, "out", "decoded.bin")
+	if code, _, stderr := rq(t, "decode", symbolsDir, output); code != exitOK {
+		t.Fatalf("decode failed with %d: %s", code, stderr)
+	}
+	if code, _, stderr := rq(t, "verify", "--file", output, symbolsDir); code != exitOK {
+		t.Fatalf("Decoded file does not match the layout (%d): %s", code, stderr)
+	}
+
+	if code, _, _ := rq(t, "decode", filepath.Join(dir, "missing"), output); code != exitNotFound {
+		t.Errorf("Expected exit code %d for a missing symbols directory, got %d", exitNotFound, code)
+	}
+	if code, _, _ := rq(t, "repair", symbolsDir); code != exitOK {
+		t.Errorf("Expected repair of healthy symbols to succeed, got %d", code)
+	}
+
+	// Lose one symbol of block 1; only builds with repair symbols can restore it
+	layout, err := raptorq.LoadLayout(layoutPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lost := filepath.Join(symbolsDir, "block_1", layout.Blocks[1].Symbols[0])
+	if err := os.Remove(lost); err != nil {
+		t.Fatal(err)
+	}
+	if res.TotalRepairSymbols == 0 {
+		if code, _, _ := rq(t, "verify", symbolsDir); code != exitUndecodable {
+			t.Fatalf("Expected exit code %d for undecodable symbols, got %d", exitUndecodable, code)
+		}
+		t.Skip("repair symbols are not supported by this build")
+	}
+	if code, _, _ := rq(t, "verify", symbolsDir); code != exitUnhealthy {
+		t.Fatalf("Expected exit code %d for a missing symbol, got %d", exitUnhealthy, code)
+	}
+	if code, stdout, stderr := rq(t, "repair", "--json", symbolsDir); code != exitOK || !strings.Contains(stdout, `"restored": 1`) {
+		t.Fatalf("repair failed with %d: %s%s", code, stdout, stderr)
+	}
+	if code, _, stderr := rq(t, "verify", symbolsDir); code != exitOK {
+		t.Fatalf("Symbols are not healthy after repair (%d): %s", code, stderr)
+	}
+	if code, stdout, stderr := rq(t, "extend", "--json", "--count", "2", symbolsDir); code != exitOK || !strings.Contains(stdout, `"added": 6`) {
+		t.Fatalf("extend failed with %d: %s%s", code, stdout, stderr)
+	}
+	if code, _, stderr := rq(t, "verify", symbolsDir); code != exitOK {
+		t.Fatalf("Symbols are not healthy after extend (%d): %s", code, stderr)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	raptorq "github.com/LumeraProtocol/rq-go"
+)
+
+var repairCommand = &command{
+	name:      "repair",
+	args:      "<symbols_dir>",
+	summary:   "Regenerate missing or corrupt symbols",
+	processor: true,
+	setup: func(fs *flagSet) func(args []string) error {
+		layout := fs.String("layout", "", "layout `file` (default <symbols_dir>/"+layoutFileName+")")
+
+		return func(args []string) error {
+			if err := expectArgs(args, 1, 1); err != nil {
+				return err
+			}
+			symbolsDir := args[0]
+			layoutPath := layoutPathFlag(*layout, symbolsDir)
+			l, err := loadLayout(layoutPath)
+			if err != nil {
+				return err
+			}
+			processor, err := fs.layoutProcessor(l)
+			if err != nil {
+				return err
+			}
+			defer processor.Free()
+
+			res, err := repair(fs.c.ctx, processor, symbolsDir, layoutPath, l)
+			if err != nil {
+				return err
+			}
+			return fs.output(res, func(w io.Writer) {
+				if res.Restored == 0 {
+					fmt.Fprintln(w, "All symbols are present and intact")
+					return
+				}
+				for _, b := range res.Blocks {
+					fmt.Fprintf(w, "Block %d: restored %d symbols\n", b.BlockID, len(b.Restored))
+				}
+				fmt.Fprintf(w, "Restored %d symbols\n", res.Restored)
+			})
+		}
+	},
+}
+
+var extendCommand = &command{
+	name:      "extend",
+	args:      "<symbols_dir>",
+	summary:   "Add repair symbols to every block",
+	processor: true,
+	setup: func(fs *flagSet) func(args []string) error {
+		layout := fs.String("layout", "", "layout `file` (default <symbols_dir>/"+layoutFileName+")")
+		count := fs.Int("count", 0, "`number` of repair symbols to add per block")
+
+		return func(args []string) error {
+			if err := expectArgs(args, 1, 1); err != nil {
+				return err
+			}
+			if *count <= 0 {
+				return usagef("--count must be positive")
+			}
+			symbolsDir := args[0]
+			layoutPath := layoutPathFlag(*layout, symbolsDir)
+			l, err := loadLayout(layoutPath)
+			if err != nil {
+				return err
+			}
+			processor, err := fs.layoutProcessor(l)
+			if err != nil {
+				return err
+			}
+			defer processor.Free()
+
+			res, err := processor.ExtendRepairSymbolsContext(fs.c.ctx, symbolsDir, layoutPath, *count)
+			if err != nil {
+				return err
+			}
+			return fs.output(res, func(w io.Writer) {
+				fmt.Fprintf(w, "Added %d repair symbols to %d blocks\n", res.Added, len(res.Blocks))
+			})
+		}
+	},
+}
+
+// repair restores the missing and corrupt symbols of symbolsDir found by
+// VerifySymbols. processor must have the symbol size of the layout.
+func repair(ctx context.Context, processor *raptorq.RaptorQProcessor, symbolsDir, layoutPath string, layout *raptorq.Layout) (*raptorq.RepairResult, error) {
+	report, err := raptorq.VerifySymbols(symbolsDir, layout)
+	if err != nil {
+		return nil, err
+	}
+	var lost []string
+	for _, b := range report.Blocks {
+		lost = append(append(lost, b.Missing...), b.Corrupt...)
+	}
+	if len(lost) == 0 {
+		return &raptorq.RepairResult{Blocks: []raptorq.RepairedBlock{}}, nil
+	}
+	if !report.Decodable {
+		return nil, fmt.Errorf("%w: not enough valid symbols to decode every block", raptorq.ErrInsufficientSymbols)
+	}
+	return processor.RepairSymbolsContext(ctx, symbolsDir, layoutPath, lost)
+}
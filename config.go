@@ -0,0 +1,309 @@
+package rq_go
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Limits enforced by ProcessorConfig.Validate.
+const (
+	// SymbolAlignment is the symbol alignment used by the native encoder. The
+	// configured symbol size is rounded down to a multiple of it.
+	SymbolAlignment = 8
+
+	// MinMaxMemoryMB is the smallest memory limit accepted for a session. Below
+	// it the native library cannot hold a block of useful size, and every
+	// operation fails with a memory limit error.
+	MinMaxMemoryMB uint64 = 64
+
+	// DefaultEnvPrefix is the prefix of the environment variables read by
+	// ProcessorConfigFromEnv when no prefix is given.
+	DefaultEnvPrefix = "RAPTORQ"
+)
+
+// DefaultProcessorConfig returns the configuration used by
+// NewDefaultRaptorQProcessor. Its SymbolSize is DefaultSymbolSize rounded down
+// to a multiple of SymbolAlignment, which is the symbol size the native
+// encoder uses for DefaultSymbolSize.
+func DefaultProcessorConfig() ProcessorConfig {
+	return ProcessorConfig{
+		SymbolSize:       DefaultSymbolSize - DefaultSymbolSize%SymbolAlignment,
+		RedundancyFactor: DefaultRedundancyFactor,
+		MaxMemoryMB:      DefaultMaxMemoryMB,
+		ConcurrencyLimit: DefaultConcurrencyLimit,
+	}
+}
+
+// ConfigError describes an invalid field of a ProcessorConfig.
+// It unwraps to ErrInvalidParameters.
+type ConfigError struct {
+	// Field is the JSON name of the offending field.
+	Field string
+
+	// Value is the rejected value.
+	Value any
+
+	// Reason explains why the value is rejected.
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid %s %v: %s", e.Field, e.Value, e.Reason)
+}
+
+// Unwrap returns ErrInvalidParameters.
+func (e *ConfigError) Unwrap() error {
+	return ErrInvalidParameters
+}
+
+// Validate checks the configuration before it is handed to the native library.
+//
+// It verifies that:
+//   - SymbolSize is a non-zero multiple of SymbolAlignment, as RFC 6330
+//     requires of the symbol size T for the alignment Al;
+//   - RedundancyFactor is at least 1, so that repair symbols are generated;
+//   - MaxMemoryMB is at least MinMaxMemoryMB;
+//   - ConcurrencyLimit is at least 1.
+//
+// The block size is chosen per call, so the RFC 6330 limits on the size of a
+// block are checked separately by ValidateBlockSize.
+//
+// Returns:
+//   - error: nil if the configuration is valid, otherwise the errors of all
+//     invalid fields joined with errors.Join. Each of them is a *ConfigError,
+//     and all of them match ErrInvalidParameters.
+func (c ProcessorConfig) Validate() error {
+	var errs []error
+	if c.SymbolSize < SymbolAlignment {
+		errs = append(errs, &ConfigError{"symbol_size", c.SymbolSize,
+			fmt.Sprintf("must be at least %d bytes, symbols are aligned to %d bytes", SymbolAlignment, SymbolAlignment)})
+	} else if c.SymbolSize%SymbolAlignment != 0 {
+		errs = append(errs, &ConfigError{"symbol_size", c.SymbolSize,
+			fmt.Sprintf("must be a multiple of the symbol alignment of %d bytes", SymbolAlignment)})
+	}
+	if c.RedundancyFactor == 0 {
+		errs = append(errs, &ConfigError{"redundancy_factor", c.RedundancyFactor,
+			"must be at least 1, otherwise no repair symbols are generated"})
+	}
+	if c.MaxMemoryMB < MinMaxMemoryMB {
+		errs = append(errs, &ConfigError{"max_memory_mb", c.MaxMemoryMB,
+			fmt.Sprintf("must be at least %d MB", MinMaxMemoryMB)})
+	}
+	if c.ConcurrencyLimit == 0 {
+		errs = append(errs, &ConfigError{"concurrency_limit", c.ConcurrencyLimit,
+			"must be at least 1"})
+	}
+	return errors.Join(errs...)
+}
+
+// MaxBlockSize returns the largest block RFC 6330 can encode with the
+// configured symbol size: MaxSourceBlocks source blocks of
+// MaxSourceSymbolsPerBlock symbols each. It returns 0 if SymbolSize is
+// smaller than SymbolAlignment.
+func (c ProcessorConfig) MaxBlockSize() uint64 {
+	t := uint64(c.SymbolSize - c.SymbolSize%SymbolAlignment)
+	return MaxSourceBlocks * MaxSourceSymbolsPerBlock * t
+}
+
+// ValidateBlockSize checks a block size passed to the encoding functions
+// against the RFC 6330 limits for the configured symbol size: a block is
+// split into at most MaxSourceBlocks source blocks of at most
+// MaxSourceSymbolsPerBlock (K'max) symbols, see MaxBlockSize.
+//
+// Backends may support smaller blocks only: the pure-Go backend encodes every
+// block as a single source block of at most K'max symbols.
+//
+// Parameters:
+//   - blockSize: The block size in bytes. 0 selects the recommended block
+//     size and is always accepted.
+//
+// Returns:
+//   - error: nil if the block size is valid, otherwise a *ConfigError for
+//     the field block_size, matching ErrInvalidParameters.
+func (c ProcessorConfig) ValidateBlockSize(blockSize int) error {
+	if blockSize < 0 {
+		return &ConfigError{"block_size", blockSize, "cannot be negative"}
+	}
+	if max := c.MaxBlockSize(); uint64(blockSize) > max {
+		return &ConfigError{"block_size", blockSize,
+			fmt.Sprintf("exceeds the RFC 6330 limit of %d bytes for %d-byte symbols", max, c.SymbolSize)}
+	}
+	return nil
+}
+
+// NewProcessorFromConfig validates cfg and creates a processor with it.
+//
+// Parameters:
+//   - cfg: The configuration of the session.
+//
+// Returns:
+//   - *RaptorQProcessor: A new processor instance if successful.
+//   - error: The errors of Validate if cfg is invalid, or an error if the
+//     session initialization fails.
+//
+// Example:
+//
+//	cfg, err := raptorq.LoadProcessorConfig("raptorq.yaml")
+//	if err != nil {
+//	    return err
+//	}
+//	processor, err := raptorq.NewProcessorFromConfig(cfg)
+//	if err != nil {
+//	    return err
+//	}
+//	defer processor.Free()
+func NewProcessorFromConfig(cfg ProcessorConfig) (*RaptorQProcessor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return NewRaptorQProcessor(cfg.SymbolSize, cfg.RedundancyFactor, cfg.MaxMemoryMB, cfg.ConcurrencyLimit)
+}
+
+// Option changes one setting of the configuration built by NewProcessor.
+type Option func(*ProcessorConfig)
+
+// WithSymbolSize sets the size of each symbol in bytes.
+func WithSymbolSize(size uint16) Option {
+	return func(c *ProcessorConfig) { c.SymbolSize = size }
+}
+
+// WithRedundancyFactor sets the number of repair symbols generated per source symbol.
+func WithRedundancyFactor(factor uint8) Option {
+	return func(c *ProcessorConfig) { c.RedundancyFactor = factor }
+}
+
+// WithMaxMemoryMB sets the memory limit of the session in megabytes.
+func WithMaxMemoryMB(mb uint64) Option {
+	return func(c *ProcessorConfig) { c.MaxMemoryMB = mb }
+}
+
+// WithConcurrencyLimit sets the maximum number of concurrent operations.
+func WithConcurrencyLimit(limit uint64) Option {
+	return func(c *ProcessorConfig) { c.ConcurrencyLimit = limit }
+}
+
+// WithConfig replaces the whole configuration, for example with one loaded by
+// LoadProcessorConfig. Options after it still apply.
+func WithConfig(cfg ProcessorConfig) Option {
+	return func(c *ProcessorConfig) { *c = cfg }
+}
+
+// NewProcessor creates a processor from the default configuration changed by
+// opts, validating the result like NewProcessorFromConfig.
+//
+// Example:
+//
+//	processor, err := raptorq.NewProcessor(
+//	    raptorq.WithSymbolSize(50000),
+//	    raptorq.WithMaxMemoryMB(raptorq.MaxMemoryMB_4GB),
+//	)
+func NewProcessor(opts ...Option) (*RaptorQProcessor, error) {
+	cfg := DefaultProcessorConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return NewProcessorFromConfig(cfg)
+}
+
+// Config returns the configuration the processor's session was created with.
+func (p *RaptorQProcessor) Config() ProcessorConfig {
+	return p.config
+}
+
+// ProcessorConfigFromJSON parses a JSON configuration. Fields that are not
+// present keep their default values; unknown fields are rejected.
+func ProcessorConfigFromJSON(data []byte) (ProcessorConfig, error) {
+	cfg := DefaultProcessorConfig()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return ProcessorConfig{}, fmt.Errorf("%w: failed to parse JSON configuration: %v", ErrInvalidParameters, err)
+	}
+	return cfg, nil
+}
+
+// ProcessorConfigFromYAML parses a YAML configuration. Fields that are not
+// present keep their default values; unknown fields are rejected.
+func ProcessorConfigFromYAML(data []byte) (ProcessorConfig, error) {
+	cfg := DefaultProcessorConfig()
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return ProcessorConfig{}, fmt.Errorf("%w: failed to parse YAML configuration: %v", ErrInvalidParameters, err)
+	}
+	return cfg, nil
+}
+
+// LoadProcessorConfig reads a configuration file. Files ending in .yaml or
+// .yml are parsed as YAML, all others as JSON. The result is not validated;
+// NewProcessorFromConfig does that.
+func LoadProcessorConfig(path string) (ProcessorConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ProcessorConfig{}, err
+	}
+
+	var cfg ProcessorConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		cfg, err = ProcessorConfigFromYAML(data)
+	default:
+		cfg, err = ProcessorConfigFromJSON(data)
+	}
+	if err != nil {
+		return ProcessorConfig{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ProcessorConfigFromEnv reads a configuration from the environment variables
+// <prefix>_SYMBOL_SIZE, <prefix>_REDUNDANCY_FACTOR, <prefix>_MAX_MEMORY_MB and
+// <prefix>_CONCURRENCY_LIMIT. Unset variables keep their default values. An
+// empty prefix selects DefaultEnvPrefix.
+//
+// Returns:
+//   - ProcessorConfig: The configuration read from the environment.
+//   - error: The errors of all variables that are not valid numbers, as
+//     *ConfigError values joined with errors.Join.
+func ProcessorConfigFromEnv(prefix string) (ProcessorConfig, error) {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	cfg := DefaultProcessorConfig()
+
+	var errs []error
+	parse := func(field string, bits int, set func(uint64)) {
+		name := prefix + "_" + strings.ToUpper(field)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+		n, err := strconv.ParseUint(strings.TrimSpace(value), 10, bits)
+		if err != nil {
+			errs = append(errs, &ConfigError{field, value,
+				fmt.Sprintf("%s must be an unsigned %d-bit integer", name, bits)})
+			return
+		}
+		set(n)
+	}
+
+	parse("symbol_size", 16, func(n uint64) { cfg.SymbolSize = uint16(n) })
+	parse("redundancy_factor", 8, func(n uint64) { cfg.RedundancyFactor = uint8(n) })
+	parse("max_memory_mb", 64, func(n uint64) { cfg.MaxMemoryMB = n })
+	parse("concurrency_limit", 64, func(n uint64) { cfg.ConcurrencyLimit = n })
+
+	if err := errors.Join(errs...); err != nil {
+		return ProcessorConfig{}, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,153 @@
+package rq_go
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Unit test for ProcessorConfig validation
+func TestProcessorConfigValidate(t *testing.T) {
+	if err := DefaultProcessorConfig().Validate(); err != nil {
+		t.Fatalf("Default configuration should be valid: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		mutate    func(c *ProcessorConfig)
+		blockSize int
+		field     string
+	}{
+		{"zero symbol size", func(c *ProcessorConfig) { c.SymbolSize = 0 }, 0, "symbol_size"},
+		{"symbol size below alignment", func(c *ProcessorConfig) { c.SymbolSize = 7 }, 0, "symbol_size"},
+		{"unaligned symbol size", func(c *ProcessorConfig) { c.SymbolSize = 4100 }, 0, "symbol_size"},
+		{"unaligned default symbol size", func(c *ProcessorConfig) { c.SymbolSize = DefaultSymbolSize }, 0, "symbol_size"},
+		{"zero redundancy", func(c *ProcessorConfig) { c.RedundancyFactor = 0 }, 0, "redundancy_factor"},
+		{"memory below floor", func(c *ProcessorConfig) { c.MaxMemoryMB = MinMaxMemoryMB - 1 }, 0, "max_memory_mb"},
+		{"zero concurrency", func(c *ProcessorConfig) { c.ConcurrencyLimit = 0 }, 0, "concurrency_limit"},
+		{"negative block size", func(c *ProcessorConfig) {}, -1, "block_size"},
+		{"block above K'max source blocks", func(c *ProcessorConfig) { c.SymbolSize = SymbolAlignment },
+			MaxSourceBlocks*MaxSourceSymbolsPerBlock*SymbolAlignment + 1, "block_size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultProcessorConfig()
+			tt.mutate(&cfg)
+
+			err := errors.Join(cfg.Validate(), cfg.ValidateBlockSize(tt.blockSize))
+			if !errors.Is(err, ErrInvalidParameters) {
+				t.Fatalf("Expected ErrInvalidParameters, got: %v", err)
+			}
+			var cfgErr *ConfigError
+			if !errors.As(err, &cfgErr) || cfgErr.Field != tt.field {
+				t.Fatalf("Expected an error for %s, got: %v", tt.field, err)
+			}
+		})
+	}
+
+	// Blocks of up to MaxSourceBlocks source blocks of K'max symbols are accepted
+	cfg := DefaultProcessorConfig()
+	cfg.SymbolSize = SymbolAlignment
+	if err := cfg.ValidateBlockSize(int(cfg.MaxBlockSize())); err != nil {
+		t.Fatalf("Expected the largest block to be accepted: %v", err)
+	}
+	if err := cfg.ValidateBlockSize(0); err != nil {
+		t.Fatalf("Expected the recommended block size to be accepted: %v", err)
+	}
+
+	// All invalid fields are reported at once
+	err := ProcessorConfig{}.Validate()
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 4 {
+		t.Fatalf("Expected 4 errors, got %d: %v", n, err)
+	}
+
+	if _, err := NewProcessorFromConfig(ProcessorConfig{}); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("Expected NewProcessorFromConfig to reject an invalid configuration, got: %v", err)
+	}
+	if _, err := NewProcessor(WithRedundancyFactor(0)); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("Expected NewProcessor to reject an invalid option, got: %v", err)
+	}
+}
+
+// Test that options are applied and reported by Config
+func TestNewProcessorOptions(t *testing.T) {
+	skipWithoutNativeLibrary(t)
+	processor, err := NewProcessor(
+		WithSymbolSize(50000),
+		WithRedundancyFactor(6),
+		WithMaxMemoryMB(MaxMemoryMB_4GB),
+		WithConcurrencyLimit(2),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	expected := ProcessorConfig{SymbolSize: 50000, RedundancyFactor: 6, MaxMemoryMB: MaxMemoryMB_4GB, ConcurrencyLimit: 2}
+	if processor.Config() != expected {
+		t.Fatalf("Expected %+v, got %+v", expected, processor.Config())
+	}
+
+	// The default processor uses the validated default configuration
+	defaultProcessor, err := NewDefaultRaptorQProcessor()
+	if err != nil {
+		t.Fatalf("Failed to create default processor: %v", err)
+	}
+	defer defaultProcessor.Free()
+	if defaultProcessor.Config() != DefaultProcessorConfig() {
+		t.Fatalf("Expected %+v, got %+v", DefaultProcessorConfig(), defaultProcessor.Config())
+	}
+}
+
+// Unit test for loading configurations from JSON, YAML and the environment
+func TestLoadProcessorConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "raptorq.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"symbol_size": 50000, "redundancy_factor": 8}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadProcessorConfig(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to load JSON configuration: %v", err)
+	}
+	if cfg.SymbolSize != 50000 || cfg.RedundancyFactor != 8 || cfg.MaxMemoryMB != DefaultMaxMemoryMB {
+		t.Fatalf("Unexpected JSON configuration: %+v", cfg)
+	}
+
+	yamlPath := filepath.Join(dir, "raptorq.yaml")
+	if err := os.WriteFile(yamlPath, []byte("max_memory_mb: 4096\nconcurrency_limit: 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadProcessorConfig(yamlPath)
+	if err != nil {
+		t.Fatalf("Failed to load YAML configuration: %v", err)
+	}
+	if cfg.MaxMemoryMB != 4096 || cfg.ConcurrencyLimit != 2 || cfg.SymbolSize != DefaultProcessorConfig().SymbolSize {
+		t.Fatalf("Unexpected YAML configuration: %+v", cfg)
+	}
+
+	if _, err := ProcessorConfigFromJSON([]byte(`{"symbol_sise": 1}`)); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("Expected unknown JSON fields to be rejected, got: %v", err)
+	}
+	if _, err := ProcessorConfigFromYAML([]byte("symbol_size: 70000\n")); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("Expected an out-of-range YAML value to be rejected, got: %v", err)
+	}
+
+	t.Setenv("RQTEST_SYMBOL_SIZE", "1024")
+	t.Setenv("RQTEST_MAX_MEMORY_MB", "2048")
+	cfg, err = ProcessorConfigFromEnv("RQTEST")
+	if err != nil {
+		t.Fatalf("Failed to read configuration from the environment: %v", err)
+	}
+	if cfg.SymbolSize != 1024 || cfg.MaxMemoryMB != 2048 || cfg.RedundancyFactor != DefaultRedundancyFactor {
+		t.Fatalf("Unexpected environment configuration: %+v", cfg)
+	}
+
+	t.Setenv("RQTEST_REDUNDANCY_FACTOR", "300")
+	var cfgErr *ConfigError
+	if _, err := ProcessorConfigFromEnv("RQTEST"); !errors.As(err, &cfgErr) || cfgErr.Field != "redundancy_factor" {
+		t.Fatalf("Expected an error for redundancy_factor, got: %v", err)
+	}
+}
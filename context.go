@@ -0,0 +1,386 @@
+package rq_go
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// EncodeFileContext encodes a file like EncodeFile, honouring cancellation and
+// deadlines of ctx.
+//
+// The native library cannot be interrupted while it encodes a block, so the
+// file is split into blocks on the Go side (using blockSize, or the recommended
+// block size if blockSize is 0) and each block is handed to the native library
+// separately. ctx is checked before every block; once it is done, the symbol
+// directories written by this call are removed and ctx.Err() is returned
+// (context.Canceled or context.DeadlineExceeded).
+//
+// The produced symbols and layout file are identical to those of EncodeFile
+// with the same block size. Files that fit in a single block are encoded with
+// one native call, in which case cancellation only takes effect before it starts.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation of the operation.
+//   - inputPath: Path to the input file to be encoded.
+//   - outputDir: Directory where the encoded symbols will be written.
+//   - blockSize: Size of each block in bytes. If 0, a recommended block size will be used.
+//
+// Returns:
+//   - *ProcessResult: Information about the encoding process, as returned by EncodeFile.
+//   - error: ctx.Err() if the context ended, or the error of the failing block.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//
+//	result, err := processor.EncodeFileContext(ctx, "input.dat", "symbols/", 0)
+//	if errors.Is(err, context.DeadlineExceeded) {
+//	    return fmt.Errorf("encoding took too long: %w", err)
+//	}
+func (p *RaptorQProcessor) EncodeFileContext(ctx context.Context, inputPath, outputDir string, blockSize int) (*ProcessResult, error) {
+	return p.encodeBlocks(ctx, "EncodeFile", inputPath, outputDir, filepath.Join(outputDir, layoutFileName), blockSize, nil)
+}
+
+// CreateMetadataContext creates layout metadata like CreateMetadata, honouring
+// cancellation and deadlines of ctx.
+//
+// As with EncodeFileContext, the file is processed one block at a time and ctx
+// is checked between blocks. The layout file is only written once every block
+// has been processed, so a cancelled call leaves no output behind.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation of the operation.
+//   - inputPath: Path to the input file to analyze.
+//   - layoutFile: Path where the layout information will be written.
+//   - blockSize: Size of each block in bytes. If 0, a recommended block size will be used.
+//
+// Returns:
+//   - *ProcessResult: Information about the metadata creation process.
+//   - error: ctx.Err() if the context ended, or the error of the failing block.
+func (p *RaptorQProcessor) CreateMetadataContext(ctx context.Context, inputPath, layoutFile string, blockSize int) (*ProcessResult, error) {
+	return p.encodeBlocks(ctx, "CreateMetadata", inputPath, "", layoutFile, blockSize, nil)
+}
+
+// DecodeSymbolsContext decodes symbols like DecodeSymbols, honouring
+// cancellation and deadlines of ctx.
+//
+// Each block listed in the layout is decoded by a separate native call and ctx
+// is checked before every block. The recovered data is assembled in a temporary
+// file next to outputPath and only renamed into place when all blocks have been
+// decoded, so a cancelled or failed call never leaves a partial output file.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation of the operation.
+//   - symbolsDir: Directory containing the encoded symbols.
+//   - outputPath: Path where the reconstructed file will be written.
+//   - layoutPath: Path to the layout file containing metadata about the encoding.
+//
+// Returns:
+//   - error: ctx.Err() if the context ended, the error of the failing block, or nil on success.
+func (p *RaptorQProcessor) DecodeSymbolsContext(ctx context.Context, symbolsDir, outputPath, layoutPath string) error {
+	if p.SessionID == 0 {
+		return ErrSessionClosed
+	}
+	if symbolsDir == "" || outputPath == "" || layoutPath == "" {
+		return fmt.Errorf("%w: symbolsDir, outputPath, and layoutPath cannot be empty", ErrInvalidParameters)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, trace := p.startOperation(ctx, "DecodeSymbols")
+	doc, err := LoadLayout(layoutPath)
+	if err != nil {
+		// Layouts that cannot be read are left to the native library to report.
+		err = p.decodeSymbols(ctx, symbolsDir, outputPath, layoutPath, nil)
+		trace.end(err)
+		return err
+	}
+	trace.setAttributes(layoutAttributes(doc)...)
+
+	progress := p.startProgress(ctx, "DecodeSymbols", len(doc.Blocks), doc.TotalSize())
+	err = p.decodeLayout(ctx, doc, symbolsDir, outputPath, layoutPath, progress, trace)
+	progress.finish(err)
+	trace.end(err)
+	return err
+}
+
+// decodeLayout decodes the blocks of doc for DecodeSymbolsContext, reporting
+// each of them to progress and tracing each of them as a child of trace.
+func (p *RaptorQProcessor) decodeLayout(ctx context.Context, doc *Layout, symbolsDir, outputPath, layoutPath string,
+	progress *progressReporter, trace *traceSpan) error {
+	if len(doc.Blocks) <= 1 {
+		// Single-block layouts are decoded in one call.
+		var block BlockLayout
+		if len(doc.Blocks) == 1 {
+			block = doc.Blocks[0]
+		}
+		progress.blockStarted(block.BlockID)
+		blockCtx := trace.startBlock(ctx, block.BlockID, block.Size)
+		if err := p.decodeSymbols(blockCtx, symbolsDir, outputPath, layoutPath, doc); err != nil {
+			return err
+		}
+		trace.finishBlock()
+		progress.blockFinished(block.BlockID, block.Size, 0)
+		return nil
+	}
+
+	stageDir, err := os.MkdirTemp(filepath.Dir(outputPath), ".rq-staging-")
+	if err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	partialPath := filepath.Join(stageDir, "output.bin")
+	out, err := os.Create(partialPath)
+	if err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	defer out.Close()
+
+	for _, block := range doc.Blocks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		progress.blockStarted(block.BlockID)
+		blockCtx := trace.startBlock(ctx, block.BlockID, block.Size)
+		if err := p.decodeStagedBlock(blockCtx, stageDir, symbolsDir, block, out); err != nil {
+			return err
+		}
+		trace.finishBlock()
+		progress.blockFinished(block.BlockID, block.Size, 0)
+	}
+
+	if err := out.Close(); err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	if err := os.Rename(partialPath, outputPath); err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	return nil
+}
+
+// decodeStagedBlock decodes a single block of a layout through the native
+// library and writes the recovered bytes into out at the block's offset.
+func (p *RaptorQProcessor) decodeStagedBlock(ctx context.Context, stageDir, symbolsDir string, block BlockLayout, out io.WriterAt) error {
+	w := io.NewOffsetWriter(out, int64(block.OriginalOffset))
+	if _, err := p.decodeBlockRange(ctx, stageDir, symbolsDir, block, 0, block.Size, w); err != nil {
+		return err
+	}
+	return nil
+}
+
+// decodeBlockFile decodes a single block of a layout into outputPath.
+//
+// The block is described to the native decoder by a one-block layout whose
+// offset is reset to zero, so outputPath receives exactly the block data.
+func (p *RaptorQProcessor) decodeBlockFile(ctx context.Context, stageDir, symbolsDir string, block BlockLayout, outputPath string) error {
+	blockID := block.BlockID
+	block.OriginalOffset = 0
+
+	blockLayout := filepath.Join(stageDir, fmt.Sprintf("block_%d_layout.json", blockID))
+	defer os.Remove(blockLayout)
+
+	doc := &Layout{Blocks: []BlockLayout{block}}
+	if err := doc.Save(blockLayout); err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	if err := p.decodeSymbols(ctx, symbolsDir, outputPath, blockLayout, doc); err != nil {
+		return fmt.Errorf("block %d: %w", blockID, err)
+	}
+	return nil
+}
+
+// encodeBlocks implements EncodeFileContext and CreateMetadataContext.
+//
+// Every block of the input is staged into its own file and processed with a
+// native call whose block size equals the block length, so the native library
+// produces exactly one block. The resulting block layouts are renumbered,
+// shifted to their original offsets and merged into a single layout file.
+// When outputDir is empty only metadata is created. Progress events are sent to
+// the ProgressFunc of ctx or of the processor, if any. If sink is not nil, it
+// is called with the symbols directory of each block once it is complete.
+func (p *RaptorQProcessor) encodeBlocks(ctx context.Context, op, inputPath, outputDir, layoutPath string, blockSize int, sink blockSink) (result *ProcessResult, err error) {
+	if p.SessionID == 0 {
+		return nil, ErrSessionClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ctx, trace := p.startOperation(ctx, op)
+	defer func() {
+		trace.endResult(result, err)
+	}()
+
+	src, err := os.Open(inputPath)
+	if err != nil {
+		return nil, ioError(op, err)
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return nil, ioError(op, err)
+	}
+
+	fileSize := uint64(info.Size())
+	effective := p.effectiveBlockSize(ctx, fileSize, blockSize)
+	spans := splitBlocks(fileSize, effective)
+	trace.setAttributes(intAttr(AttrBlockSize, int64(effective)))
+
+	progress := p.startProgress(ctx, op, len(spans), fileSize)
+	result, err = p.encodeSpans(ctx, op, src, spans, inputPath, outputDir, layoutPath, blockSize, sink, progress, trace)
+	progress.finish(err)
+	return result, err
+}
+
+// blockSink receives the symbols of a block produced by encodeBlocks. dir is
+// the directory holding the symbol files of the block.
+type blockSink func(blockID uint64, dir string) error
+
+// encodeSpans processes the blocks of src described by spans for encodeBlocks,
+// reporting each of them to progress and tracing each of them as a child of
+// trace.
+func (p *RaptorQProcessor) encodeSpans(ctx context.Context, op string, src *os.File, spans []blockSpan,
+	inputPath, outputDir, layoutPath string, blockSize int, sink blockSink, progress *progressReporter, trace *traceSpan) (*ProcessResult, error) {
+	writeSymbols := outputDir != ""
+
+	if len(spans) == 1 {
+		// A single block cannot be interrupted, so it is processed in one call.
+		progress.blockStarted(0)
+		blockCtx := trace.startBlock(ctx, 0, spans[0].size)
+		res, err := p.runBlock(blockCtx, writeSymbols, inputPath, outputDir, layoutPath, blockSize)
+		if err != nil {
+			return nil, err
+		}
+		if sink != nil {
+			if err := sink(0, blockSymbolsDir(outputDir, 0)); err != nil {
+				return nil, err
+			}
+		}
+		trace.finishBlock(blockAttributes(res)...)
+		progress.blockFinished(0, spans[0].size, symbolsWritten(writeSymbols, res))
+		return res, nil
+	}
+
+	baseDir := filepath.Dir(layoutPath)
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return nil, ioError(op, err)
+	}
+
+	stageDir, err := os.MkdirTemp(baseDir, ".rq-staging-")
+	if err != nil {
+		return nil, ioError(op, err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	var created []string
+	committed := false
+	defer func() {
+		if !committed {
+			removePaths(created)
+		}
+	}()
+
+	result := &ProcessResult{LayoutFilePath: layoutPath}
+	if writeSymbols {
+		result.SymbolsDirectory = outputDir
+	}
+	doc := &Layout{}
+
+	for _, span := range spans {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		blockCtx := trace.startBlock(ctx, span.id, span.size)
+
+		blockInput := filepath.Join(stageDir, "block.bin")
+		if err := stageSpan(src, span, blockInput); err != nil {
+			return nil, ioError(op, err)
+		}
+
+		blockOutput := filepath.Join(stageDir, "out")
+		if err := os.MkdirAll(blockOutput, 0755); err != nil {
+			return nil, ioError(op, err)
+		}
+
+		blockLayout := filepath.Join(blockOutput, layoutFileName)
+		progress.blockStarted(span.id)
+		res, err := p.runBlock(blockCtx, writeSymbols, blockInput, blockOutput, blockLayout, int(span.size))
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", span.id, err)
+		}
+
+		blockDoc, err := LoadLayout(blockLayout)
+		if err != nil {
+			return nil, ioError(op, err)
+		}
+		if len(blockDoc.Blocks) != 1 || len(res.Blocks) != 1 {
+			return nil, fmt.Errorf("%s: block %d: %w: expected a single block, got %d",
+				op, span.id, ErrInvalidResponse, len(blockDoc.Blocks))
+		}
+
+		if writeSymbols {
+			moved, err := moveBlockSymbols(
+				filepath.Join(blockOutput, blockDirName(0)),
+				filepath.Join(outputDir, blockDirName(span.id)),
+			)
+			created = append(created, moved...)
+			if err != nil {
+				return nil, ioError(op, err)
+			}
+		}
+
+		layoutEntry := blockDoc.Blocks[0]
+		layoutEntry.BlockID = span.id
+		layoutEntry.OriginalOffset = span.offset
+		doc.Blocks = append(doc.Blocks, layoutEntry)
+
+		block := res.Blocks[0]
+		block.BlockID = span.id
+		block.OriginalOffset = span.offset
+		result.Blocks = append(result.Blocks, block)
+		result.TotalSymbolsCount += res.TotalSymbolsCount
+		result.TotalRepairSymbols += res.TotalRepairSymbols
+
+		if err := os.RemoveAll(blockOutput); err != nil {
+			return nil, ioError(op, err)
+		}
+		if sink != nil {
+			if err := sink(span.id, filepath.Join(outputDir, blockDirName(span.id))); err != nil {
+				return nil, fmt.Errorf("block %d: %w", span.id, err)
+			}
+		}
+		trace.finishBlock(blockAttributes(res)...)
+		progress.blockFinished(span.id, span.size, symbolsWritten(writeSymbols, res))
+	}
+
+	if err := doc.Save(layoutPath); err != nil {
+		return nil, ioError(op, err)
+	}
+	committed = true
+
+	return result, nil
+}
+
+// symbolsWritten returns the number of symbol files written for res, which is
+// zero when only metadata is created.
+func symbolsWritten(writeSymbols bool, res *ProcessResult) uint32 {
+	if !writeSymbols {
+		return 0
+	}
+	return res.TotalSymbolsCount
+}
+
+// runBlock performs a single native EncodeFile or CreateMetadata call.
+func (p *RaptorQProcessor) runBlock(ctx context.Context, writeSymbols bool, inputPath, outputDir, layoutPath string, blockSize int) (*ProcessResult, error) {
+	if writeSymbols {
+		return p.encodeFile(ctx, inputPath, outputDir, blockSize)
+	}
+	return p.createMetadata(ctx, inputPath, layoutPath, blockSize)
+}
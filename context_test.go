@@ -0,0 +1,230 @@
+package rq_go
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Unit test for splitting inputs into blocks
+func TestSplitBlocks(t *testing.T) {
+	spans := splitBlocks(25, 10)
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 blocks, got %d", len(spans))
+	}
+	expected := []blockSpan{{0, 0, 10}, {1, 10, 10}, {2, 20, 5}}
+	for i, span := range spans {
+		if span != expected[i] {
+			t.Fatalf("Block %d: expected %+v, got %+v", i, expected[i], span)
+		}
+	}
+
+	if spans := splitBlocks(25, 0); len(spans) != 1 || spans[0].size != 25 {
+		t.Fatalf("Block size 0 should produce a single block, got %+v", spans)
+	}
+	if spans := splitBlocks(10, 10); len(spans) != 1 {
+		t.Fatalf("Exact fit should produce a single block, got %+v", spans)
+	}
+}
+
+// System test for block-wise encoding/decoding with a context (5MB, 1MB blocks)
+func TestSysEncodeDecodeContext(t *testing.T) {
+	processor, err := NewDefaultRaptorQProcessor()
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	ctx := NewTestContext(t, 5*1024*1024)
+	defer ctx.Cleanup()
+
+	blockSize := 1024 * 1024
+	res, err := processor.EncodeFileContext(context.Background(), ctx.InputFile, ctx.SymbolsDir, blockSize)
+	if err != nil {
+		t.Fatalf("Failed to encode file: %v", err)
+	}
+	if len(res.Blocks) != 5 {
+		t.Fatalf("Expected 5 blocks, got %d", len(res.Blocks))
+	}
+	for i, block := range res.Blocks {
+		if block.BlockID != uint64(i) || block.OriginalOffset != uint64(i*blockSize) {
+			t.Fatalf("Unexpected block %d metadata: %+v", i, block)
+		}
+	}
+
+	err = processor.DecodeSymbolsContext(context.Background(), ctx.SymbolsDir, ctx.OutputFile, res.LayoutFilePath)
+	if err != nil {
+		t.Fatalf("Failed to decode symbols: %v", err)
+	}
+	if !ctx.VerifyFilesMatch(t) {
+		t.Fatal("Decoded file does not match original")
+	}
+}
+
+// System test for cancelled encoding and decoding
+func TestSysContextCancelled(t *testing.T) {
+	processor, err := NewDefaultRaptorQProcessor()
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	ctx := NewTestContext(t, 3*1024*1024)
+	defer ctx.Cleanup()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = processor.EncodeFileContext(cancelled, ctx.InputFile, ctx.SymbolsDir, 1024*1024)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	entries, err := os.ReadDir(ctx.SymbolsDir)
+	if err != nil {
+		t.Fatalf("Failed to read symbols directory: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("Cancelled encode should leave no output, found %d entries", len(entries))
+	}
+
+	layoutPath := filepath.Join(ctx.TempDir, "layout.json")
+	_, err = processor.CreateMetadataContext(cancelled, ctx.InputFile, layoutPath, 1024*1024)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(layoutPath); !os.IsNotExist(err) {
+		t.Fatal("Cancelled metadata creation should not write the layout file")
+	}
+
+	err = processor.DecodeSymbolsContext(cancelled, ctx.SymbolsDir, ctx.OutputFile, layoutPath)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(ctx.OutputFile); !os.IsNotExist(err) {
+		t.Fatal("Cancelled decode should not write the output file")
+	}
+}
+
+// cancelBackend cancels a context once a number of EncodeFile and
+// DecodeSymbols calls have returned
+type cancelBackend struct {
+	Backend
+	calls  int
+	after  int
+	cancel context.CancelFunc
+}
+
+func (b *cancelBackend) returned() {
+	b.calls++
+	if b.calls == b.after {
+		b.cancel()
+	}
+}
+
+func (b *cancelBackend) EncodeFile(sessionID uintptr, inputPath, outputDir string, blockSize int) (*ProcessResult, ErrorCode) {
+	defer b.returned()
+	return b.Backend.EncodeFile(sessionID, inputPath, outputDir, blockSize)
+}
+
+func (b *cancelBackend) DecodeSymbols(sessionID uintptr, symbolsDir, outputPath, layoutPath string) ErrorCode {
+	defer b.returned()
+	return b.Backend.DecodeSymbols(sessionID, symbolsDir, outputPath, layoutPath)
+}
+
+// Unit test for cancelling encoding and decoding between blocks
+func TestContextCancelledBetweenBlocks(t *testing.T) {
+	backend := &cancelBackend{Backend: NewPureGoBackend()}
+	cfg := DefaultProcessorConfig()
+	cfg.RedundancyFactor = 0
+	processor, err := newProcessor(backend, cfg)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	ctx := NewTestContext(t, 300*1024)
+	defer ctx.Cleanup()
+	blockSize := 100 * 1024
+	keep := filepath.Join(ctx.SymbolsDir, "keep.txt")
+	if err := os.WriteFile(keep, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cancelled after two of three blocks: the symbol directories of both
+	// blocks and the staging directory are removed, other files are kept
+	cancelled, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	backend.after, backend.cancel = 2, cancel
+	_, err = processor.EncodeFileContext(cancelled, ctx.InputFile, ctx.SymbolsDir, blockSize)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got: %v", err)
+	}
+	if backend.calls != 2 {
+		t.Fatalf("Expected the encoding to stop after 2 blocks, got %d", backend.calls)
+	}
+	if staged := stagingDirs(t, ctx.TempDir); len(staged) != 0 {
+		t.Fatalf("Cancelled encode should remove its staging directory, found %v", staged)
+	}
+	if _, err := os.Stat(filepath.Join(ctx.SymbolsDir, layoutFileName)); !os.IsNotExist(err) {
+		t.Fatal("Cancelled encode should not write the layout file")
+	}
+	entries, err := os.ReadDir(ctx.SymbolsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "keep.txt" {
+		t.Fatalf("Cancelled encode should only leave keep.txt, found %d entries", len(entries))
+	}
+
+	res, err := processor.EncodeFileContext(context.Background(), ctx.InputFile, ctx.SymbolsDir, blockSize)
+	if err != nil {
+		t.Fatalf("Failed to encode file: %v", err)
+	}
+
+	// Cancelled after the first block: neither the output file nor the staged
+	// output is left behind
+	cancelled, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	backend.calls, backend.after, backend.cancel = 0, 1, cancel
+	err = processor.DecodeSymbolsContext(cancelled, ctx.SymbolsDir, ctx.OutputFile, res.LayoutFilePath)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got: %v", err)
+	}
+	if backend.calls != 1 {
+		t.Fatalf("Expected the decoding to stop after 1 block, got %d", backend.calls)
+	}
+	if _, err := os.Stat(ctx.OutputFile); !os.IsNotExist(err) {
+		t.Fatal("Cancelled decode should not write the output file")
+	}
+	if staged := stagingDirs(t, ctx.TempDir); len(staged) != 0 {
+		t.Fatalf("Cancelled decode should remove the staged output, found %v", staged)
+	}
+	entries, err = os.ReadDir(filepath.Dir(ctx.OutputFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if name := entry.Name(); name != filepath.Base(ctx.InputFile) && name != filepath.Base(ctx.SymbolsDir) {
+			t.Fatalf("Cancelled decode should not leave partial output, found %s", name)
+		}
+	}
+}
+
+// stagingDirs returns the .rq-staging-* directories below root
+func stagingDirs(t *testing.T, root string) []string {
+	t.Helper()
+	var staged []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && d.IsDir() && strings.HasPrefix(d.Name(), ".rq-staging-") {
+			staged = append(staged, path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return staged
+}
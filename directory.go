@@ -0,0 +1,629 @@
+package rq_go
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"lukechampine.com/blake3"
+)
+
+// EntryType is the kind of a FileEntry.
+type EntryType string
+
+const (
+	// EntryFile is a regular file.
+	EntryFile EntryType = "file"
+
+	// EntryDir is a directory.
+	EntryDir EntryType = "dir"
+
+	// EntrySymlink is a symbolic link.
+	EntrySymlink EntryType = "symlink"
+)
+
+// FileEntry describes a file, directory or symbolic link of a directory tree
+// encoded with EncodeDirectory.
+type FileEntry struct {
+	// Path is the slash-separated path of the entry relative to the root of
+	// the tree, e.g. "data/part-0001.csv".
+	Path string `json:"path"`
+
+	// Type is the kind of the entry.
+	Type EntryType `json:"type"`
+
+	// Mode holds the permission bits of the entry.
+	Mode fs.FileMode `json:"mode"`
+
+	// ModTime is the modification time of the entry, in UTC.
+	ModTime time.Time `json:"mtime"`
+
+	// Offset is the offset of the content of a regular file in the container.
+	Offset uint64 `json:"offset,omitempty"`
+
+	// Size is the size of the content of a regular file.
+	Size uint64 `json:"size,omitempty"`
+
+	// Hash is the base58-encoded BLAKE3 hash of the content of a regular file.
+	Hash string `json:"hash,omitempty"`
+
+	// LinkTarget is the target of a symbolic link, as stored in the link.
+	LinkTarget string `json:"link_target,omitempty"`
+}
+
+// DirectoryLayout is the layout written by EncodeDirectory. It extends the
+// Layout of the encoded container with an index of the files of the tree, so
+// the layout file is read by LoadLayout and every function taking a layout
+// like any other.
+type DirectoryLayout struct {
+	Layout
+
+	// Files lists the entries of the tree in the order of the container:
+	// sorted by path, every directory before its content.
+	Files []FileEntry `json:"files"`
+}
+
+// LoadDirectoryLayout reads and parses a layout file written by
+// EncodeDirectory.
+//
+// Returns:
+//   - *DirectoryLayout: The parsed layout.
+//   - error: The error reading the file, or an error matching ErrInvalidLayout
+//     if it is not well-formed JSON or has no file index.
+func LoadDirectoryLayout(path string) (*DirectoryLayout, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var layout DirectoryLayout
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", path, ErrInvalidLayout, err)
+	}
+	if layout.Files == nil {
+		return nil, fmt.Errorf("%s: %w: layout has no file index, it was not written by EncodeDirectory", path, ErrInvalidLayout)
+	}
+	return &layout, nil
+}
+
+// Marshal returns the layout, including the file index, as indented JSON.
+func (l *DirectoryLayout) Marshal() ([]byte, error) {
+	return json.MarshalIndent(l, "", "  ")
+}
+
+// Save writes the layout, including the file index, to path. The file is
+// replaced atomically.
+func (l *DirectoryLayout) Save(path string) error {
+	data, err := l.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to serialize layout: %w", err)
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// File returns the entry with the given slash-separated path.
+func (l *DirectoryLayout) File(name string) (*FileEntry, bool) {
+	for i := range l.Files {
+		if l.Files[i].Path == name {
+			return &l.Files[i], true
+		}
+	}
+	return nil, false
+}
+
+// Validate checks the blocks like Layout.Validate and the file index: every
+// path must be a valid relative path listed once, below a directory listed
+// before it, and the content of every regular file must lie within the
+// container.
+//
+// Returns:
+//   - error: nil if the layout is consistent, otherwise an error matching
+//     ErrInvalidLayout.
+func (l *DirectoryLayout) Validate() error {
+	if err := l.Layout.Validate(); err != nil {
+		return err
+	}
+
+	total := l.TotalSize()
+	types := make(map[string]EntryType, len(l.Files))
+	for _, entry := range l.Files {
+		if !fs.ValidPath(entry.Path) || entry.Path == "." {
+			return fmt.Errorf("%w: file %q: invalid path", ErrInvalidLayout, entry.Path)
+		}
+		if _, ok := types[entry.Path]; ok {
+			return fmt.Errorf("%w: file %q: listed twice", ErrInvalidLayout, entry.Path)
+		}
+		if dir := path.Dir(entry.Path); dir != "." && types[dir] != EntryDir {
+			return fmt.Errorf("%w: file %q: parent directory is not listed before it", ErrInvalidLayout, entry.Path)
+		}
+		switch entry.Type {
+		case EntryFile:
+			if entry.Offset > total || entry.Size > total-entry.Offset {
+				return fmt.Errorf("%w: file %q: content [%d, %d) is outside the %d bytes of the container",
+					ErrInvalidLayout, entry.Path, entry.Offset, entry.Offset+entry.Size, total)
+			}
+		case EntryDir:
+		case EntrySymlink:
+			if entry.LinkTarget == "" {
+				return fmt.Errorf("%w: file %q: symbolic link has no target", ErrInvalidLayout, entry.Path)
+			}
+		default:
+			return fmt.Errorf("%w: file %q: unknown type %q", ErrInvalidLayout, entry.Path, entry.Type)
+		}
+		types[entry.Path] = entry.Type
+	}
+	return nil
+}
+
+// DirectoryOptions are the options of EncodeDirectory.
+type DirectoryOptions struct {
+	// BlockSize is the size of each block of the container in bytes. If 0, a
+	// recommended block size is used.
+	BlockSize int
+}
+
+// EncodeDirectory encodes a directory tree into a single RaptorQ object.
+//
+// The tree is serialized into a tar container holding the path, permission
+// bits and modification time of every regular file, directory and symbolic
+// link below root. Entries are written in lexical order without owners or
+// access times, so the same tree always produces the same container and
+// therefore the same symbols. Symbolic links are stored, not followed; other
+// file types are rejected.
+//
+// The container is staged in outputDir and encoded like EncodeFile. The
+// layout file written into outputDir is a DirectoryLayout: the layout of the
+// container extended with the index of the files, which DecodeDirectory and
+// ExtractFile use to restore them.
+//
+// Parameters:
+//   - root: The directory to encode. Its own metadata is not recorded.
+//   - outputDir: Directory where the encoded symbols will be written. It cannot
+//     be inside root.
+//   - opts: Options of the encoding.
+//
+// Returns:
+//   - *ProcessResult: Information about the encoding of the container, as
+//     returned by EncodeFile.
+//   - error: An error matching ErrInvalidParameters if root is not a directory,
+//     contains outputDir or an unsupported file, ErrIO for failures reading the
+//     tree, or the errors of EncodeFile.
+//
+// Example:
+//
+//	result, err := processor.EncodeDirectory("dataset/", "symbols/", raptorq.DirectoryOptions{})
+//	if err != nil {
+//	    return fmt.Errorf("encoding failed: %w", err)
+//	}
+//	layout, _ := raptorq.LoadDirectoryLayout(result.LayoutFilePath)
+//	fmt.Printf("Encoded %d entries into %d symbols\n", len(layout.Files), result.TotalSymbolsCount)
+func (p *RaptorQProcessor) EncodeDirectory(root, outputDir string, opts DirectoryOptions) (*ProcessResult, error) {
+	return p.EncodeDirectoryContext(context.Background(), root, outputDir, opts)
+}
+
+// EncodeDirectoryContext encodes a directory tree like EncodeDirectory,
+// honouring cancellation and deadlines of ctx. ctx is checked before every
+// entry of the tree and before every block of the container.
+func (p *RaptorQProcessor) EncodeDirectoryContext(ctx context.Context, root, outputDir string, opts DirectoryOptions) (*ProcessResult, error) {
+	if p.SessionID == 0 {
+		return nil, ErrSessionClosed
+	}
+	if root == "" || outputDir == "" {
+		return nil, fmt.Errorf("%w: root and outputDir cannot be empty", ErrInvalidParameters)
+	}
+	if info, err := os.Stat(root); err != nil {
+		return nil, ioError("EncodeFile", err)
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("%w: %s is not a directory", ErrInvalidParameters, root)
+	}
+	if inside, err := isWithin(root, outputDir); err != nil {
+		return nil, ioError("EncodeFile", err)
+	} else if inside {
+		return nil, fmt.Errorf("%w: outputDir %s is inside root %s", ErrInvalidParameters, outputDir, root)
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, ioError("EncodeFile", err)
+	}
+	stageDir, err := os.MkdirTemp(outputDir, ".rq-staging-")
+	if err != nil {
+		return nil, ioError("EncodeFile", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	containerPath := filepath.Join(stageDir, "container.tar")
+	container, err := os.Create(containerPath)
+	if err != nil {
+		return nil, ioError("EncodeFile", err)
+	}
+	files, err := writeContainer(ctx, root, container)
+	if closeErr := container.Close(); err == nil && closeErr != nil {
+		err = ioError("EncodeFile", closeErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := p.EncodeFileContext(ctx, containerPath, outputDir, opts.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := LoadLayout(result.LayoutFilePath)
+	if err != nil {
+		return nil, ioError("EncodeFile", err)
+	}
+	layout := &DirectoryLayout{Layout: *doc, Files: files}
+	if err := layout.Save(result.LayoutFilePath); err != nil {
+		return nil, ioError("EncodeFile", err)
+	}
+	return result, nil
+}
+
+// DecodeDirectory restores a directory tree encoded with EncodeDirectory.
+//
+// The whole container is decoded, then every entry of the file index is
+// created below destDir with its permission bits and modification time. The
+// content of every regular file is checked against its hash. destDir is
+// created if needed, but none of the entries may exist yet. Modification
+// times of symbolic links are not restored.
+//
+// To restore a single file, use ExtractFile, which only decodes the blocks the
+// file spans.
+//
+// Parameters:
+//   - symbolsDir: Directory containing the encoded symbols.
+//   - layoutPath: Path to the layout file written by EncodeDirectory.
+//   - destDir: Directory the tree is restored into.
+//
+// Returns:
+//   - error: An error matching ErrInvalidLayout if the layout has no valid file
+//     index, ErrHashMismatch if a restored file does not match its hash, ErrIO
+//     for failures writing the tree, or the errors of DecodeSymbols.
+//
+// Example:
+//
+//	err := processor.DecodeDirectory("symbols/", "symbols/_raptorq_layout.json", "restored/")
+//	if err != nil {
+//	    return fmt.Errorf("restore failed: %w", err)
+//	}
+func (p *RaptorQProcessor) DecodeDirectory(symbolsDir, layoutPath, destDir string) error {
+	return p.DecodeDirectoryContext(context.Background(), symbolsDir, layoutPath, destDir)
+}
+
+// DecodeDirectoryContext restores a directory tree like DecodeDirectory,
+// honouring cancellation and deadlines of ctx. ctx is checked before every
+// block of the container and every entry of the tree; entries restored before
+// ctx ended are left in place.
+func (p *RaptorQProcessor) DecodeDirectoryContext(ctx context.Context, symbolsDir, layoutPath, destDir string) error {
+	if p.SessionID == 0 {
+		return ErrSessionClosed
+	}
+	if symbolsDir == "" || layoutPath == "" || destDir == "" {
+		return fmt.Errorf("%w: symbolsDir, layoutPath, and destDir cannot be empty", ErrInvalidParameters)
+	}
+
+	layout, err := loadDirectoryLayout(layoutPath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	stageDir, err := os.MkdirTemp(filepath.Dir(filepath.Clean(destDir)), ".rq-staging-")
+	if err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	// The native decoder is given the plain layout of the container.
+	containerLayout := filepath.Join(stageDir, layoutFileName)
+	if err := layout.Layout.Save(containerLayout); err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	containerPath := filepath.Join(stageDir, "container.tar")
+	if err := p.DecodeSymbolsContext(ctx, symbolsDir, containerPath, containerLayout); err != nil {
+		return err
+	}
+
+	container, err := os.Open(containerPath)
+	if err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	defer container.Close()
+	return restoreEntries(ctx, container, layout.Files, destDir)
+}
+
+// ExtractFile decodes a single regular file of a tree encoded with
+// EncodeDirectory and writes its content to w. Only the blocks of the
+// container the file spans are decoded, as by DecodeRange.
+//
+// Parameters:
+//   - symbolsDir: Directory containing the encoded symbols.
+//   - layoutPath: Path to the layout file written by EncodeDirectory.
+//   - name: The slash-separated path of the file relative to the root of the tree.
+//   - w: Destination of the content of the file.
+//
+// Returns:
+//   - int64: The number of bytes written to w.
+//   - error: An error matching ErrFileNotFound if the tree has no regular file
+//     named name, ErrHashMismatch if the decoded content does not match its
+//     hash (in which case it has already been written to w), ErrInvalidLayout
+//     if the layout has no valid file index, or the errors of DecodeRange.
+//
+// Example:
+//
+//	out, err := os.Create("part-0001.csv")
+//	if err != nil {
+//	    return err
+//	}
+//	defer out.Close()
+//	_, err = processor.ExtractFile("symbols/", "symbols/_raptorq_layout.json", "data/part-0001.csv", out)
+func (p *RaptorQProcessor) ExtractFile(symbolsDir, layoutPath, name string, w io.Writer) (int64, error) {
+	if p.SessionID == 0 {
+		return 0, ErrSessionClosed
+	}
+	layout, err := loadDirectoryLayout(layoutPath)
+	if err != nil {
+		return 0, err
+	}
+	entry, ok := layout.File(name)
+	if !ok || entry.Type != EntryFile {
+		return 0, fmt.Errorf("%w: %s is not a regular file of the tree", ErrFileNotFound, name)
+	}
+	if entry.Size == 0 {
+		return 0, checkContentHash(entry, hashBytes(nil))
+	}
+
+	h := blake3.New(HashSize, nil)
+	n, err := p.DecodeRange(symbolsDir, layoutPath, entry.Offset, entry.Size, io.MultiWriter(w, h))
+	if err != nil {
+		return n, err
+	}
+	return n, checkContentHash(entry, base58Encode(h.Sum(nil)))
+}
+
+// loadDirectoryLayout loads and validates a layout written by EncodeDirectory.
+func loadDirectoryLayout(layoutPath string) (*DirectoryLayout, error) {
+	layout, err := LoadDirectoryLayout(layoutPath)
+	if err != nil {
+		if errors.Is(err, ErrInvalidLayout) {
+			return nil, err
+		}
+		return nil, ioError("DecodeSymbols", err)
+	}
+	if err := layout.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", layoutPath, err)
+	}
+	return layout, nil
+}
+
+// saveLayout writes layout to path like Layout.Save, keeping the file index
+// of a DirectoryLayout stored at path, so that functions updating the blocks of
+// a layout also work on the layouts of EncodeDirectory.
+func saveLayout(layout *Layout, path string) error {
+	if dirLayout, err := LoadDirectoryLayout(path); err == nil {
+		dirLayout.Layout = *layout
+		return dirLayout.Save(path)
+	}
+	return layout.Save(path)
+}
+
+// checkContentHash compares the hash of the decoded content of a file with
+// the hash of its entry.
+func checkContentHash(entry *FileEntry, hash string) error {
+	if hash != entry.Hash {
+		return fmt.Errorf("%w: %s has hash %s, layout lists %s", ErrHashMismatch, entry.Path, hash, entry.Hash)
+	}
+	return nil
+}
+
+// isWithin reports whether path is root or a path below it.
+func isWithin(root, path string) (bool, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return false, err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	rel, err := filepath.Rel(absRoot, absPath)
+	if err != nil {
+		return false, nil
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)), nil
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n uint64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += uint64(n)
+	return n, err
+}
+
+// writeContainer writes the tar container of the tree below root to w and
+// returns the index of its entries.
+func writeContainer(ctx context.Context, root string, w io.Writer) ([]FileEntry, error) {
+	counter := &countingWriter{w: w}
+	tw := tar.NewWriter(counter)
+	files := []FileEntry{}
+
+	err := filepath.WalkDir(root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return ioError("EncodeFile", err)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return ioError("EncodeFile", err)
+		}
+		if rel == "." {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return ioError("EncodeFile", err)
+		}
+
+		entry := FileEntry{
+			Path:    filepath.ToSlash(rel),
+			Mode:    info.Mode().Perm(),
+			ModTime: info.ModTime().UTC(),
+		}
+		hdr := &tar.Header{
+			Name:    entry.Path,
+			Mode:    int64(entry.Mode),
+			ModTime: entry.ModTime,
+			Format:  tar.FormatPAX,
+		}
+		switch {
+		case d.IsDir():
+			entry.Type = EntryDir
+			hdr.Typeflag = tar.TypeDir
+			hdr.Name += "/"
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(filePath)
+			if err != nil {
+				return ioError("EncodeFile", err)
+			}
+			entry.Type = EntrySymlink
+			entry.LinkTarget = target
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = target
+		case d.Type().IsRegular():
+			entry.Type = EntryFile
+			entry.Size = uint64(info.Size())
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = info.Size()
+		default:
+			return fmt.Errorf("%w: %s: unsupported file type %s", ErrInvalidParameters, filePath, d.Type())
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return ioError("EncodeFile", err)
+		}
+		if entry.Type == EntryFile {
+			entry.Offset = counter.n
+			hash, err := copyFileContent(tw, filePath, hdr.Size)
+			if err != nil {
+				return ioError("EncodeFile", err)
+			}
+			entry.Hash = hash
+		}
+		files = append(files, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, ioError("EncodeFile", err)
+	}
+	return files, nil
+}
+
+// copyFileContent copies size bytes of the file at path to w and returns
+// their hash. It fails if the file is shorter than size.
+func copyFileContent(w io.Writer, path string, size int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := blake3.New(HashSize, nil)
+	if _, err := io.CopyN(io.MultiWriter(w, h), f, size); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = fmt.Errorf("%s: file shrank while it was archived", path)
+		}
+		return "", err
+	}
+	return base58Encode(h.Sum(nil)), nil
+}
+
+// restoreEntries creates the entries of files below destDir, reading the
+// content of regular files from the decoded container.
+func restoreEntries(ctx context.Context, container io.ReaderAt, files []FileEntry, destDir string) error {
+	var dirs []FileEntry
+	for i := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry := &files[i]
+		target := filepath.Join(destDir, filepath.FromSlash(entry.Path))
+
+		switch entry.Type {
+		case EntryDir:
+			if err := os.Mkdir(target, 0700); err != nil {
+				return ioError("DecodeSymbols", err)
+			}
+			dirs = append(dirs, *entry)
+		case EntrySymlink:
+			if err := os.Symlink(entry.LinkTarget, target); err != nil {
+				return ioError("DecodeSymbols", err)
+			}
+		case EntryFile:
+			if err := restoreFile(container, entry, target); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Directories get their metadata last, as creating their content changes
+	// their modification time and may need write permission.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		target := filepath.Join(destDir, filepath.FromSlash(dirs[i].Path))
+		if err := os.Chmod(target, dirs[i].Mode); err != nil {
+			return ioError("DecodeSymbols", err)
+		}
+		if err := os.Chtimes(target, dirs[i].ModTime, dirs[i].ModTime); err != nil {
+			return ioError("DecodeSymbols", err)
+		}
+	}
+	return nil
+}
+
+// restoreFile writes the content of a regular file from the container to
+// target and applies its metadata.
+func restoreFile(container io.ReaderAt, entry *FileEntry, target string) error {
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	defer f.Close()
+
+	h := blake3.New(HashSize, nil)
+	content := io.NewSectionReader(container, int64(entry.Offset), int64(entry.Size))
+	if _, err := io.Copy(io.MultiWriter(f, h), content); err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	if err := checkContentHash(entry, base58Encode(h.Sum(nil))); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	if err := os.Chmod(target, entry.Mode); err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	if err := os.Chtimes(target, entry.ModTime, entry.ModTime); err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	return nil
+}
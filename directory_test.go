@@ -0,0 +1,257 @@
+package rq_go
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestTree creates a tree with nested directories, files of the given
+// sizes, an empty file and a symbolic link below root
+func writeTestTree(t *testing.T, root string, sizes ...int) {
+	t.Helper()
+	rng := rand.New(rand.NewSource(int64(len(sizes))))
+	mtime := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	if err := os.MkdirAll(filepath.Join(root, "data", "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for i, size := range sizes {
+		data := make([]byte, size)
+		rng.Read(data)
+		name := filepath.Join(root, "data", string(rune('a'+i))+".bin")
+		if err := os.WriteFile(name, data, 0640); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(name, mtime, mtime.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "data", "nested", "empty"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("data/a.bin", filepath.Join(root, "link")); err != nil {
+		t.Skipf("Symbolic links are not supported: %v", err)
+	}
+	if err := os.Chtimes(filepath.Join(root, "data", "nested"), mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Unit test for the deterministic container and its index
+func TestWriteContainer(t *testing.T) {
+	root := t.TempDir()
+	writeTestTree(t, root, 1000, 3000)
+
+	var first, second bytes.Buffer
+	files, err := writeContainer(context.Background(), root, &first)
+	if err != nil {
+		t.Fatalf("Failed to write the container: %v", err)
+	}
+	if _, err := writeContainer(context.Background(), root, &second); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Error("Expected the same container for the same tree")
+	}
+
+	paths := []string{"data", "data/a.bin", "data/b.bin", "data/nested", "data/nested/empty", "link"}
+	if len(files) != len(paths) {
+		t.Fatalf("Expected %d entries, got %+v", len(paths), files)
+	}
+	for i, entry := range files {
+		if entry.Path != paths[i] {
+			t.Errorf("Entry %d: expected %s, got %s", i, paths[i], entry.Path)
+		}
+	}
+
+	a := files[1]
+	content, err := os.ReadFile(filepath.Join(root, "data", "a.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Type != EntryFile || a.Mode != 0640 || a.Size != 1000 || a.Hash != ContentHash(content) ||
+		!bytes.Equal(first.Bytes()[a.Offset:a.Offset+a.Size], content) {
+		t.Errorf("Unexpected entry of a.bin: %+v", a)
+	}
+	if link := files[5]; link.Type != EntrySymlink || link.LinkTarget != "data/a.bin" {
+		t.Errorf("Unexpected entry of the link: %+v", link)
+	}
+	if dir := files[3]; dir.Type != EntryDir || !dir.ModTime.Equal(time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)) {
+		t.Errorf("Unexpected entry of the nested directory: %+v", dir)
+	}
+
+	// The container is a regular tar archive
+	tr := tar.NewReader(&first)
+	for i := 0; ; i++ {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read the container: %v", err)
+		}
+		if hdr.ModTime.Sub(files[i].ModTime).Abs() > time.Microsecond {
+			t.Errorf("%s: expected modification time %v, got %v", hdr.Name, files[i].ModTime, hdr.ModTime)
+		}
+	}
+}
+
+// Unit test for validating the file index
+func TestDirectoryLayoutValidate(t *testing.T) {
+	base := *testLayout()
+	tests := []struct {
+		name  string
+		files []FileEntry
+		valid bool
+	}{
+		{"valid", []FileEntry{{Path: "d", Type: EntryDir}, {Path: "d/f", Type: EntryFile, Offset: 512, Size: 488}}, true},
+		{"absolute", []FileEntry{{Path: "/etc/passwd", Type: EntryFile}}, false},
+		{"parent", []FileEntry{{Path: "../f", Type: EntryFile}}, false},
+		{"unlisted parent", []FileEntry{{Path: "d/f", Type: EntryFile}}, false},
+		{"file as parent", []FileEntry{{Path: "d", Type: EntrySymlink, LinkTarget: "/tmp"}, {Path: "d/f", Type: EntryFile}}, false},
+		{"duplicate", []FileEntry{{Path: "f", Type: EntryFile}, {Path: "f", Type: EntryFile}}, false},
+		{"outside", []FileEntry{{Path: "f", Type: EntryFile, Offset: 1400, Size: 200}}, false},
+		{"unknown type", []FileEntry{{Path: "f", Type: "fifo"}}, false},
+	}
+	for _, tt := range tests {
+		layout := &DirectoryLayout{Layout: base, Files: tt.files}
+		err := layout.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidLayout) {
+			t.Errorf("%s: expected ErrInvalidLayout, got %v", tt.name, err)
+		}
+	}
+}
+
+// Unit test for keeping the file index when the blocks of a layout are saved
+func TestSaveLayoutKeepsIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "layout.json")
+	layout := testLayout()
+	files := []FileEntry{{Path: "f", Type: EntryFile, Size: 10, Hash: testHash(9)}}
+	if err := (&DirectoryLayout{Layout: *layout, Files: files}).Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	layout.Blocks[1].Symbols = append(layout.Blocks[1].Symbols, testHash(6))
+	if err := saveLayout(layout, path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadDirectoryLayout(path)
+	if err != nil {
+		t.Fatalf("Lost the file index: %v", err)
+	}
+	if len(got.Files) != 1 || got.Files[0] != files[0] || len(got.Blocks[1].Symbols) != 2 {
+		t.Errorf("Unexpected layout: %+v", got)
+	}
+
+	plain := filepath.Join(t.TempDir(), "plain.json")
+	if err := saveLayout(layout, plain); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDirectoryLayout(plain); !errors.Is(err, ErrInvalidLayout) {
+		t.Errorf("Expected a plain layout, got %v", err)
+	}
+}
+
+// System test encoding, restoring and extracting a directory tree (1MB blocks)
+func TestSysEncodeDirectory(t *testing.T) {
+	processor, err := NewDefaultRaptorQProcessor()
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	dir := t.TempDir()
+	root := filepath.Join(dir, "tree")
+	writeTestTree(t, root, 100*1024, 2*1024*1024, 50*1024)
+	symbolsDir := filepath.Join(dir, "symbols")
+
+	if _, err := processor.EncodeDirectory(root, filepath.Join(root, "symbols"), DirectoryOptions{}); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("Expected ErrInvalidParameters for an output directory inside the tree, got %v", err)
+	}
+
+	res, err := processor.EncodeDirectory(root, symbolsDir, DirectoryOptions{BlockSize: 1024 * 1024})
+	if err != nil {
+		t.Fatalf("Failed to encode the directory: %v", err)
+	}
+	layout, err := LoadDirectoryLayout(res.LayoutFilePath)
+	if err != nil {
+		t.Fatalf("Failed to load the layout: %v", err)
+	}
+	if len(layout.Blocks) != 3 || len(layout.Files) != 7 {
+		t.Fatalf("Unexpected layout: %d blocks, %d files", len(layout.Blocks), len(layout.Files))
+	}
+	if report, err := VerifySymbols(symbolsDir, &layout.Layout); err != nil || !report.Healthy() {
+		t.Fatalf("Expected healthy symbols: %v", err)
+	}
+
+	dest := filepath.Join(dir, "restored")
+	if err := processor.DecodeDirectory(symbolsDir, res.LayoutFilePath, dest); err != nil {
+		t.Fatalf("Failed to decode the directory: %v", err)
+	}
+	for _, entry := range layout.Files {
+		want, err := os.Lstat(filepath.Join(root, filepath.FromSlash(entry.Path)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.Lstat(filepath.Join(dest, filepath.FromSlash(entry.Path)))
+		if err != nil {
+			t.Fatalf("%s was not restored: %v", entry.Path, err)
+		}
+		if got.Mode() != want.Mode() {
+			t.Errorf("%s: expected mode %v, got %v", entry.Path, want.Mode(), got.Mode())
+		}
+		if entry.Type != EntrySymlink && !got.ModTime().Equal(want.ModTime()) {
+			t.Errorf("%s: expected modification time %v, got %v", entry.Path, want.ModTime(), got.ModTime())
+		}
+	}
+	if target, err := os.Readlink(filepath.Join(dest, "link")); err != nil || target != "data/a.bin" {
+		t.Errorf("Unexpected link target %q: %v", target, err)
+	}
+	if err := processor.DecodeDirectory(symbolsDir, res.LayoutFilePath, dest); !errors.Is(err, ErrIO) {
+		t.Errorf("Expected an error restoring over existing entries, got %v", err)
+	}
+
+	// a.bin lies in block 0, so it is extracted without the symbols of block 2
+	entry, _ := layout.File("data/a.bin")
+	if entry.Offset+entry.Size > layout.Blocks[0].Size {
+		t.Fatalf("Expected data/a.bin in block 0: %+v", entry)
+	}
+	if err := os.RemoveAll(filepath.Join(symbolsDir, blockDirName(2))); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if n, err := processor.ExtractFile(symbolsDir, res.LayoutFilePath, "data/a.bin", &out); err != nil || n != int64(entry.Size) {
+		t.Fatalf("Failed to extract data/a.bin (%d bytes): %v", n, err)
+	}
+	if want, _ := os.ReadFile(filepath.Join(root, "data", "a.bin")); !bytes.Equal(out.Bytes(), want) {
+		t.Error("Extracted content does not match")
+	}
+	if n, err := processor.ExtractFile(symbolsDir, res.LayoutFilePath, "data/nested/empty", io.Discard); err != nil || n != 0 {
+		t.Errorf("Failed to extract the empty file: %v", err)
+	}
+	if _, err := processor.ExtractFile(symbolsDir, res.LayoutFilePath, "data", io.Discard); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("Expected ErrFileNotFound for a directory, got %v", err)
+	}
+	if err := processor.DecodeDirectory(symbolsDir, res.LayoutFilePath, filepath.Join(dir, "incomplete")); err == nil {
+		t.Error("Expected decoding to fail without the symbols of block 2")
+	}
+
+	// A plain layout has no file index
+	plain := filepath.Join(dir, "plain.json")
+	if err := layout.Layout.Save(plain); err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.DecodeDirectory(symbolsDir, plain, filepath.Join(dir, "plain")); !errors.Is(err, ErrInvalidLayout) {
+		t.Errorf("Expected ErrInvalidLayout for a plain layout, got %v", err)
+	}
+}
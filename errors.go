@@ -0,0 +1,189 @@
+package rq_go
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorCode is a raw return code produced by the native RaptorQ library.
+//
+// The values mirror the ProcessError variants of the Rust implementation and
+// are documented in include/rq-library.h. Codes -1 to -5 are reported by the
+// FFI layer itself, while -11 to -17 come from the processor and carry a
+// detailed message retrievable through the session's last error.
+type ErrorCode int32
+
+// Return codes of the native library.
+const (
+	CodeSuccess           ErrorCode = 0
+	CodeGeneric           ErrorCode = -1
+	CodeInvalidParameters ErrorCode = -2
+	CodeInvalidResponse   ErrorCode = -3
+	CodeBufferTooSmall    ErrorCode = -4
+	CodeInvalidSession    ErrorCode = -5
+	CodeIO                ErrorCode = -11
+	CodeFileNotFound      ErrorCode = -12
+	CodeInvalidPath       ErrorCode = -13
+	CodeEncodingFailed    ErrorCode = -14
+	CodeDecodingFailed    ErrorCode = -15
+	CodeMemoryLimit       ErrorCode = -16
+	CodeConcurrencyLimit  ErrorCode = -17
+)
+
+// Sentinel errors that can be matched with errors.Is against any error returned
+// by a RaptorQProcessor.
+var (
+	// ErrSessionClosed is returned when a method is called on a processor whose
+	// session has already been freed.
+	ErrSessionClosed = errors.New("RaptorQ session is closed")
+
+	ErrGeneric           = errors.New("generic error")
+	ErrInvalidParameters = errors.New("invalid parameters")
+	ErrInvalidResponse   = errors.New("invalid response (JSON serialization error)")
+	ErrBufferTooSmall    = errors.New("result buffer too small")
+	ErrInvalidSession    = errors.New("invalid session")
+	ErrIO                = errors.New("IO error")
+	ErrFileNotFound      = errors.New("file not found")
+	ErrInvalidPath       = errors.New("invalid path")
+	ErrEncodingFailed    = errors.New("encoding failed")
+	ErrDecodingFailed    = errors.New("decoding failed")
+	ErrMemoryLimit       = errors.New("memory limit exceeded")
+	ErrConcurrencyLimit  = errors.New("concurrency limit reached")
+
+	// ErrInsufficientSymbols is reported when a block cannot be reconstructed
+	// because too few of its symbols are available. It is a specialisation of
+	// ErrDecodingFailed, so errors.Is matches both.
+	ErrInsufficientSymbols = fmt.Errorf("%w: insufficient symbols", ErrDecodingFailed)
+
+	// ErrInvalidLayout is returned when a layout file cannot be parsed or fails
+	// validation. Errors reported by Layout.Validate match it through errors.Is.
+	ErrInvalidLayout = errors.New("invalid layout")
+
+	// ErrHashMismatch is returned by VerifyDecodedFile when decoded data does
+	// not match the block hashes recorded in its layout.
+	ErrHashMismatch = errors.New("hash mismatch")
+
+	// ErrNativeLibraryUnavailable is returned when creating a processor on the
+	// default backend of a build without cgo, which cannot link the native
+	// library.
+	ErrNativeLibraryUnavailable = errors.New("native RaptorQ library unavailable: rq-go was built without cgo")
+
+	// ErrSymbolNotFound is returned by SymbolStore.Get for symbols the store
+	// does not hold. It is a specialisation of ErrFileNotFound.
+	ErrSymbolNotFound = fmt.Errorf("%w: symbol not found", ErrFileNotFound)
+)
+
+// errorCodes is the single mapping table from native return codes to sentinel
+// errors shared by every FFI call. The detail flag marks codes for which the
+// native session records a message that can be fetched with getLastError.
+var errorCodes = map[ErrorCode]struct {
+	err    error
+	detail bool
+}{
+	CodeGeneric:           {ErrGeneric, false},
+	CodeInvalidParameters: {ErrInvalidParameters, false},
+	CodeInvalidResponse:   {ErrInvalidResponse, false},
+	CodeBufferTooSmall:    {ErrBufferTooSmall, false},
+	CodeInvalidSession:    {ErrInvalidSession, false},
+	CodeIO:                {ErrIO, true},
+	CodeFileNotFound:      {ErrFileNotFound, true},
+	CodeInvalidPath:       {ErrInvalidPath, true},
+	CodeEncodingFailed:    {ErrEncodingFailed, true},
+	CodeDecodingFailed:    {ErrDecodingFailed, true},
+	CodeMemoryLimit:       {ErrMemoryLimit, true},
+	CodeConcurrencyLimit:  {ErrConcurrencyLimit, true},
+}
+
+// String returns the name of the sentinel error associated with the code.
+func (c ErrorCode) String() string {
+	if c == CodeSuccess {
+		return "success"
+	}
+	if entry, ok := errorCodes[c]; ok {
+		return entry.err.Error()
+	}
+	return fmt.Sprintf("unknown error code %d", int32(c))
+}
+
+// RaptorQError describes a failed call into the native library.
+//
+// It records the operation that failed, the raw return code and, when the
+// native side provides one, the detailed last-error message of the session.
+// RaptorQError unwraps to the sentinel error of its code, so callers can use
+// errors.Is(err, ErrMemoryLimit) instead of matching on error strings, and
+// errors.As to access the raw code.
+type RaptorQError struct {
+	// Op is the name of the processor method that failed, e.g. "EncodeFile".
+	Op string
+
+	// Code is the raw return code reported by the native library.
+	Code ErrorCode
+
+	// Detail is the session's last error message. It is empty for codes that
+	// are reported by the FFI layer without a message.
+	Detail string
+
+	// err is the sentinel error the code maps to, or nil for unknown codes.
+	err error
+}
+
+// Error implements the error interface.
+func (e *RaptorQError) Error() string {
+	msg := e.Code.String()
+	if e.err != nil {
+		msg = e.err.Error()
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.Op == "" {
+		return msg
+	}
+	return e.Op + ": " + msg
+}
+
+// Unwrap returns the sentinel error associated with the return code.
+func (e *RaptorQError) Unwrap() error {
+	return e.err
+}
+
+// newRaptorQError builds the error for a non-zero native return code using the
+// shared errorCodes table. lastError is only invoked for codes that carry a
+// detailed message.
+func newRaptorQError(op string, code ErrorCode, lastError func() string) *RaptorQError {
+	e := &RaptorQError{Op: op, Code: code}
+
+	entry, known := errorCodes[code]
+	if known {
+		e.err = entry.err
+	}
+	if (!known || entry.detail) && lastError != nil {
+		e.Detail = lastError()
+	}
+
+	// The native library reports every decoder failure as -15. Narrow it down
+	// when the message shows that the block simply lacked symbols.
+	if code == CodeDecodingFailed && isInsufficientSymbolsDetail(e.Detail) {
+		e.err = ErrInsufficientSymbols
+	}
+
+	return e
+}
+
+// isInsufficientSymbolsDetail reports whether a decoding error message
+// indicates that not enough symbols were available to reconstruct a block.
+//
+// The native library only says so when a block has no symbols at all ("None
+// of the symbols for block N could be found"); a block with too few symbols is
+// reported as a plain decoding failure. The pure-Go backend reports missing
+// source symbols as "insufficient symbols".
+func isInsufficientSymbolsDetail(detail string) bool {
+	detail = strings.ToLower(detail)
+	for _, marker := range []string{"insufficient", "could be found"} {
+		if strings.Contains(detail, marker) {
+			return true
+		}
+	}
+	return false
+}
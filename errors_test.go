@@ -0,0 +1,93 @@
+package rq_go
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Unit test for the shared native return code table
+func TestErrorCodeMapping(t *testing.T) {
+	cases := []struct {
+		code     ErrorCode
+		sentinel error
+	}{
+		{CodeGeneric, ErrGeneric},
+		{CodeInvalidParameters, ErrInvalidParameters},
+		{CodeInvalidResponse, ErrInvalidResponse},
+		{CodeBufferTooSmall, ErrBufferTooSmall},
+		{CodeInvalidSession, ErrInvalidSession},
+		{CodeIO, ErrIO},
+		{CodeFileNotFound, ErrFileNotFound},
+		{CodeInvalidPath, ErrInvalidPath},
+		{CodeEncodingFailed, ErrEncodingFailed},
+		{CodeDecodingFailed, ErrDecodingFailed},
+		{CodeMemoryLimit, ErrMemoryLimit},
+		{CodeConcurrencyLimit, ErrConcurrencyLimit},
+	}
+
+	for _, tc := range cases {
+		err := newRaptorQError("EncodeFile", tc.code, func() string { return "detail" })
+		if !errors.Is(err, tc.sentinel) {
+			t.Errorf("code %d: expected errors.Is(%v), got %v", tc.code, tc.sentinel, err)
+		}
+		if err.Code != tc.code {
+			t.Errorf("code %d: unexpected Code %d", tc.code, err.Code)
+		}
+		if !strings.HasPrefix(err.Error(), "EncodeFile: ") {
+			t.Errorf("code %d: error should start with the operation, got %q", tc.code, err.Error())
+		}
+	}
+}
+
+// Unit test ensuring the last error is only fetched for codes that carry one
+func TestErrorCodeDetail(t *testing.T) {
+	calls := 0
+	lastError := func() string {
+		calls++
+		return "File is not found: input.bin"
+	}
+
+	err := newRaptorQError("DecodeSymbols", CodeInvalidSession, lastError)
+	if calls != 0 || err.Detail != "" {
+		t.Fatalf("invalid session should not fetch the last error, got %q", err.Detail)
+	}
+
+	err = newRaptorQError("DecodeSymbols", CodeFileNotFound, lastError)
+	if calls != 1 || err.Detail != "File is not found: input.bin" {
+		t.Fatalf("file not found should carry the last error, got %q", err.Detail)
+	}
+	if err.Error() != "DecodeSymbols: file not found: File is not found: input.bin" {
+		t.Fatalf("unexpected error message: %s", err.Error())
+	}
+
+	var rqErr *RaptorQError
+	if !errors.As(error(err), &rqErr) || rqErr.Op != "DecodeSymbols" {
+		t.Fatalf("errors.As should expose the RaptorQError, got %v", err)
+	}
+}
+
+// Unit test for unknown codes and insufficient symbol classification
+func TestErrorCodeUnknownAndInsufficient(t *testing.T) {
+	err := newRaptorQError("EncodeFile", ErrorCode(-99), func() string { return "boom" })
+	if err.Unwrap() != nil {
+		t.Fatalf("unknown code should not map to a sentinel, got %v", err.Unwrap())
+	}
+	if err.Error() != "EncodeFile: unknown error code -99: boom" {
+		t.Fatalf("unexpected error message: %s", err.Error())
+	}
+
+	err = newRaptorQError("DecodeSymbols", CodeDecodingFailed, func() string {
+		return "None of the symbols for block 3 could be found"
+	})
+	if !errors.Is(err, ErrInsufficientSymbols) || !errors.Is(err, ErrDecodingFailed) {
+		t.Fatalf("expected insufficient symbols decoding error, got %v", err)
+	}
+
+	err = newRaptorQError("DecodeSymbols", CodeDecodingFailed, func() string {
+		return "Hash mismatch for block 0"
+	})
+	if errors.Is(err, ErrInsufficientSymbols) || !errors.Is(err, ErrDecodingFailed) {
+		t.Fatalf("hash mismatch should be a plain decoding failure, got %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+package rq_go
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+)
+
+// maxOverheadSymbols bounds the number of symbols beyond the source symbol
+// count the estimator considers; past it the failure probability is below
+// float64 resolution.
+const maxOverheadSymbols = 16
+
+// DecodeSuccessProbability estimates the probability that a RaptorQ block with
+// sourceSymbols source symbols decodes from available distinct, intact symbols.
+//
+// The estimate follows the overhead statistics of RFC 6330: decoding from
+// exactly as many symbols as source symbols fails with a probability of at
+// most 1%, and each additional symbol lowers it by a factor of 100
+// (0.01% with one extra symbol, 0.0001% with two). Below the source symbol
+// count decoding is impossible.
+//
+// Parameters:
+//   - sourceSymbols: The number of source symbols of the block.
+//   - available: The number of symbols available for decoding.
+//
+// Returns:
+//   - float64: The estimated success probability, between 0 and 1.
+func DecodeSuccessProbability(sourceSymbols, available int) float64 {
+	if sourceSymbols <= 0 {
+		return 1
+	}
+	if available < sourceSymbols {
+		return 0
+	}
+	extra := available - sourceSymbols
+	if extra > maxOverheadSymbols {
+		extra = maxOverheadSymbols
+	}
+	return 1 - math.Pow(10, -2*float64(extra+1))
+}
+
+// RecoverabilityReport is the result of EstimateRecoverability.
+type RecoverabilityReport struct {
+	// Blocks contains one entry per block of the layout, in layout order.
+	Blocks []BlockRecoverability `json:"blocks"`
+
+	// Probability is the estimated probability that every block decodes, the
+	// product of the block probabilities.
+	Probability float64 `json:"probability"`
+
+	// Recoverable is true if every block has at least as many available
+	// symbols as source symbols, so a decode is worth attempting.
+	Recoverable bool `json:"recoverable"`
+}
+
+// BlockRecoverability describes the symbols available for a single block.
+type BlockRecoverability struct {
+	// BlockID is the identifier of the block.
+	BlockID uint64 `json:"block_id"`
+
+	// SourceSymbols is the number of source symbols of the block, derived
+	// from its encoder parameters.
+	SourceSymbols int `json:"source_symbols"`
+
+	// Total is the number of distinct symbols listed in the layout.
+	Total int `json:"total"`
+
+	// Available is the number of listed symbols that are available.
+	Available int `json:"available"`
+
+	// Missing lists the symbols of the block that are not available, in
+	// layout order.
+	Missing []string `json:"missing,omitempty"`
+
+	// Probability is the estimated decode success probability of the block.
+	Probability float64 `json:"probability"`
+
+	// Recoverable is true if Available is at least SourceSymbols.
+	Recoverable bool `json:"recoverable"`
+}
+
+// EstimateRecoverability estimates, for each block of a layout, whether the
+// available symbols are sufficient to decode it.
+//
+// Only symbol IDs are considered: symbols are assumed to be intact, and IDs
+// that are not listed in the layout are ignored. Use VerifySymbols to check
+// symbol contents first. When the encoder parameters of a block describe
+// several RFC 6330 source blocks, the block is treated as a whole, assuming
+// the available symbols are spread evenly across them.
+//
+// Parameters:
+//   - layout: The layout describing the encoded blocks.
+//   - available: The IDs of the available symbols.
+//
+// Returns:
+//   - *RecoverabilityReport: The estimate for every block and the overall verdict.
+//   - error: An error matching ErrInvalidLayout if the encoder parameters of a
+//     block cannot be decoded.
+//
+// Example:
+//
+//	report, err := raptorq.EstimateRecoverability(layout, storedSymbolIDs)
+//	if err != nil {
+//	    return err
+//	}
+//	if !report.Recoverable {
+//	    plan, err := report.FetchPlan(0.999999)
+//	    ...
+//	}
+func EstimateRecoverability(layout *Layout, available []string) (*RecoverabilityReport, error) {
+	if layout == nil {
+		return nil, fmt.Errorf("%w: layout cannot be nil", ErrInvalidParameters)
+	}
+
+	have := make(map[string]struct{}, len(available))
+	for _, id := range available {
+		have[id] = struct{}{}
+	}
+
+	report := &RecoverabilityReport{Probability: 1, Recoverable: true}
+	for _, block := range layout.Blocks {
+		oti, err := block.OTI()
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", block.BlockID, err)
+		}
+
+		result := BlockRecoverability{
+			BlockID:       block.BlockID,
+			SourceSymbols: oti.SourceSymbols(),
+		}
+		seen := make(map[string]struct{}, len(block.Symbols))
+		for _, id := range block.Symbols {
+			if _, dup := seen[id]; dup {
+				continue
+			}
+			seen[id] = struct{}{}
+			result.Total++
+			if _, ok := have[id]; ok {
+				result.Available++
+			} else {
+				result.Missing = append(result.Missing, id)
+			}
+		}
+		result.Probability = DecodeSuccessProbability(result.SourceSymbols, result.Available)
+		result.Recoverable = result.Available >= result.SourceSymbols
+
+		report.Probability *= result.Probability
+		report.Recoverable = report.Recoverable && result.Recoverable
+		report.Blocks = append(report.Blocks, result)
+	}
+	return report, nil
+}
+
+// EstimateRecoverabilityDir is like EstimateRecoverability, with the symbols
+// available in a symbols directory laid out as written by EncodeFile. Symbols
+// are identified by file name only; their contents are not hashed.
+func EstimateRecoverabilityDir(symbolsDir string, layout *Layout) (*RecoverabilityReport, error) {
+	if symbolsDir == "" || layout == nil {
+		return nil, fmt.Errorf("%w: symbolsDir and layout cannot be empty", ErrInvalidParameters)
+	}
+	if _, err := os.Stat(symbolsDir); err != nil {
+		return nil, ioError("EstimateRecoverability", err)
+	}
+
+	var available []string
+	for _, block := range layout.Blocks {
+		entries, err := os.ReadDir(blockSymbolsDir(symbolsDir, block.BlockID))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, ioError("EstimateRecoverability", err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				available = append(available, entry.Name())
+			}
+		}
+	}
+	return EstimateRecoverability(layout, available)
+}
+
+// FetchPlan lists the additional symbols to request so that the whole layout
+// decodes with at least the target probability.
+type FetchPlan struct {
+	// Target is the requested overall success probability.
+	Target float64 `json:"target"`
+
+	// Blocks lists the blocks that need more symbols, in layout order.
+	Blocks []BlockFetch `json:"blocks,omitempty"`
+
+	// Total is the number of symbols to fetch across all blocks.
+	Total int `json:"total"`
+
+	// Probability is the estimated overall success probability once every
+	// symbol of the plan has been fetched.
+	Probability float64 `json:"probability"`
+
+	// Achievable is false if some blocks do not list enough symbols to reach
+	// the target; the plan then fetches every missing symbol of those blocks.
+	Achievable bool `json:"achievable"`
+}
+
+// BlockFetch lists the symbols to fetch for a single block.
+type BlockFetch struct {
+	// BlockID is the identifier of the block.
+	BlockID uint64 `json:"block_id"`
+
+	// Symbols are the IDs of the symbols to fetch, in layout order.
+	Symbols []string `json:"symbols"`
+
+	// Probability is the estimated success probability of the block once the
+	// symbols have been fetched.
+	Probability float64 `json:"probability"`
+}
+
+// FetchPlan computes the minimum number of additional symbols to fetch so that
+// the estimated probability of decoding every block reaches target.
+//
+// The target is split evenly across blocks, so each block must reach the
+// n-th root of target, and each block gets the fewest symbols that achieve it.
+// Missing symbols are picked in layout order.
+//
+// Parameters:
+//   - target: The desired overall success probability, greater than 0 and
+//     less than 1 (for example 0.999999).
+//
+// Returns:
+//   - *FetchPlan: The symbols to fetch per block.
+//   - error: An error matching ErrInvalidParameters if target is out of range.
+func (r *RecoverabilityReport) FetchPlan(target float64) (*FetchPlan, error) {
+	if !(target > 0 && target < 1) {
+		return nil, fmt.Errorf("%w: target probability must be between 0 and 1, got %v", ErrInvalidParameters, target)
+	}
+
+	plan := &FetchPlan{Target: target, Probability: 1, Achievable: true}
+	if len(r.Blocks) == 0 {
+		return plan, nil
+	}
+	blockTarget := math.Pow(target, 1/float64(len(r.Blocks)))
+
+	for _, block := range r.Blocks {
+		wanted := block.SourceSymbols + overheadForProbability(blockTarget)
+		fetch := wanted - block.Available
+		if fetch < 0 {
+			fetch = 0
+		}
+		if fetch > len(block.Missing) {
+			fetch = len(block.Missing)
+			plan.Achievable = false
+		}
+
+		probability := DecodeSuccessProbability(block.SourceSymbols, block.Available+fetch)
+		plan.Probability *= probability
+		if fetch == 0 {
+			continue
+		}
+
+		plan.Blocks = append(plan.Blocks, BlockFetch{
+			BlockID:     block.BlockID,
+			Symbols:     append([]string(nil), block.Missing[:fetch]...),
+			Probability: probability,
+		})
+		plan.Total += fetch
+	}
+	return plan, nil
+}
+
+// overheadForProbability returns the number of symbols beyond the source
+// symbol count needed to decode a block with at least the given probability.
+func overheadForProbability(p float64) int {
+	for extra := 0; extra < maxOverheadSymbols; extra++ {
+		if DecodeSuccessProbability(1, 1+extra) >= p {
+			return extra
+		}
+	}
+	return maxOverheadSymbols
+}
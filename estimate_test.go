@@ -0,0 +1,132 @@
+package rq_go
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// estimateLayout returns a two-block layout with 4 source symbols and 8 symbols per block
+func estimateLayout() *Layout {
+	layout := &Layout{}
+	for b := uint64(0); b < 2; b++ {
+		block := BlockLayout{
+			BlockID: b,
+			EncoderParameters: ObjectTransmissionInfo{
+				TransferLength: 256, SymbolSize: 64, SourceBlocks: 1, SubBlocks: 1, Alignment: 8,
+			}.Bytes(),
+			OriginalOffset: b * 256,
+			Size:           256,
+		}
+		for i := 0; i < 8; i++ {
+			block.Symbols = append(block.Symbols, testHash(byte(b*16)+byte(i)))
+		}
+		layout.Blocks = append(layout.Blocks, block)
+	}
+	return layout
+}
+
+// Unit test for the RFC 6330 overhead statistics
+func TestDecodeSuccessProbability(t *testing.T) {
+	tests := []struct {
+		source, available int
+		expected          float64
+	}{
+		{10, 9, 0},
+		{10, 10, 0.99},
+		{10, 11, 0.9999},
+		{10, 12, 0.999999},
+		{0, 0, 1},
+	}
+	for _, tt := range tests {
+		if got := DecodeSuccessProbability(tt.source, tt.available); math.Abs(got-tt.expected) > 1e-12 {
+			t.Fatalf("DecodeSuccessProbability(%d, %d) = %v, expected %v", tt.source, tt.available, got, tt.expected)
+		}
+	}
+}
+
+// Unit test for estimating recoverability and planning fetches
+func TestEstimateRecoverability(t *testing.T) {
+	layout := estimateLayout()
+
+	// Block 0 has 5 symbols (one extra), block 1 only 3
+	available := append([]string{}, layout.Blocks[0].Symbols[:5]...)
+	available = append(available, layout.Blocks[1].Symbols[2:5]...)
+	available = append(available, "unknown")
+
+	report, err := EstimateRecoverability(layout, available)
+	if err != nil {
+		t.Fatalf("Failed to estimate recoverability: %v", err)
+	}
+	b0, b1 := report.Blocks[0], report.Blocks[1]
+	if b0.SourceSymbols != 4 || b0.Available != 5 || !b0.Recoverable || math.Abs(b0.Probability-0.9999) > 1e-12 {
+		t.Fatalf("Unexpected block 0 estimate: %+v", b0)
+	}
+	if b1.Available != 3 || b1.Recoverable || b1.Probability != 0 || len(b1.Missing) != 5 {
+		t.Fatalf("Unexpected block 1 estimate: %+v", b1)
+	}
+	if report.Recoverable || report.Probability != 0 {
+		t.Fatalf("Expected an unrecoverable report: %+v", report)
+	}
+
+	plan, err := report.FetchPlan(0.9999)
+	if err != nil {
+		t.Fatalf("Failed to plan fetches: %v", err)
+	}
+	// Each block needs two extra symbols to reach sqrt(0.9999) > 0.9999
+	if !plan.Achievable || plan.Total != 4 || len(plan.Blocks) != 2 {
+		t.Fatalf("Unexpected plan: %+v", plan)
+	}
+	if len(plan.Blocks[0].Symbols) != 1 || plan.Blocks[0].Symbols[0] != layout.Blocks[0].Symbols[5] {
+		t.Fatalf("Unexpected block 0 fetch: %+v", plan.Blocks[0])
+	}
+	if len(plan.Blocks[1].Symbols) != 3 || plan.Blocks[1].Symbols[0] != layout.Blocks[1].Symbols[0] {
+		t.Fatalf("Unexpected block 1 fetch: %+v", plan.Blocks[1])
+	}
+	if plan.Probability < plan.Target {
+		t.Fatalf("Plan probability %v below target %v", plan.Probability, plan.Target)
+	}
+
+	// A target beyond what the listed symbols allow fetches everything
+	plan, err = report.FetchPlan(1 - 1e-15)
+	if err != nil {
+		t.Fatalf("Failed to plan fetches: %v", err)
+	}
+	if plan.Achievable || plan.Total != 8 {
+		t.Fatalf("Expected an unachievable plan fetching all 8 missing symbols: %+v", plan)
+	}
+
+	if _, err := report.FetchPlan(1); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters for target 1, got: %v", err)
+	}
+}
+
+// Unit test for estimating recoverability from a symbols directory
+func TestEstimateRecoverabilityDir(t *testing.T) {
+	layout := estimateLayout()
+	symbolsDir := t.TempDir()
+	for _, block := range layout.Blocks {
+		dir := filepath.Join(symbolsDir, blockDirName(block.BlockID))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for _, id := range block.Symbols[:6] {
+			if err := os.WriteFile(filepath.Join(dir, id), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	report, err := EstimateRecoverabilityDir(symbolsDir, layout)
+	if err != nil {
+		t.Fatalf("Failed to estimate recoverability: %v", err)
+	}
+	if !report.Recoverable || report.Blocks[0].Available != 6 || report.Blocks[1].Available != 6 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if math.Abs(report.Probability-0.999999*0.999999) > 1e-12 {
+		t.Fatalf("Unexpected overall probability %v", report.Probability)
+	}
+}
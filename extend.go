@@ -0,0 +1,180 @@
+package rq_go
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ExtendResult is the result of ExtendRepairSymbols.
+type ExtendResult struct {
+	// Blocks lists the symbols added to every block, in layout order.
+	Blocks []ExtendedBlock `json:"blocks"`
+
+	// Added is the total number of symbols added.
+	Added int `json:"added"`
+
+	// LayoutFilePath is the path of the updated layout file.
+	LayoutFilePath string `json:"layout_file_path"`
+}
+
+// ExtendedBlock lists the repair symbols added to one block.
+type ExtendedBlock struct {
+	// BlockID is the identifier of the block.
+	BlockID uint64 `json:"block_id"`
+
+	// Added lists the IDs of the new symbols in encoding symbol ID order, as
+	// appended to the block's symbols in the layout.
+	Added []string `json:"added"`
+}
+
+// ExtendRepairSymbols is like ExtendRepairSymbolsContext but without
+// cancellation.
+func (p *RaptorQProcessor) ExtendRepairSymbols(symbolsDir, layoutPath string, extraPerBlock int) (*ExtendResult, error) {
+	return p.ExtendRepairSymbolsContext(context.Background(), symbolsDir, layoutPath, extraPerBlock)
+}
+
+// ExtendRepairSymbolsContext generates extraPerBlock new repair symbols for
+// every block of an encoded file, without re-encoding the file or changing
+// its existing symbols.
+//
+// Each block is decoded from its intact symbols and encoded again with the
+// encoder parameters of the layout and a redundancy factor high enough to
+// produce the additional symbols. The repair symbols following the last one
+// listed in the layout get fresh encoding symbol IDs; they are written to the
+// block's directory and appended to the block's symbols in the layout. Once
+// every block is extended, the layout file is replaced atomically. If an error
+// occurs, the new symbol files are removed and the layout is left unchanged.
+//
+// The number of symbols per block is limited by the largest redundancy factor,
+// 255 repair symbols per source symbol. The pure-Go backend does not generate
+// repair symbols, so ExtendRepairSymbols fails with it.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation between blocks.
+//   - symbolsDir: Directory containing the encoded symbols.
+//   - layoutPath: Path to the layout file the symbols were encoded with. It is
+//     updated with the new symbols.
+//   - extraPerBlock: The number of repair symbols to add to every block.
+//
+// Returns:
+//   - *ExtendResult: The symbols added per block.
+//   - error: An error matching ErrInvalidParameters if extraPerBlock is not
+//     positive, ErrInsufficientSymbols if a block has too few intact symbols to
+//     be decoded, ErrEncodingFailed if the backend cannot generate enough repair
+//     symbols, the errors of DecodeSymbols and EncodeFile, or ctx.Err().
+//
+// Example:
+//
+//	// Add 10 repair symbols to every block
+//	result, err := processor.ExtendRepairSymbolsContext(ctx, "symbols/", "symbols/_raptorq_layout.json", 10)
+//	if err != nil {
+//	    return err
+//	}
+//	for _, b := range result.Blocks {
+//	    distribute(b.BlockID, b.Added)
+//	}
+func (p *RaptorQProcessor) ExtendRepairSymbolsContext(ctx context.Context, symbolsDir, layoutPath string, extraPerBlock int) (*ExtendResult, error) {
+	if extraPerBlock <= 0 {
+		return nil, fmt.Errorf("%w: extraPerBlock must be positive, got %d", ErrInvalidParameters, extraPerBlock)
+	}
+	layout, err := p.loadDecodeLayout(symbolsDir, layoutPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckLayout(layout); err != nil {
+		return nil, err
+	}
+
+	var created []string
+	committed := false
+	defer func() {
+		if !committed {
+			removePaths(created)
+		}
+	}()
+
+	result := &ExtendResult{Blocks: []ExtendedBlock{}, LayoutFilePath: layoutPath}
+	for i := range layout.Blocks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block := &layout.Blocks[i]
+		added, err := p.extendBlock(ctx, symbolsDir, *block, extraPerBlock, &created)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", block.BlockID, err)
+		}
+		block.Symbols = append(block.Symbols, added...)
+		result.Blocks = append(result.Blocks, ExtendedBlock{BlockID: block.BlockID, Added: added})
+		result.Added += len(added)
+	}
+
+	if err := saveLayout(layout, layoutPath); err != nil {
+		return nil, ioError("ExtendRepairSymbols", err)
+	}
+	committed = true
+	return result, nil
+}
+
+// extendBlock writes extra new repair symbols of a block to its directory,
+// recording the created files in created, and returns their IDs.
+func (p *RaptorQProcessor) extendBlock(ctx context.Context, symbolsDir string, block BlockLayout, extra int, created *[]string) ([]string, error) {
+	stageDir, err := os.MkdirTemp("", "raptorq-extend-")
+	if err != nil {
+		return nil, ioError("ExtendRepairSymbols", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	listed := make(map[string]bool, len(block.Symbols))
+	for _, id := range block.Symbols {
+		listed[id] = true
+	}
+	regenerated, ids, err := p.regenerateBlock(ctx, stageDir, symbolsDir, block, len(listed)+extra)
+	if err != nil {
+		return nil, err
+	}
+
+	// The regenerated symbols include those of the layout, so the others have
+	// fresh encoding symbol IDs
+	regeneratedIDs := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		regeneratedIDs[id] = true
+	}
+	for id := range listed {
+		if !regeneratedIDs[id] {
+			return nil, fmt.Errorf("%w: symbol %s of the layout was not regenerated", ErrEncodingFailed, id)
+		}
+	}
+	var added []string
+	for _, id := range ids {
+		if len(added) == extra {
+			break
+		}
+		if !listed[id] {
+			listed[id] = true
+			added = append(added, id)
+		}
+	}
+	if len(added) < extra {
+		return nil, fmt.Errorf("%w: only %d of %d additional repair symbols were generated",
+			ErrEncodingFailed, len(added), extra)
+	}
+
+	dir := blockSymbolsDir(symbolsDir, block.BlockID)
+	for _, id := range added {
+		data, err := os.ReadFile(filepath.Join(regenerated, id))
+		if err != nil {
+			return nil, ioError("ExtendRepairSymbols", err)
+		}
+		if hashBytes(data) != id {
+			return nil, fmt.Errorf("%w: generated symbol %s does not match its ID", ErrHashMismatch, id)
+		}
+		dst := filepath.Join(dir, id)
+		if err := writeFileAtomic(dst, data, 0644); err != nil {
+			return nil, ioError("ExtendRepairSymbols", err)
+		}
+		*created = append(*created, dst)
+	}
+	return added, nil
+}
@@ -0,0 +1,113 @@
+package rq_go_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	raptorq "github.com/LumeraProtocol/rq-go"
+)
+
+// readSymbols returns the content of every symbol file under symbolsDir,
+// keyed by path relative to symbolsDir
+func readSymbols(t *testing.T, symbolsDir string) map[string][]byte {
+	t.Helper()
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(symbolsDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Dir(path) == symbolsDir {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(symbolsDir, path)
+		files[rel] = data
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// Unit test for adding repair symbols to a damaged encoding, decoded by the
+// fake backend from the surviving symbols
+func TestExtendRepairSymbolsFakeBackend(t *testing.T) {
+	processor := newFakeProcessor(t)
+	symbolsDir, res := encodeFake(t, processor, t.TempDir(), 3*1024*1024+100, 1024*1024)
+	before, err := raptorq.LoadLayout(res.LayoutFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Blocks are decoded from their surviving symbols: lose a source and a
+	// repair symbol of every block
+	for _, block := range before.Blocks {
+		dropSymbols(t, symbolsDir, block.BlockID, block.Symbols[0], block.Symbols[len(block.Symbols)-1])
+	}
+	existing := readSymbols(t, symbolsDir)
+
+	// The processor generates 4 repair symbols per source symbol, so the new
+	// symbols come from a session with a higher redundancy factor
+	extended, err := processor.ExtendRepairSymbols(symbolsDir, res.LayoutFilePath, 3)
+	if err != nil {
+		t.Fatalf("Failed to extend repair symbols: %v", err)
+	}
+	if extended.Added != 3*len(before.Blocks) {
+		t.Fatalf("Expected %d new symbols, got %d", 3*len(before.Blocks), extended.Added)
+	}
+	after, err := raptorq.LoadLayout(res.LayoutFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, block := range after.Blocks {
+		old := before.Blocks[i]
+		if !bytes.Equal(block.EncoderParameters, old.EncoderParameters) || block.Hash != old.Hash ||
+			block.Size != old.Size || block.OriginalOffset != old.OriginalOffset {
+			t.Fatalf("Expected block %d to keep its encoder parameters:\n before: %+v\n after:  %+v", block.BlockID, old, block)
+		}
+		if len(block.Symbols) != len(old.Symbols)+3 || !reflect.DeepEqual(block.Symbols[:len(old.Symbols)], old.Symbols) {
+			t.Fatalf("Expected 3 symbols appended to block %d", block.BlockID)
+		}
+		// The new symbols follow the encoding symbol IDs of the layout, as the
+		// fake names its placeholders after them
+		for j, id := range extended.Blocks[i].Added {
+			esi := len(old.Symbols) + j
+			if want := raptorq.ContentHash([]byte(fmt.Sprintf("%s/%d", block.Hash, esi))); id != want || block.Symbols[esi] != id {
+				t.Fatalf("Block %d: unexpected new symbol %s", block.BlockID, id)
+			}
+		}
+	}
+
+	// The existing symbol files are untouched, and only new ones were written
+	files := readSymbols(t, symbolsDir)
+	for path, data := range existing {
+		if !bytes.Equal(files[path], data) {
+			t.Fatalf("Existing symbol %s was changed", path)
+		}
+	}
+	if len(files) != len(existing)+extended.Added {
+		t.Fatalf("Expected %d symbol files, got %d", len(existing)+extended.Added, len(files))
+	}
+
+	// Only the lost symbols are missing, and the new ones match their hashes
+	report, err := raptorq.VerifySymbols(symbolsDir, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lost []string
+	for i, block := range report.Blocks {
+		if len(block.Missing) != 2 || len(block.Corrupt) != 0 || !block.Decodable {
+			t.Fatalf("Unexpected state of block %d after extension: %+v", i, block)
+		}
+		lost = append(lost, block.Missing...)
+	}
+	if _, err := processor.RepairSymbols(symbolsDir, res.LayoutFilePath, lost); err != nil {
+		t.Fatalf("Failed to repair symbols: %v", err)
+	}
+	checkSymbols(t, symbolsDir, after)
+}
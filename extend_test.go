@@ -0,0 +1,87 @@
+package rq_go
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// System test adding repair symbols to an encoded file (2MB, 1MB blocks)
+func TestSysExtendRepairSymbols(t *testing.T) {
+	processor, err := NewDefaultRaptorQProcessor()
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	ctx := NewTestContext(t, 2*1024*1024+100)
+	defer ctx.Cleanup()
+	res, err := processor.EncodeFile(ctx.InputFile, ctx.SymbolsDir, 1024*1024)
+	if err != nil {
+		t.Fatalf("Failed to encode file: %v", err)
+	}
+	before, err := LoadLayout(res.LayoutFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := processor.ExtendRepairSymbols(ctx.SymbolsDir, res.LayoutFilePath, 0); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters for no extra symbols, got: %v", err)
+	}
+	if res.TotalRepairSymbols == 0 {
+		// Without repair symbols nothing is added and nothing is changed
+		if _, err := processor.ExtendRepairSymbols(ctx.SymbolsDir, res.LayoutFilePath, 3); !errors.Is(err, ErrEncodingFailed) {
+			t.Fatalf("Expected ErrEncodingFailed without repair symbols, got: %v", err)
+		}
+		after, err := LoadLayout(res.LayoutFilePath)
+		if err != nil || !reflect.DeepEqual(before, after) {
+			t.Fatalf("Expected the layout to be unchanged, got %v", err)
+		}
+		report, err := VerifySymbols(ctx.SymbolsDir, after)
+		if err != nil || !report.Healthy() {
+			t.Fatalf("Expected no stray symbols to be left, got %v", err)
+		}
+	}
+	skipWithoutRepairSymbols(t)
+
+	extended, err := processor.ExtendRepairSymbols(ctx.SymbolsDir, res.LayoutFilePath, 3)
+	if err != nil {
+		t.Fatalf("Failed to extend repair symbols: %v", err)
+	}
+	if extended.Added != 3*len(before.Blocks) {
+		t.Fatalf("Expected %d new symbols, got %d", 3*len(before.Blocks), extended.Added)
+	}
+	after, err := LoadLayout(res.LayoutFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, block := range after.Blocks {
+		old := before.Blocks[i].Symbols
+		if len(block.Symbols) != len(old)+3 || !reflect.DeepEqual(block.Symbols[:len(old)], old) {
+			t.Fatalf("Expected 3 symbols appended to block %d", block.BlockID)
+		}
+	}
+	report, err := VerifySymbols(ctx.SymbolsDir, after)
+	if err != nil || !report.Healthy() {
+		t.Fatalf("Symbols are not healthy after extension: %v", err)
+	}
+
+	// The new symbols replace lost ones: drop every original repair symbol and
+	// one source symbol of block 0
+	block := after.Blocks[0]
+	oti, _ := block.OTI()
+	source := oti.SourceSymbols()
+	for _, id := range append([]string{block.Symbols[0]}, before.Blocks[0].Symbols[source:]...) {
+		if err := os.Remove(filepath.Join(ctx.SymbolsDir, blockDirName(0), id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := processor.DecodeSymbols(ctx.SymbolsDir, ctx.OutputFile, res.LayoutFilePath); err != nil {
+		t.Fatalf("Failed to decode with the new symbols: %v", err)
+	}
+	if !ctx.VerifyFilesMatch(t) {
+		t.Fatal("Decoded file does not match the original")
+	}
+}
@@ -0,0 +1,480 @@
+package rq_go
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// symbolHeaderSize is the size of the RFC 6330 payload ID stored in front of
+// the data of every symbol file: the source block number (8 bits) followed by
+// the encoding symbol ID (24 bits, big-endian).
+const symbolHeaderSize = 4
+
+// goCodec implements the library operations in pure Go for the backend
+// returned by NewPureGoBackend. It is compiled into every build so that its
+// output can be checked against the native library, but it is not a fallback
+// for it.
+//
+// RaptorQ is a systematic code: the first K encoding symbols of a source block
+// are the source data itself, and only the repair symbols require the RFC 6330
+// precoding. goCodec implements the systematic part of the code:
+//   - encoding writes the source symbols of every block, with the same payload
+//     IDs, symbol IDs, block hashes and encoder parameters as the native
+//     library. Since it cannot generate repair symbols, it fails with
+//     CodeInvalidParameters when the redundancy factor is not 0 rather than
+//     writing a file without the redundancy that was asked for;
+//   - decoding reassembles every block from its source symbols, for any number
+//     of source blocks and sub-blocks, and fails with ErrInsufficientSymbols
+//     when a source symbol is missing.
+//
+// Generating and decoding repair symbols needs the RFC 6330 lookup tables and
+// the intermediate symbol solver, which are only available natively.
+type goCodec struct {
+	config ProcessorConfig
+}
+
+// codecError is a failure of goCodec together with the native return code for
+// the same condition, so that it is reported like a native failure.
+type codecError struct {
+	code ErrorCode
+	msg  string
+}
+
+// Error implements the error interface.
+func (e *codecError) Error() string {
+	return e.msg
+}
+
+// codecErrorf formats a codecError.
+func codecErrorf(code ErrorCode, format string, args ...any) error {
+	return &codecError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+// fileError converts a file system error into a codecError.
+func fileError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return codecErrorf(CodeFileNotFound, "%v", err)
+	}
+	return codecErrorf(CodeIO, "%v", err)
+}
+
+// symbolSize returns the symbol size used for encoding: the configured size
+// rounded down to SymbolAlignment, as done by the native encoder.
+func (c goCodec) symbolSize() uint16 {
+	return c.config.SymbolSize - c.config.SymbolSize%SymbolAlignment
+}
+
+// recommendedBlockSize returns the block size used when none is given: the
+// whole file, limited to the largest source block RFC 6330 allows and to a
+// quarter of the memory limit, since a block is held in memory together with
+// its symbols.
+func (c goCodec) recommendedBlockSize(fileSize uint64) uint64 {
+	t := uint64(c.symbolSize())
+	if t == 0 {
+		return 0
+	}
+
+	limit := uint64(MaxSourceSymbolsPerBlock) * t
+	if mem := c.config.MaxMemoryMB * 1024 * 1024 / 4; mem < limit {
+		limit = mem
+	}
+	limit -= limit % t
+	if limit == 0 {
+		limit = t
+	}
+
+	if fileSize < limit {
+		return fileSize
+	}
+	return limit
+}
+
+// encode implements EncodeFile (writeSymbols set) and CreateMetadata. Every
+// block is encoded as a single source block and sub-block.
+func (c goCodec) encode(inputPath, outputDir, layoutPath string, blockSize int, writeSymbols bool) (*ProcessResult, error) {
+	if err := c.checkEncode(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(inputPath)
+	if err != nil {
+		return nil, fileError(err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fileError(err)
+	}
+	fileSize := uint64(info.Size())
+	if fileSize == 0 {
+		return nil, codecErrorf(CodeInvalidParameters, "input file %s is empty", inputPath)
+	}
+	size, err := c.encodeBlockSize(fileSize, blockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(layoutPath), 0755); err != nil {
+		return nil, fileError(err)
+	}
+
+	var emit func(blockID uint64, id string, symbol []byte) error
+	if writeSymbols {
+		var dir string
+		emit = func(blockID uint64, id string, symbol []byte) error {
+			if blockDir := filepath.Join(outputDir, blockDirName(blockID)); blockDir != dir {
+				if err := os.MkdirAll(blockDir, 0755); err != nil {
+					return fileError(err)
+				}
+				dir = blockDir
+			}
+			if err := os.WriteFile(filepath.Join(dir, id), symbol, 0644); err != nil {
+				return fileError(err)
+			}
+			return nil
+		}
+	}
+	result, layout, err := c.encodeBlocks(f, fileSize, size, emit)
+	if err != nil {
+		return nil, err
+	}
+	result.LayoutFilePath = layoutPath
+	if writeSymbols {
+		result.SymbolsDirectory = outputDir
+	}
+
+	if err := layout.Save(layoutPath); err != nil {
+		return nil, fileError(err)
+	}
+	return result, nil
+}
+
+// encodeBytes implements EncodeBytes of MemoryBackend, with the blocks encode
+// would write for a file holding data.
+func (c goCodec) encodeBytes(data []byte, blockSize int) (*EncodedObject, error) {
+	if err := c.checkEncode(); err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, codecErrorf(CodeInvalidParameters, "input data is empty")
+	}
+	size, err := c.encodeBlockSize(uint64(len(data)), blockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make(map[string][]byte)
+	result, layout, err := c.encodeBlocks(bytes.NewReader(data), uint64(len(data)), size, func(_ uint64, id string, symbol []byte) error {
+		symbols[id] = symbol
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	layoutData, err := layout.Marshal()
+	if err != nil {
+		return nil, codecErrorf(CodeGeneric, "failed to serialize layout: %v", err)
+	}
+	return &EncodedObject{Result: result, Layout: layoutData, Symbols: symbols}, nil
+}
+
+// checkEncode checks that the configuration can be encoded with.
+func (c goCodec) checkEncode() error {
+	if c.symbolSize() == 0 {
+		return codecErrorf(CodeInvalidParameters, "symbol size %d is smaller than the alignment of %d bytes",
+			c.config.SymbolSize, SymbolAlignment)
+	}
+	if c.config.RedundancyFactor > 0 {
+		return codecErrorf(CodeInvalidParameters, "redundancy factor %d asks for repair symbols, which only the native library generates; "+
+			"the pure-Go backend encodes with a redundancy factor of 0", c.config.RedundancyFactor)
+	}
+	return nil
+}
+
+// encodeBlockSize returns the size of the blocks of an input of dataSize
+// bytes: blockSize, or the recommended size if blockSize is not positive.
+func (c goCodec) encodeBlockSize(dataSize uint64, blockSize int) (uint64, error) {
+	t := c.symbolSize()
+	size := uint64(blockSize)
+	if blockSize <= 0 {
+		size = c.recommendedBlockSize(dataSize)
+	}
+	if k := (size + uint64(t) - 1) / uint64(t); k > MaxSourceSymbolsPerBlock {
+		return 0, codecErrorf(CodeInvalidParameters, "block size %d needs %d source symbols of %d bytes, at most %d are allowed",
+			size, k, t, MaxSourceSymbolsPerBlock)
+	}
+	return size, nil
+}
+
+// encodeBlocks encodes the dataSize bytes of r in blocks of blockSize bytes
+// and returns the result and the layout of the encoding, without paths. If
+// emit is not nil, it receives every symbol with its ID.
+func (c goCodec) encodeBlocks(r io.ReaderAt, dataSize, blockSize uint64, emit func(blockID uint64, id string, symbol []byte) error) (*ProcessResult, *Layout, error) {
+	t := c.symbolSize()
+	result := &ProcessResult{}
+	layout := &Layout{}
+
+	spans := splitBlocks(dataSize, blockSize)
+	buf := make([]byte, spans[0].size)
+	for _, span := range spans {
+		data := buf[:span.size]
+		if _, err := r.ReadAt(data, int64(span.offset)); err != nil {
+			return nil, nil, fileError(err)
+		}
+
+		oti := ObjectTransmissionInfo{
+			TransferLength: span.size,
+			SymbolSize:     t,
+			SourceBlocks:   1,
+			SubBlocks:      1,
+			Alignment:      SymbolAlignment,
+		}
+		symbols := encodeSourceSymbols(oti, data)
+
+		entry := BlockLayout{
+			BlockID:           span.id,
+			EncoderParameters: oti.Bytes(),
+			OriginalOffset:    span.offset,
+			Size:              span.size,
+			Hash:              hashBytes(data),
+		}
+		for _, symbol := range symbols {
+			id := hashBytes(symbol)
+			entry.Symbols = append(entry.Symbols, id)
+			if emit != nil {
+				if err := emit(span.id, id, symbol); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+		layout.Blocks = append(layout.Blocks, entry)
+
+		result.Blocks = append(result.Blocks, Block{
+			BlockID:            entry.BlockID,
+			EncoderParameters:  entry.EncoderParameters,
+			OriginalOffset:     entry.OriginalOffset,
+			Size:               entry.Size,
+			SymbolsCount:       uint32(len(symbols)),
+			SourceSymbolsCount: uint32(len(symbols)),
+			Hash:               entry.Hash,
+		})
+		result.TotalSymbolsCount += uint32(len(symbols))
+	}
+	return result, layout, nil
+}
+
+// decode implements DecodeSymbols.
+func (c goCodec) decode(symbolsDir, outputPath, layoutPath string) error {
+	layout, err := LoadLayout(layoutPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fileError(err)
+		}
+		return codecErrorf(CodeInvalidParameters, "%v", err)
+	}
+
+	out, err := os.Create(outputPath)
+	if err != nil {
+		return fileError(err)
+	}
+	defer out.Close()
+
+	for _, block := range layout.Blocks {
+		files := make(map[string][]byte)
+		if err := readBlockSymbols(symbolsDir, block.BlockID, files); err != nil {
+			return fileError(err)
+		}
+		data, err := decodeBlock(block, files)
+		if err != nil {
+			return err
+		}
+		if _, err := out.WriteAt(data, int64(block.OriginalOffset)); err != nil {
+			return fileError(err)
+		}
+	}
+
+	if err := out.Close(); err != nil {
+		return fileError(err)
+	}
+	return nil
+}
+
+// decodeBytes implements DecodeBytes of MemoryBackend. Only the symbols the
+// layout lists for a block are used to decode it, as when DecodeBytes stages
+// them in block directories.
+func (c goCodec) decodeBytes(layoutData []byte, symbols map[string][]byte) ([]byte, error) {
+	layout, err := ParseLayout(layoutData)
+	if err != nil {
+		return nil, codecErrorf(CodeInvalidParameters, "%v", err)
+	}
+
+	out := make([]byte, layout.TotalSize())
+	for _, block := range layout.Blocks {
+		files := make(map[string][]byte, len(block.Symbols))
+		for _, id := range block.Symbols {
+			if data, ok := symbols[id]; ok {
+				files[id] = data
+			}
+		}
+		data, err := decodeBlock(block, files)
+		if err != nil {
+			return nil, err
+		}
+		copy(out[block.OriginalOffset:], data)
+	}
+	return out, nil
+}
+
+// decodeBlock reassembles a block from its symbol files, keyed by symbol ID.
+func decodeBlock(block BlockLayout, files map[string][]byte) ([]byte, error) {
+	oti, err := block.OTI()
+	if err != nil {
+		return nil, codecErrorf(CodeDecodingFailed, "block %d: %v", block.BlockID, err)
+	}
+
+	packets := make(map[uint32][]byte, len(files))
+	for id, data := range files {
+		// Symbols whose content does not match their ID are corrupt.
+		if len(data) != symbolHeaderSize+int(oti.SymbolSize) || hashBytes(data) != id {
+			continue
+		}
+		packets[payloadID(data)] = data[symbolHeaderSize:]
+	}
+
+	data, err := decodeSourceSymbols(oti, packets)
+	if err != nil {
+		return nil, codecErrorf(CodeDecodingFailed, "block %d: %v", block.BlockID, err)
+	}
+	if block.Hash != "" && hashBytes(data) != block.Hash {
+		return nil, codecErrorf(CodeDecodingFailed, "block %d: decoded data does not match hash %s", block.BlockID, block.Hash)
+	}
+	return data, nil
+}
+
+// payloadID returns the source block number and encoding symbol ID of a symbol
+// file as a single key: SBN in the top 8 bits, ESI in the low 24 bits.
+func payloadID(symbol []byte) uint32 {
+	return uint32(symbol[0])<<24 | uint32(symbol[1])<<16 | uint32(symbol[2])<<8 | uint32(symbol[3])
+}
+
+// sourceBlock describes where an RFC 6330 source block lies in the padded object.
+type sourceBlock struct {
+	offset  uint64
+	symbols int
+}
+
+// sourceBlocks partitions the object into source blocks as specified in
+// section 4.4.1.2 of RFC 6330.
+func sourceBlocks(oti ObjectTransmissionInfo) []sourceBlock {
+	blocks := make([]sourceBlock, oti.SourceBlocks)
+	var offset uint64
+	for sbn := range blocks {
+		k := oti.SourceBlockSymbols(sbn)
+		blocks[sbn] = sourceBlock{offset: offset, symbols: k}
+		offset += uint64(k) * uint64(oti.SymbolSize)
+	}
+	return blocks
+}
+
+// subSymbolSizes returns the size of the sub-symbols contributed by each
+// sub-block to a symbol: the symbol size in units of the alignment is
+// partitioned over the N sub-blocks, the first ones getting one unit more.
+func subSymbolSizes(oti ObjectTransmissionInfo) []int {
+	n := int(oti.SubBlocks)
+	if n == 0 || oti.Alignment == 0 {
+		return nil
+	}
+	units := int(oti.SymbolSize) / int(oti.Alignment)
+	large, small := (units+n-1)/n, units/n
+	sizes := make([]int, n)
+	for j := range sizes {
+		if j < units-small*n {
+			sizes[j] = large * int(oti.Alignment)
+		} else {
+			sizes[j] = small * int(oti.Alignment)
+		}
+	}
+	return sizes
+}
+
+// encodeSourceSymbols splits data into the source symbols of every source
+// block and returns them as symbol files: payload ID followed by the symbol.
+//
+// Within a source block, sub-block j holds K sub-symbols of the j-th sub-symbol
+// size, and source symbol i is the concatenation of sub-symbol i of every
+// sub-block. The object is padded with zeros to a whole number of symbols.
+func encodeSourceSymbols(oti ObjectTransmissionInfo, data []byte) [][]byte {
+	t := int(oti.SymbolSize)
+	subSizes := subSymbolSizes(oti)
+
+	var symbols [][]byte
+	for sbn, block := range sourceBlocks(oti) {
+		k := block.symbols
+		blockData := make([]byte, k*t)
+		if block.offset < uint64(len(data)) {
+			copy(blockData, data[block.offset:])
+		}
+
+		for esi := 0; esi < k; esi++ {
+			symbol := make([]byte, symbolHeaderSize, symbolHeaderSize+t)
+			symbol[0] = byte(sbn)
+			symbol[1], symbol[2], symbol[3] = byte(esi>>16), byte(esi>>8), byte(esi)
+
+			subOffset := 0
+			for _, size := range subSizes {
+				start := subOffset + esi*size
+				symbol = append(symbol, blockData[start:start+size]...)
+				subOffset += k * size
+			}
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
+
+// decodeSourceSymbols reassembles the object from the source symbols in
+// packets, keyed by payloadID. It fails if any source symbol is missing.
+func decodeSourceSymbols(oti ObjectTransmissionInfo, packets map[uint32][]byte) ([]byte, error) {
+	t := int(oti.SymbolSize)
+	subSizes := subSymbolSizes(oti)
+	blocks := sourceBlocks(oti)
+
+	var padded uint64
+	missing := 0
+	for sbn, block := range blocks {
+		padded += uint64(block.symbols) * uint64(t)
+		for esi := 0; esi < block.symbols; esi++ {
+			if _, ok := packets[uint32(sbn)<<24|uint32(esi)]; !ok {
+				missing++
+			}
+		}
+	}
+	if missing > 0 {
+		return nil, fmt.Errorf("insufficient symbols: %d of %d source symbols are missing, "+
+			"and repair symbols can only be decoded by the native library", missing, oti.SourceSymbols())
+	}
+
+	object := make([]byte, padded)
+	for sbn, block := range blocks {
+		k := block.symbols
+		blockData := object[block.offset : block.offset+uint64(k*t)]
+		for esi := 0; esi < k; esi++ {
+			symbol := packets[uint32(sbn)<<24|uint32(esi)]
+
+			subOffset, pos := 0, 0
+			for _, size := range subSizes {
+				start := subOffset + esi*size
+				copy(blockData[start:start+size], symbol[pos:pos+size])
+				pos += size
+				subOffset += k * size
+			}
+		}
+	}
+	return object[:oti.TransferLength], nil
+}
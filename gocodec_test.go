@@ -0,0 +1,155 @@
+package rq_go
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// skipWithoutRepairSymbols skips tests that lose source symbols in builds that
+// cannot decode repair symbols
+func skipWithoutRepairSymbols(t *testing.T) {
+	t.Helper()
+	if !repairSymbolsSupported {
+		t.Skip("repair symbols need the native library")
+	}
+}
+
+// skipWithoutNativeLibrary skips tests that need processors on the default
+// backend in builds without the native library
+func skipWithoutNativeLibrary(t *testing.T) {
+	t.Helper()
+	if !nativeLibraryLinked {
+		t.Skip("the native library is not linked in builds without cgo")
+	}
+}
+
+// Unit test for splitting and reassembling source symbols with source blocks and sub-blocks
+func TestGoCodecSourceSymbols(t *testing.T) {
+	tests := []struct {
+		name string
+		oti  ObjectTransmissionInfo
+	}{
+		{"single block", ObjectTransmissionInfo{TransferLength: 1000, SymbolSize: 64, SourceBlocks: 1, SubBlocks: 1, Alignment: 8}},
+		{"exact symbols", ObjectTransmissionInfo{TransferLength: 1024, SymbolSize: 64, SourceBlocks: 1, SubBlocks: 1, Alignment: 8}},
+		{"source blocks", ObjectTransmissionInfo{TransferLength: 1000, SymbolSize: 64, SourceBlocks: 3, SubBlocks: 1, Alignment: 8}},
+		{"sub-blocks", ObjectTransmissionInfo{TransferLength: 1000, SymbolSize: 64, SourceBlocks: 1, SubBlocks: 3, Alignment: 8}},
+		{"both", ObjectTransmissionInfo{TransferLength: 5000, SymbolSize: 40, SourceBlocks: 4, SubBlocks: 2, Alignment: 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make([]byte, tt.oti.TransferLength)
+			rand.New(rand.NewSource(1)).Read(data)
+
+			symbols := encodeSourceSymbols(tt.oti, data)
+			if len(symbols) != tt.oti.SourceSymbols() {
+				t.Fatalf("Expected %d source symbols, got %d", tt.oti.SourceSymbols(), len(symbols))
+			}
+
+			packets := make(map[uint32][]byte)
+			for _, symbol := range symbols {
+				if len(symbol) != symbolHeaderSize+int(tt.oti.SymbolSize) {
+					t.Fatalf("Unexpected symbol length %d", len(symbol))
+				}
+				packets[payloadID(symbol)] = symbol[symbolHeaderSize:]
+			}
+			decoded, err := decodeSourceSymbols(tt.oti, packets)
+			if err != nil {
+				t.Fatalf("Failed to decode: %v", err)
+			}
+			if !bytes.Equal(decoded, data) {
+				t.Fatal("Decoded data does not match the original")
+			}
+
+			// Without sub-blocks the source symbols are plain slices of the data
+			if tt.oti.SourceBlocks == 1 && tt.oti.SubBlocks == 1 {
+				if !bytes.Equal(symbols[1][symbolHeaderSize:], data[64:128]) {
+					t.Fatal("Expected source symbol 1 to hold bytes 64 to 128")
+				}
+			}
+
+			delete(packets, payloadID(symbols[len(symbols)-1]))
+			if _, err := decodeSourceSymbols(tt.oti, packets); err == nil || !strings.Contains(err.Error(), "insufficient") {
+				t.Fatalf("Expected an insufficient symbols error, got: %v", err)
+			}
+		})
+	}
+
+	// 64 bytes are 8 alignment units, partitioned over 3 sub-blocks as 3, 3 and 2
+	oti := ObjectTransmissionInfo{SymbolSize: 64, SubBlocks: 3, Alignment: 8}
+	if sizes := subSymbolSizes(oti); len(sizes) != 3 || sizes[0] != 24 || sizes[1] != 24 || sizes[2] != 16 {
+		t.Fatalf("Unexpected sub-symbol sizes: %v", sizes)
+	}
+}
+
+// Unit test for encoding and decoding files with the pure-Go codec
+func TestGoCodecEncodeDecode(t *testing.T) {
+	dir := t.TempDir()
+	codec := goCodec{config: ProcessorConfig{SymbolSize: 1003, MaxMemoryMB: 256, ConcurrencyLimit: 1}}
+
+	data := make([]byte, 25000)
+	rand.New(rand.NewSource(2)).Read(data)
+	inputPath := filepath.Join(dir, "input.bin")
+	if err := os.WriteFile(inputPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	symbolsDir := filepath.Join(dir, "symbols")
+	layoutPath := filepath.Join(symbolsDir, layoutFileName)
+	res, err := codec.encode(inputPath, symbolsDir, layoutPath, 10000, true)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	// The symbol size is aligned down to 1000 bytes
+	if len(res.Blocks) != 3 || res.Blocks[0].SourceSymbolsCount != 10 || res.Blocks[2].SourceSymbolsCount != 5 {
+		t.Fatalf("Unexpected result: %+v", res)
+	}
+
+	layout, err := LoadLayout(layoutPath)
+	if err != nil {
+		t.Fatalf("Failed to load layout: %v", err)
+	}
+	if err := layout.Validate(); err != nil {
+		t.Fatalf("Layout is not valid: %v", err)
+	}
+	if report, err := VerifySymbols(symbolsDir, layout); err != nil || !report.Healthy() {
+		t.Fatalf("Symbols do not match the layout: %v", err)
+	}
+
+	outputPath := filepath.Join(dir, "output.bin")
+	if err := codec.decode(symbolsDir, outputPath, layoutPath); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	if err := VerifyDecodedFile(outputPath, layout); err != nil {
+		t.Fatalf("Decoded file does not match the layout: %v", err)
+	}
+
+	// Losing a source symbol cannot be repaired
+	lost := filepath.Join(symbolsDir, blockDirName(1), layout.Blocks[1].Symbols[0])
+	if err := os.Remove(lost); err != nil {
+		t.Fatal(err)
+	}
+	err = codec.decode(symbolsDir, outputPath, layoutPath)
+	var cerr *codecError
+	if !errors.As(err, &cerr) || cerr.code != CodeDecodingFailed || !isInsufficientSymbolsDetail(cerr.msg) {
+		t.Fatalf("Expected an insufficient symbols error, got: %v", err)
+	}
+
+	if _, err := codec.encode(filepath.Join(dir, "missing"), symbolsDir, layoutPath, 0, true); !errors.As(err, &cerr) || cerr.code != CodeFileNotFound {
+		t.Fatalf("Expected a file not found error, got: %v", err)
+	}
+
+	// Repair symbols cannot be generated, so a redundancy factor is rejected
+	// instead of silently writing unprotected output
+	codec.config.RedundancyFactor = DefaultRedundancyFactor
+	if _, err := codec.encode(inputPath, filepath.Join(dir, "redundant"), filepath.Join(dir, "redundant.json"), 0, false); !errors.As(err, &cerr) || cerr.code != CodeInvalidParameters {
+		t.Fatalf("Expected an invalid parameters error, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "redundant.json")); !os.IsNotExist(err) {
+		t.Fatalf("Expected no layout to be written, got: %v", err)
+	}
+}
@@ -50,12 +50,12 @@ bool raptorq_free_session(uintptr_t session_id);
  * *  -2 on invalid parameters
  * *  -3 on invalid response
  * *  -4 on bad return buffer size
- * *  -4 on encoding failure
  * *  -5 on invalid session
  * * -11 on IO error
  * * -12 on File not found
  * * -13 on Invalid path
  * * -14 on Encoding failed
+ * * -15 on Decoding failed
  * * -16 on Memory limit exceeded
  * * -17 on Concurrency limit reached
  */
@@ -83,12 +83,12 @@ int32_t raptorq_create_metadata(uintptr_t session_id,
  * *  -2 on invalid parameters
  * *  -3 on invalid response
  * *  -4 on bad return buffer size
- * *  -4 on encoding failure
  * *  -5 on invalid session
  * * -11 on IO error
  * * -12 on File not found
- * * -13 on Invalid Path
+ * * -13 on Invalid path
  * * -14 on Encoding failed
+ * * -15 on Decoding failed
  * * -16 on Memory limit exceeded
  * * -17 on Concurrency limit reached
  */
@@ -130,11 +130,11 @@ int32_t raptorq_get_last_error(uintptr_t session_id,
  * *  -2 on invalid parameters
  * *  -3 on invalid response
  * *  -4 on bad return buffer size
- * *  -4 on encoding failure
  * *  -5 on invalid session
  * * -11 on IO error
  * * -12 on File not found
- * * -13 on Invalid Path
+ * * -13 on Invalid path
+ * * -14 on Encoding failed
  * * -15 on Decoding failed
  * * -16 on Memory limit exceeded
  * * -17 on Concurrency limit reached
@@ -0,0 +1,233 @@
+package rq_go
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// LayoutSummary is the result of InspectLayout: a human-oriented overview of a
+// layout, optionally combined with the state of a symbols directory.
+type LayoutSummary struct {
+	// FileSize is the size of the original file.
+	FileSize uint64 `json:"file_size"`
+
+	// Blocks contains one entry per block, in layout order.
+	Blocks []BlockSummary `json:"blocks"`
+
+	// SourceSymbols is the number of source symbols across all blocks.
+	SourceSymbols int `json:"source_symbols"`
+
+	// RepairSymbols is the number of repair symbols across all blocks.
+	RepairSymbols int `json:"repair_symbols"`
+
+	// RedundancyRatio is RepairSymbols divided by SourceSymbols.
+	RedundancyRatio float64 `json:"redundancy_ratio"`
+
+	// SymbolsDirectory is the directory the symbols were checked in, or empty
+	// if they were not checked.
+	SymbolsDirectory string `json:"symbols_directory,omitempty"`
+
+	// Healthy and Decodable summarize the state of the symbols, as in
+	// VerificationReport. They are only set if SymbolsDirectory is.
+	Healthy   *bool `json:"healthy,omitempty"`
+	Decodable *bool `json:"decodable,omitempty"`
+}
+
+// BlockSummary describes a single block of a LayoutSummary.
+type BlockSummary struct {
+	// BlockID is the identifier of the block.
+	BlockID uint64 `json:"block_id"`
+
+	// Offset and Size locate the block in the original file.
+	Offset uint64 `json:"offset"`
+	Size   uint64 `json:"size"`
+
+	// OTI is the decoded encoder parameters of the block.
+	OTI ObjectTransmissionInfo `json:"oti"`
+
+	// SourceSymbols is the number of source symbols of the block, the minimum
+	// needed to decode it.
+	SourceSymbols int `json:"source_symbols"`
+
+	// RepairSymbols is the number of distinct symbols listed beyond the
+	// source symbols.
+	RepairSymbols int `json:"repair_symbols"`
+
+	// RedundancyRatio is RepairSymbols divided by SourceSymbols.
+	RedundancyRatio float64 `json:"redundancy_ratio"`
+
+	// Hash is the base58-encoded hash of the block data.
+	Hash string `json:"hash"`
+
+	// Health is the state of the symbols of the block, or nil if the symbols
+	// were not checked.
+	Health *BlockHealth `json:"health,omitempty"`
+}
+
+// BlockHealth counts the symbols of a block found in a symbols directory. See
+// BlockVerification for the meaning of each count.
+type BlockHealth struct {
+	Directory  string `json:"directory"`
+	Present    int    `json:"present"`
+	Missing    int    `json:"missing"`
+	Corrupt    int    `json:"corrupt"`
+	Unexpected int    `json:"unexpected"`
+	Duplicate  int    `json:"duplicate"`
+	Healthy    bool   `json:"healthy"`
+	Decodable  bool   `json:"decodable"`
+}
+
+// State returns "healthy", "degraded" (decodable, but symbols are missing,
+// corrupt or unexpected) or "undecodable".
+func (h *BlockHealth) State() string {
+	return healthState(h.Healthy, h.Decodable)
+}
+
+// healthState returns the state of symbols that are healthy and decodable as
+// described by BlockHealth.State.
+func healthState(healthy, decodable bool) string {
+	switch {
+	case !decodable:
+		return "undecodable"
+	case !healthy:
+		return "degraded"
+	}
+	return "healthy"
+}
+
+// InspectLayout summarizes a layout: the blocks with their offsets, sizes and
+// decoded encoder parameters, and the source and repair symbol counts. If
+// symbolsDir is not empty, the symbols are checked with VerifySymbols and the
+// summary includes the presence and health of the symbols of every block.
+//
+// Parameters:
+//   - layout: The layout to summarize.
+//   - symbolsDir: Directory containing the encoded symbols, or "" to only
+//     summarize the layout.
+//
+// Returns:
+//   - *LayoutSummary: The summary of the layout.
+//   - error: An error matching ErrInvalidLayout if the encoder parameters of a
+//     block cannot be decoded, or the errors of VerifySymbols.
+//
+// Example:
+//
+//	layout, err := raptorq.LoadLayout("symbols/_raptorq_layout.json")
+//	if err != nil {
+//	    return err
+//	}
+//	summary, err := raptorq.InspectLayout(layout, "symbols/")
+//	if err != nil {
+//	    return err
+//	}
+//	summary.WriteTable(os.Stdout)
+func InspectLayout(layout *Layout, symbolsDir string) (*LayoutSummary, error) {
+	if layout == nil {
+		return nil, fmt.Errorf("%w: layout cannot be nil", ErrInvalidParameters)
+	}
+
+	summary := &LayoutSummary{FileSize: layout.TotalSize(), Blocks: make([]BlockSummary, len(layout.Blocks))}
+	for i, block := range layout.Blocks {
+		oti, err := ParseOTI(block.EncoderParameters)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", block.BlockID, err)
+		}
+		distinct := make(map[string]struct{}, len(block.Symbols))
+		for _, id := range block.Symbols {
+			distinct[id] = struct{}{}
+		}
+		source := oti.SourceSymbols()
+		repair := len(distinct) - source
+		if repair < 0 {
+			repair = 0
+		}
+
+		summary.Blocks[i] = BlockSummary{
+			BlockID:         block.BlockID,
+			Offset:          block.OriginalOffset,
+			Size:            block.Size,
+			OTI:             oti,
+			SourceSymbols:   source,
+			RepairSymbols:   repair,
+			RedundancyRatio: ratio(repair, source),
+			Hash:            block.Hash,
+		}
+		summary.SourceSymbols += source
+		summary.RepairSymbols += repair
+	}
+	summary.RedundancyRatio = ratio(summary.RepairSymbols, summary.SourceSymbols)
+
+	if symbolsDir == "" {
+		return summary, nil
+	}
+	report, err := VerifySymbols(symbolsDir, layout)
+	if err != nil {
+		return nil, err
+	}
+	healthy, decodable := report.Healthy(), report.Decodable
+	summary.SymbolsDirectory = symbolsDir
+	summary.Healthy, summary.Decodable = &healthy, &decodable
+	for i := range report.Blocks {
+		b := &report.Blocks[i]
+		summary.Blocks[i].Health = &BlockHealth{
+			Directory:  b.Directory,
+			Present:    len(b.Valid),
+			Missing:    len(b.Missing),
+			Corrupt:    len(b.Corrupt),
+			Unexpected: len(b.Unexpected),
+			Duplicate:  len(b.Duplicate),
+			Healthy:    b.Healthy(),
+			Decodable:  b.Decodable,
+		}
+	}
+	return summary, nil
+}
+
+// State returns the state of all symbols, as BlockHealth.State does for the
+// symbols of a block, or "" if the symbols were not checked.
+func (s *LayoutSummary) State() string {
+	if s.Healthy == nil || s.Decodable == nil {
+		return ""
+	}
+	return healthState(*s.Healthy, *s.Decodable)
+}
+
+// ratio returns n/d, or 0 if d is 0.
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+// WriteTable writes the summary as human-readable text: the totals followed by
+// a table with one row per block.
+func (s *LayoutSummary) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "File size:       %d bytes\n", s.FileSize)
+	fmt.Fprintf(w, "Blocks:          %d\n", len(s.Blocks))
+	fmt.Fprintf(w, "Source symbols:  %d\n", s.SourceSymbols)
+	fmt.Fprintf(w, "Repair symbols:  %d\n", s.RepairSymbols)
+	fmt.Fprintf(w, "Redundancy:      %.2f\n", s.RedundancyRatio)
+	if s.SymbolsDirectory != "" {
+		fmt.Fprintf(w, "Symbols:         %s (%s)\n", s.SymbolsDirectory, s.State())
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := "BLOCK\tOFFSET\tSIZE\tSOURCE\tREPAIR\tRATIO\tOTI\t"
+	if s.SymbolsDirectory != "" {
+		header += "PRESENT\tMISSING\tCORRUPT\tSTATE\t"
+	}
+	fmt.Fprintln(tw, header)
+	for i := range s.Blocks {
+		b := &s.Blocks[i]
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\t%.2f\t%s\t", b.BlockID, b.Offset, b.Size,
+			b.SourceSymbols, b.RepairSymbols, b.RedundancyRatio, b.OTI)
+		if h := b.Health; h != nil {
+			fmt.Fprintf(tw, "%d\t%d\t%d\t%s\t", h.Present, h.Missing, h.Corrupt, h.State())
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,87 @@
+package rq_go
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Unit test for summarizing layouts with and without a symbols directory
+func TestInspectLayout(t *testing.T) {
+	dir := t.TempDir()
+	codec := goCodec{config: ProcessorConfig{SymbolSize: 1000, MaxMemoryMB: 256, ConcurrencyLimit: 1}}
+
+	data := make([]byte, 25000)
+	rand.New(rand.NewSource(3)).Read(data)
+	inputPath := filepath.Join(dir, "input.bin")
+	if err := os.WriteFile(inputPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	symbolsDir := filepath.Join(dir, "symbols")
+	layoutPath := filepath.Join(symbolsDir, layoutFileName)
+	if _, err := codec.encode(inputPath, symbolsDir, layoutPath, 10000, true); err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	layout, err := LoadLayout(layoutPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Pretend block 0 has two repair symbols, listing one of them twice
+	layout.Blocks[0].Symbols = append(layout.Blocks[0].Symbols, hashBytes([]byte("r1")), hashBytes([]byte("r2")), hashBytes([]byte("r2")))
+
+	summary, err := InspectLayout(layout, "")
+	if err != nil {
+		t.Fatalf("Failed to inspect layout: %v", err)
+	}
+	if summary.FileSize != 25000 || len(summary.Blocks) != 3 || summary.SourceSymbols != 25 || summary.RepairSymbols != 2 || summary.State() != "" {
+		t.Fatalf("Unexpected summary: %+v", summary)
+	}
+	b := summary.Blocks[2]
+	if b.Offset != 20000 || b.Size != 5000 || b.SourceSymbols != 5 || b.OTI.TransferLength != 5000 || b.OTI.SymbolSize != 1000 {
+		t.Fatalf("Unexpected block summary: %+v", b)
+	}
+	if summary.Blocks[0].RedundancyRatio != 0.2 || summary.Healthy != nil || b.Health != nil {
+		t.Fatalf("Unexpected block summary: %+v", summary.Blocks[0])
+	}
+
+	// With the symbols directory, the made-up repair symbols of block 0 are missing
+	// and the removed source symbol of block 1 makes it undecodable
+	if err := os.Remove(filepath.Join(symbolsDir, blockDirName(1), layout.Blocks[1].Symbols[3])); err != nil {
+		t.Fatal(err)
+	}
+	summary, err = InspectLayout(layout, symbolsDir)
+	if err != nil {
+		t.Fatalf("Failed to inspect symbols: %v", err)
+	}
+	if *summary.Healthy || *summary.Decodable || summary.State() != "undecodable" {
+		t.Fatalf("Expected unhealthy, undecodable symbols: %+v", summary)
+	}
+	states := []string{}
+	for _, b := range summary.Blocks {
+		states = append(states, b.Health.State())
+	}
+	if strings.Join(states, ",") != "degraded,undecodable,healthy" {
+		t.Fatalf("Unexpected block states %v", states)
+	}
+	if h := summary.Blocks[0].Health; h.Present != 10 || h.Missing != 2 {
+		t.Fatalf("Unexpected health of block 0: %+v", h)
+	}
+
+	var buf bytes.Buffer
+	if err := summary.WriteTable(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"25000 bytes", "BLOCK", "PRESENT", "(undecodable)", "F=5000 T=1000 Z=1 N=1 Al=8"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Table does not contain %q:\n%s", want, buf.String())
+		}
+	}
+
+	if _, err := InspectLayout(&Layout{Blocks: []BlockLayout{{EncoderParameters: []uint8{1}}}}, ""); err == nil {
+		t.Fatal("Expected invalid encoder parameters to be rejected")
+	}
+}
@@ -0,0 +1,282 @@
+package rq_go
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// HashSize is the size in bytes of the digests used as symbol IDs and block
+// hashes. They appear in layouts base58-encoded.
+const HashSize = 32
+
+// EncoderParametersSize is the size in bytes of the encoder parameters stored
+// for every block (the RFC 6330 Object Transmission Information).
+const EncoderParametersSize = 12
+
+// Layout is the Go model of the _raptorq_layout.json file written during
+// encoding. It describes how the original file was split into blocks and lists
+// the symbols of every block, and is required to decode the symbols.
+//
+// A Layout can be inspected and validated without invoking the native library.
+type Layout struct {
+	// Blocks contains one entry per block, in block ID order.
+	Blocks []BlockLayout `json:"blocks"`
+}
+
+// BlockLayout describes a single block of a Layout.
+type BlockLayout struct {
+	// BlockID is the identifier of the block (0, 1, 2, ...). Symbols of the block
+	// are stored in the block_<BlockID> subdirectory of the symbols directory.
+	BlockID uint64 `json:"block_id"`
+
+	// EncoderParameters are the 12 bytes needed to initialize the RaptorQ decoder.
+	EncoderParameters []uint8 `json:"encoder_parameters"`
+
+	// OriginalOffset is the offset of the block in the original file.
+	OriginalOffset uint64 `json:"original_offset"`
+
+	// Size is the number of bytes of the original file contained in the block.
+	Size uint64 `json:"size"`
+
+	// Symbols lists the IDs (base58-encoded hashes) of the symbols of the block.
+	Symbols []string `json:"symbols"`
+
+	// Hash is the base58-encoded hash of the block data.
+	Hash string `json:"hash"`
+}
+
+// blockLayoutJSON is the wire format of BlockLayout. Encoder parameters are
+// written as an array of numbers, as the native library does, rather than the
+// base64 string encoding/json uses for byte slices.
+type blockLayoutJSON struct {
+	BlockID           uint64   `json:"block_id"`
+	EncoderParameters []uint16 `json:"encoder_parameters"`
+	OriginalOffset    uint64   `json:"original_offset"`
+	Size              uint64   `json:"size"`
+	Symbols           []string `json:"symbols"`
+	Hash              string   `json:"hash"`
+}
+
+// MarshalJSON implements json.Marshaler using the format of the native library.
+func (b BlockLayout) MarshalJSON() ([]byte, error) {
+	params := make([]uint16, len(b.EncoderParameters))
+	for i, v := range b.EncoderParameters {
+		params[i] = uint16(v)
+	}
+	symbols := b.Symbols
+	if symbols == nil {
+		symbols = []string{}
+	}
+
+	return json.Marshal(blockLayoutJSON{
+		BlockID:           b.BlockID,
+		EncoderParameters: params,
+		OriginalOffset:    b.OriginalOffset,
+		Size:              b.Size,
+		Symbols:           symbols,
+		Hash:              b.Hash,
+	})
+}
+
+// ParseLayout parses the content of a layout file.
+//
+// ParseLayout only checks that data is well-formed JSON; call Validate to check
+// the consistency of the blocks.
+func ParseLayout(data []byte) (*Layout, error) {
+	var layout Layout
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLayout, err)
+	}
+	return &layout, nil
+}
+
+// LoadLayout reads and parses a layout file, such as the _raptorq_layout.json
+// written by EncodeFile or the file written by CreateMetadata.
+//
+// Example:
+//
+//	layout, err := raptorq.LoadLayout("symbols/_raptorq_layout.json")
+//	if err != nil {
+//	    return err
+//	}
+//	if err := layout.Validate(); err != nil {
+//	    return fmt.Errorf("corrupt layout: %w", err)
+//	}
+func LoadLayout(path string) (*Layout, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := ParseLayout(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return layout, nil
+}
+
+// Marshal returns the layout in the indented JSON format of the native library.
+func (l *Layout) Marshal() ([]byte, error) {
+	return json.MarshalIndent(l, "", "  ")
+}
+
+// Save writes the layout to path. The file is replaced atomically, so
+// concurrent readers see either the previous or the new layout.
+func (l *Layout) Save(path string) error {
+	data, err := l.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to serialize layout: %w", err)
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// Block returns the block with the given ID.
+func (l *Layout) Block(blockID uint64) (*BlockLayout, bool) {
+	for i := range l.Blocks {
+		if l.Blocks[i].BlockID == blockID {
+			return &l.Blocks[i], true
+		}
+	}
+	return nil, false
+}
+
+// TotalSize returns the size of the original file, i.e. the end offset of the
+// last block.
+func (l *Layout) TotalSize() uint64 {
+	var total uint64
+	for _, block := range l.Blocks {
+		if end := block.OriginalOffset + block.Size; end > total {
+			total = end
+		}
+	}
+	return total
+}
+
+// TotalSymbols returns the number of symbols listed across all blocks.
+func (l *Layout) TotalSymbols() int {
+	total := 0
+	for _, block := range l.Blocks {
+		total += len(block.Symbols)
+	}
+	return total
+}
+
+// LayoutError describes an inconsistency found in one block of a layout.
+// It unwraps to ErrInvalidLayout.
+type LayoutError struct {
+	// Index is the position of the block in Layout.Blocks.
+	Index int
+
+	// BlockID is the ID recorded for the block.
+	BlockID uint64
+
+	// Field is the JSON name of the offending field.
+	Field string
+
+	// Reason explains what is wrong with the field.
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *LayoutError) Error() string {
+	return fmt.Sprintf("block %d (index %d): %s: %s", e.BlockID, e.Index, e.Field, e.Reason)
+}
+
+// Unwrap returns ErrInvalidLayout.
+func (e *LayoutError) Unwrap() error {
+	return ErrInvalidLayout
+}
+
+// Validate checks the consistency of the layout without touching any symbols.
+//
+// It verifies that:
+//   - the layout has at least one block;
+//   - block IDs are sequential starting at 0;
+//   - blocks are contiguous and non-overlapping, starting at offset 0, so each
+//     offset equals the previous offset plus the previous size;
+//   - every block is non-empty and has 12 bytes of encoder parameters that
+//     satisfy RFC 6330 and whose transfer length matches the block size;
+//   - the block hash and every symbol ID are well-formed base58-encoded hashes,
+//     and no symbol is listed twice within a block.
+//
+// Returns:
+//   - error: nil if the layout is consistent, otherwise the errors of all
+//     problems found joined with errors.Join. Each of them is a *LayoutError
+//     naming the block and field, and all of them match ErrInvalidLayout.
+func (l *Layout) Validate() error {
+	if len(l.Blocks) == 0 {
+		return fmt.Errorf("%w: layout has no blocks", ErrInvalidLayout)
+	}
+
+	var errs []error
+	report := func(i int, field, format string, args ...any) {
+		errs = append(errs, &LayoutError{
+			Index:   i,
+			BlockID: l.Blocks[i].BlockID,
+			Field:   field,
+			Reason:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	var expectedOffset uint64
+	for i, block := range l.Blocks {
+		if block.BlockID != uint64(i) {
+			report(i, "block_id", "expected block ID %d, block IDs must be sequential starting at 0", i)
+		}
+
+		if block.Size == 0 {
+			report(i, "size", "block is empty")
+		}
+
+		for _, problem := range otiProblems(block.EncoderParameters, block.Size) {
+			report(i, "encoder_parameters", "%s", problem)
+		}
+
+		switch {
+		case block.OriginalOffset < expectedOffset:
+			report(i, "original_offset", "offset %d overlaps the previous block, which ends at %d",
+				block.OriginalOffset, expectedOffset)
+		case block.OriginalOffset > expectedOffset:
+			report(i, "original_offset", "offset %d leaves a gap of %d bytes after the previous block",
+				block.OriginalOffset, block.OriginalOffset-expectedOffset)
+		}
+		expectedOffset = block.OriginalOffset + block.Size
+
+		if err := checkHashString(block.Hash); err != nil {
+			report(i, "hash", "%v", err)
+		}
+
+		if len(block.Symbols) == 0 {
+			report(i, "symbols", "block lists no symbols")
+		}
+		seen := make(map[string]int, len(block.Symbols))
+		for j, id := range block.Symbols {
+			if first, dup := seen[id]; dup {
+				report(i, "symbols", "symbol %d (%s) duplicates symbol %d", j, id, first)
+				continue
+			}
+			seen[id] = j
+			if err := checkHashString(id); err != nil {
+				report(i, "symbols", "symbol %d (%q): %v", j, id, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkHashString checks that s is a base58-encoded hash of HashSize bytes.
+func checkHashString(s string) error {
+	if s == "" {
+		return errors.New("empty hash")
+	}
+	decoded, err := base58Decode(s)
+	if err != nil {
+		return err
+	}
+	if len(decoded) != HashSize {
+		return fmt.Errorf("expected a %d-byte hash, got %d bytes", HashSize, len(decoded))
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package rq_go
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testHash returns a well-formed base58 hash derived from seed
+func testHash(seed byte) string {
+	digest := make([]byte, HashSize)
+	for i := range digest {
+		digest[i] = seed + byte(i)
+	}
+	return base58Encode(digest)
+}
+
+// testLayout returns a consistent two-block layout
+func testLayout() *Layout {
+	params := func(size uint64) []uint8 {
+		return ObjectTransmissionInfo{TransferLength: size, SymbolSize: 64, SourceBlocks: 1, SubBlocks: 1, Alignment: 8}.Bytes()
+	}
+	return &Layout{Blocks: []BlockLayout{
+		{
+			BlockID:           0,
+			EncoderParameters: params(1000),
+			OriginalOffset:    0,
+			Size:              1000,
+			Symbols:           []string{testHash(1), testHash(2)},
+			Hash:              testHash(3),
+		},
+		{
+			BlockID:           1,
+			EncoderParameters: params(500),
+			OriginalOffset:    1000,
+			Size:              500,
+			Symbols:           []string{testHash(4)},
+			Hash:              testHash(5),
+		},
+	}}
+}
+
+// Unit test for base58 round trips, including leading zero bytes
+func TestBase58RoundTrip(t *testing.T) {
+	inputs := [][]byte{
+		{},
+		{0},
+		{0, 0, 1, 2, 3},
+		[]byte("hello world"),
+		bytes.Repeat([]byte{0xff}, HashSize),
+	}
+	for _, in := range inputs {
+		out, err := base58Decode(base58Encode(in))
+		if err != nil {
+			t.Fatalf("Failed to decode %x: %v", in, err)
+		}
+		if !bytes.Equal(in, out) {
+			t.Fatalf("Round trip mismatch: %x != %x", in, out)
+		}
+	}
+
+	if got := base58Encode([]byte("hello world")); got != "StV1DL6CwTryKyV" {
+		t.Fatalf("Unexpected encoding %q", got)
+	}
+	if _, err := base58Decode("0OIl"); err == nil {
+		t.Fatal("Expected an error for characters outside the alphabet")
+	}
+}
+
+// Unit test for parsing the layout format written by the native library
+func TestParseLayout(t *testing.T) {
+	data := []byte(`{
+  "blocks": [
+    {
+      "block_id": 0,
+      "encoder_parameters": [0, 0, 25, 240, 160, 0, 195, 80, 1, 0, 1, 8],
+      "original_offset": 0,
+      "size": 1700000,
+      "symbols": ["9yCaAXSexMsaWDP6pzK4wZ4w9Hqrr6QPjJZ86wJMGoq9"],
+      "hash": "9yCaAXSexMsaWDP6pzK4wZ4w9Hqrr6QPjJZ86wJMGoq9"
+    }
+  ]
+}`)
+	layout, err := ParseLayout(data)
+	if err != nil {
+		t.Fatalf("Failed to parse layout: %v", err)
+	}
+	if len(layout.Blocks) != 1 || layout.Blocks[0].EncoderParameters[3] != 240 {
+		t.Fatalf("Unexpected layout: %+v", layout)
+	}
+	if err := layout.Validate(); err != nil {
+		t.Fatalf("Expected a valid layout, got: %v", err)
+	}
+	if layout.TotalSize() != 1700000 || layout.TotalSymbols() != 1 {
+		t.Fatalf("Unexpected totals: size %d, symbols %d", layout.TotalSize(), layout.TotalSymbols())
+	}
+
+	if _, err := ParseLayout([]byte(`{"blocks": [`)); !errors.Is(err, ErrInvalidLayout) {
+		t.Fatalf("Expected ErrInvalidLayout for malformed JSON, got: %v", err)
+	}
+}
+
+// Unit test for saving and loading a layout
+func TestLayoutSaveLoad(t *testing.T) {
+	layout := testLayout()
+	path := filepath.Join(t.TempDir(), layoutFileName)
+	if err := layout.Save(path); err != nil {
+		t.Fatalf("Failed to save layout: %v", err)
+	}
+
+	data, err := layout.Marshal()
+	if err != nil {
+		t.Fatalf("Failed to marshal layout: %v", err)
+	}
+	// Encoder parameters must be written as numbers, not base64
+	if !strings.Contains(string(data), `"encoder_parameters": [`) {
+		t.Fatalf("Encoder parameters not written as an array:\n%s", data)
+	}
+
+	loaded, err := LoadLayout(path)
+	if err != nil {
+		t.Fatalf("Failed to load layout: %v", err)
+	}
+	if len(loaded.Blocks) != 2 || loaded.Blocks[1].OriginalOffset != 1000 ||
+		!bytes.Equal(loaded.Blocks[1].EncoderParameters, layout.Blocks[1].EncoderParameters) ||
+		loaded.Blocks[0].Symbols[1] != layout.Blocks[0].Symbols[1] {
+		t.Fatalf("Loaded layout differs: %+v", loaded)
+	}
+
+	block, ok := loaded.Block(1)
+	if !ok || block.Size != 500 {
+		t.Fatalf("Block(1) = %+v, %v", block, ok)
+	}
+	if _, ok := loaded.Block(2); ok {
+		t.Fatal("Block(2) should not exist")
+	}
+}
+
+// Unit test for the consistency checks of Validate
+func TestLayoutValidate(t *testing.T) {
+	if err := testLayout().Validate(); err != nil {
+		t.Fatalf("Expected a valid layout, got: %v", err)
+	}
+	if err := (&Layout{}).Validate(); !errors.Is(err, ErrInvalidLayout) {
+		t.Fatalf("Expected ErrInvalidLayout for an empty layout, got: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(l *Layout)
+		index  int
+		field  string
+	}{
+		{"block id", func(l *Layout) { l.Blocks[1].BlockID = 5 }, 1, "block_id"},
+		{"gap", func(l *Layout) { l.Blocks[1].OriginalOffset = 1200 }, 1, "original_offset"},
+		{"overlap", func(l *Layout) { l.Blocks[1].OriginalOffset = 900 }, 1, "original_offset"},
+		{"parameters", func(l *Layout) { l.Blocks[0].EncoderParameters = []uint8{1, 2, 3} }, 0, "encoder_parameters"},
+		{"transfer length", func(l *Layout) { l.Blocks[1].Size = 400 }, 1, "encoder_parameters"},
+		{"size", func(l *Layout) { l.Blocks[1].Size = 0 }, 1, "size"},
+		{"no symbols", func(l *Layout) { l.Blocks[1].Symbols = nil }, 1, "symbols"},
+		{"duplicate symbol", func(l *Layout) { l.Blocks[0].Symbols[1] = l.Blocks[0].Symbols[0] }, 0, "symbols"},
+		{"bad symbol", func(l *Layout) { l.Blocks[0].Symbols[0] = "not-base58!" }, 0, "symbols"},
+		{"short hash", func(l *Layout) { l.Blocks[1].Hash = "abc" }, 1, "hash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := testLayout()
+			tt.mutate(layout)
+
+			err := layout.Validate()
+			if !errors.Is(err, ErrInvalidLayout) {
+				t.Fatalf("Expected ErrInvalidLayout, got: %v", err)
+			}
+			var layoutErr *LayoutError
+			if !errors.As(err, &layoutErr) {
+				t.Fatalf("Expected a *LayoutError, got: %T", err)
+			}
+			if layoutErr.Index != tt.index || layoutErr.Field != tt.field {
+				t.Fatalf("Expected block %d field %s, got: %v", tt.index, tt.field, layoutErr)
+			}
+		})
+	}
+
+	// All problems are reported at once
+	layout := testLayout()
+	layout.Blocks[0].Hash = ""
+	layout.Blocks[1].BlockID = 7
+	err := layout.Validate()
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 2 {
+		t.Fatalf("Expected 2 errors, got %d: %v", n, err)
+	}
+}
@@ -0,0 +1,385 @@
+package rq_go
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics receives a measurement of every EncodeFile, CreateMetadata and
+// DecodeSymbols call into the backend, and of the EncodeBytes and DecodeBytes
+// calls into a MemoryBackend. Operations that process a file block by
+// block, such as EncodeFileContext, DecodeRange or an Encoder, make one call
+// per block and are therefore observed per block.
+//
+// Install an implementation with SetMetrics. MetricsRegistry is a
+// dependency-free implementation exposing the Prometheus text format; an
+// adapter for another metrics library implements ObserveOperation and reads
+// the gauges from ActiveSessions when it is scraped.
+//
+// ObserveOperation is called synchronously from the goroutine running the
+// operation, possibly from many goroutines at once, so it must be fast and
+// safe for concurrent use.
+type Metrics interface {
+	ObserveOperation(stats OperationStats)
+}
+
+// OperationStats describes one completed backend call.
+type OperationStats struct {
+	// Op is the operation: "EncodeFile", "CreateMetadata", "DecodeSymbols",
+	// "EncodeBytes" or "DecodeBytes".
+	Op string
+
+	// Duration is the wall time of the call.
+	Duration time.Duration
+
+	// BytesIn is the size of the original data read by EncodeFile,
+	// EncodeBytes and CreateMetadata. It is zero for the decoding operations,
+	// whose decoder reads only as many symbols as it needs and does not report
+	// them.
+	BytesIn uint64
+
+	// BytesOut is the size of the data written: the total size of the symbol
+	// files written by EncodeFile, computed from the symbol count and symbol
+	// size of every block, and of the symbols returned by EncodeBytes, payload
+	// IDs included, and the decoded data for DecodeSymbols and
+	// DecodeBytes. It is zero for CreateMetadata.
+	BytesOut uint64
+
+	// Symbols is the number of symbols generated by EncodeFile and
+	// EncodeBytes.
+	Symbols uint32
+
+	// Code is CodeSuccess, or the error code of the failure, see ErrorCodeOf.
+	Code ErrorCode
+
+	// Err is the error of the call, or nil.
+	Err error
+}
+
+// metrics holds the Metrics installed with SetMetrics.
+var metrics atomic.Pointer[Metrics]
+
+// SetMetrics installs m to observe all processors of the package. nil removes
+// the installed Metrics. Without Metrics, operations are not measured at all.
+//
+// Example:
+//
+//	registry := raptorq.NewMetricsRegistry()
+//	raptorq.SetMetrics(registry)
+//	http.Handle("/metrics", registry)
+func SetMetrics(m Metrics) {
+	if m == nil {
+		metrics.Store(nil)
+		return
+	}
+	metrics.Store(&m)
+}
+
+// loadMetrics returns the installed Metrics, or nil.
+func loadMetrics() Metrics {
+	if m := metrics.Load(); m != nil {
+		return *m
+	}
+	return nil
+}
+
+// observeResult reports an EncodeFile or CreateMetadata call that started at
+// start to the installed Metrics.
+func observeResult(op string, start time.Time, result *ProcessResult, err error) {
+	m := loadMetrics()
+	if m == nil {
+		return
+	}
+	stats := resultStats(op, start, result, err)
+	if err == nil && result != nil && op == "EncodeFile" {
+		stats.BytesOut = symbolBytes(result)
+	}
+	m.ObserveOperation(stats)
+}
+
+// observeEncodeBytes reports an EncodeBytes call that started at start to the
+// installed Metrics.
+func observeEncodeBytes(start time.Time, obj *EncodedObject, err error) {
+	m := loadMetrics()
+	if m == nil {
+		return
+	}
+	var result *ProcessResult
+	if err == nil && obj != nil {
+		result = obj.Result
+	}
+	stats := resultStats("EncodeBytes", start, result, err)
+	if result != nil {
+		for _, symbol := range obj.Symbols {
+			stats.BytesOut += uint64(len(symbol))
+		}
+	}
+	m.ObserveOperation(stats)
+}
+
+// resultStats returns the statistics of an encoding call common to all
+// operations: the input size and, unless only metadata was created, the
+// number of symbols.
+func resultStats(op string, start time.Time, result *ProcessResult, err error) OperationStats {
+	stats := OperationStats{Op: op, Duration: time.Since(start), Code: ErrorCodeOf(err), Err: err}
+	if err == nil && result != nil {
+		for _, b := range result.Blocks {
+			stats.BytesIn += b.Size
+		}
+		if op != "CreateMetadata" {
+			stats.Symbols = result.TotalSymbolsCount
+		}
+	}
+	return stats
+}
+
+// symbolBytes returns the size of the symbol files written for result: every
+// symbol file holds a payload ID followed by a symbol of the symbol size in the
+// encoder parameters of its block.
+func symbolBytes(result *ProcessResult) uint64 {
+	var total uint64
+	for _, block := range result.Blocks {
+		if oti, err := block.OTI(); err == nil {
+			total += uint64(block.SymbolsCount) * (uint64(oti.SymbolSize) + symbolHeaderSize)
+		}
+	}
+	return total
+}
+
+// observeDecode reports a DecodeSymbols or DecodeBytes call of layout, which
+// may be nil if it could not be read, that started at start to the installed
+// Metrics.
+func observeDecode(op string, start time.Time, layout *Layout, err error) {
+	m := loadMetrics()
+	if m == nil {
+		return
+	}
+	stats := OperationStats{Op: op, Duration: time.Since(start), Code: ErrorCodeOf(err), Err: err}
+	if err == nil && layout != nil {
+		stats.BytesOut = layout.TotalSize()
+	}
+	m.ObserveOperation(stats)
+}
+
+// ErrorCodeOf returns the native error code describing err: CodeSuccess for
+// nil, the Code of a *RaptorQError, the code of the sentinel error err
+// matches, CodeInvalidSession for ErrSessionClosed, CodeInvalidParameters for
+// ErrInvalidLayout, and CodeGeneric otherwise.
+func ErrorCodeOf(err error) ErrorCode {
+	if err == nil {
+		return CodeSuccess
+	}
+	var rqErr *RaptorQError
+	if errors.As(err, &rqErr) {
+		return rqErr.Code
+	}
+	switch {
+	case errors.Is(err, ErrSessionClosed):
+		return CodeInvalidSession
+	case errors.Is(err, ErrInvalidLayout):
+		return CodeInvalidParameters
+	}
+	// Sorted, so that an error wrapping several sentinels gets a stable code
+	codes := make([]ErrorCode, 0, len(errorCodes))
+	for code := range errorCodes {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] > codes[j] })
+	for _, code := range codes {
+		if errors.Is(err, errorCodes[code].err) {
+			return code
+		}
+	}
+	return CodeGeneric
+}
+
+// SessionStats describes the live sessions of the package.
+type SessionStats struct {
+	// Active is the number of sessions that have not been freed.
+	Active int `json:"active"`
+
+	// Pooled is the number of active sessions owned by a ProcessorPool.
+	Pooled int `json:"pooled"`
+
+	// MemoryMB is the sum of the configured MaxMemoryMB of the active sessions.
+	MemoryMB uint64 `json:"memory_mb"`
+
+	// PoolBudgetMB is the sum of the MemoryBudgetMB of the pools owning
+	// active sessions.
+	PoolBudgetMB uint64 `json:"pool_budget_mb"`
+}
+
+// ActiveSessions returns a snapshot of the live sessions of all backends.
+func ActiveSessions() SessionStats {
+	var stats SessionStats
+	pools := make(map[*ProcessorPool]bool)
+	sessionMutex.Lock()
+	for _, info := range sessions {
+		stats.Active++
+		stats.MemoryMB += info.config.MaxMemoryMB
+		if info.pool != nil {
+			stats.Pooled++
+			pools[info.pool] = true
+		}
+	}
+	sessionMutex.Unlock()
+
+	for pool := range pools {
+		stats.PoolBudgetMB += pool.cfg.MemoryBudgetMB
+	}
+	return stats
+}
+
+// DefaultDurationBuckets are the upper bounds, in seconds, of the duration
+// histogram of a MetricsRegistry.
+var DefaultDurationBuckets = []float64{0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 300}
+
+// MetricsRegistry is a Metrics implementation accumulating the observations
+// in memory and exposing them, together with ActiveSessions, in the
+// Prometheus text format. It is an http.Handler serving that format, so it
+// can be scraped without a Prometheus client library.
+//
+// The exposed metrics are, labeled by operation where applicable:
+//
+//	rq_operations_total                 Backend calls
+//	rq_operation_failures_total         Failed calls, also labeled by error code
+//	rq_operation_duration_seconds       Histogram of the call durations
+//	rq_operation_bytes_in_total         Original data read
+//	rq_operation_bytes_out_total        Symbol files and decoded data written
+//	rq_symbols_generated_total          Symbols generated
+//	rq_sessions_active                  Live sessions
+//	rq_sessions_pooled                  Live sessions owned by pools
+//	rq_sessions_memory_mb               Configured MaxMemoryMB of the live sessions
+//	rq_pools_memory_budget_mb           Memory budgets of the pools owning live sessions
+type MetricsRegistry struct {
+	buckets []float64
+
+	mu  sync.Mutex
+	ops map[string]*opMetrics
+}
+
+// opMetrics accumulates the observations of one operation.
+type opMetrics struct {
+	calls     uint64
+	failures  map[ErrorCode]uint64
+	counts    []uint64 // per bucket, not cumulative; the last entry is +Inf
+	sum       float64
+	bytesIn   uint64
+	bytesOut  uint64
+	generated uint64
+}
+
+// NewMetricsRegistry returns an empty MetricsRegistry using
+// DefaultDurationBuckets.
+func NewMetricsRegistry() *MetricsRegistry {
+	return &MetricsRegistry{buckets: DefaultDurationBuckets, ops: make(map[string]*opMetrics)}
+}
+
+// ObserveOperation implements Metrics.
+func (r *MetricsRegistry) ObserveOperation(stats OperationStats) {
+	seconds := stats.Duration.Seconds()
+	bucket := sort.SearchFloat64s(r.buckets, seconds)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	op := r.ops[stats.Op]
+	if op == nil {
+		op = &opMetrics{failures: make(map[ErrorCode]uint64), counts: make([]uint64, len(r.buckets)+1)}
+		r.ops[stats.Op] = op
+	}
+	op.calls++
+	if stats.Code != CodeSuccess {
+		op.failures[stats.Code]++
+	}
+	op.counts[bucket]++
+	op.sum += seconds
+	op.bytesIn += stats.BytesIn
+	op.bytesOut += stats.BytesOut
+	op.generated += uint64(stats.Symbols)
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format.
+func (r *MetricsRegistry) WritePrometheus(w io.Writer) error {
+	var b strings.Builder
+
+	r.mu.Lock()
+	names := make([]string, 0, len(r.ops))
+	for name := range r.ops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	counter := func(metric, help string, value func(*opMetrics) uint64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", metric, help, metric)
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s{op=%q} %d\n", metric, name, value(r.ops[name]))
+		}
+	}
+	counter("rq_operations_total", "Number of RaptorQ backend calls.", func(m *opMetrics) uint64 { return m.calls })
+
+	fmt.Fprintf(&b, "# HELP rq_operation_failures_total Number of failed RaptorQ backend calls by error code.\n")
+	fmt.Fprintf(&b, "# TYPE rq_operation_failures_total counter\n")
+	for _, name := range names {
+		op := r.ops[name]
+		codes := make([]ErrorCode, 0, len(op.failures))
+		for code := range op.failures {
+			codes = append(codes, code)
+		}
+		sort.Slice(codes, func(i, j int) bool { return codes[i] > codes[j] })
+		for _, code := range codes {
+			fmt.Fprintf(&b, "rq_operation_failures_total{op=%q,code=\"%d\",error=%q} %d\n",
+				name, int32(code), code.String(), op.failures[code])
+		}
+	}
+
+	fmt.Fprintf(&b, "# HELP rq_operation_duration_seconds Duration of RaptorQ backend calls.\n")
+	fmt.Fprintf(&b, "# TYPE rq_operation_duration_seconds histogram\n")
+	for _, name := range names {
+		op := r.ops[name]
+		var cumulative uint64
+		for i, bound := range r.buckets {
+			cumulative += op.counts[i]
+			fmt.Fprintf(&b, "rq_operation_duration_seconds_bucket{op=%q,le=\"%g\"} %d\n", name, bound, cumulative)
+		}
+		fmt.Fprintf(&b, "rq_operation_duration_seconds_bucket{op=%q,le=\"+Inf\"} %d\n", name, op.calls)
+		fmt.Fprintf(&b, "rq_operation_duration_seconds_sum{op=%q} %g\n", name, op.sum)
+		fmt.Fprintf(&b, "rq_operation_duration_seconds_count{op=%q} %d\n", name, op.calls)
+	}
+
+	counter("rq_operation_bytes_in_total", "Bytes of original data read by RaptorQ backend calls.", func(m *opMetrics) uint64 { return m.bytesIn })
+	counter("rq_operation_bytes_out_total", "Bytes of symbols and decoded data written by RaptorQ backend calls.", func(m *opMetrics) uint64 { return m.bytesOut })
+	var generated uint64
+	for _, name := range names {
+		generated += r.ops[name].generated
+	}
+	r.mu.Unlock()
+
+	fmt.Fprintf(&b, "# HELP rq_symbols_generated_total Number of symbols generated.\n")
+	fmt.Fprintf(&b, "# TYPE rq_symbols_generated_total counter\n")
+	fmt.Fprintf(&b, "rq_symbols_generated_total %d\n", generated)
+
+	sessions := ActiveSessions()
+	gauge := func(metric, help string, value uint64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", metric, help, metric, metric, value)
+	}
+	gauge("rq_sessions_active", "Number of live RaptorQ sessions.", uint64(sessions.Active))
+	gauge("rq_sessions_pooled", "Number of live RaptorQ sessions owned by pools.", uint64(sessions.Pooled))
+	gauge("rq_sessions_memory_mb", "Sum of the configured memory limits of the live sessions in MB.", sessions.MemoryMB)
+	gauge("rq_pools_memory_budget_mb", "Sum of the memory budgets of the pools owning live sessions in MB.", sessions.PoolBudgetMB)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (r *MetricsRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WritePrometheus(w)
+}
@@ -0,0 +1,205 @@
+package rq_go
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Unit test for mapping errors to native codes
+func TestErrorCodeOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		code ErrorCode
+	}{
+		{nil, CodeSuccess},
+		{newRaptorQError("DecodeSymbols", CodeMemoryLimit, nil), CodeMemoryLimit},
+		{fmt.Errorf("block 1: %w", ErrInsufficientSymbols), CodeDecodingFailed},
+		{ErrSymbolNotFound, CodeFileNotFound},
+		{ErrSessionClosed, CodeInvalidSession},
+		{&LayoutError{Field: "hash", Reason: "empty"}, CodeInvalidParameters},
+		{errors.New("other"), CodeGeneric},
+	}
+	for _, tt := range tests {
+		if got := ErrorCodeOf(tt.err); got != tt.code {
+			t.Errorf("ErrorCodeOf(%v) = %d; expected %d", tt.err, got, tt.code)
+		}
+	}
+}
+
+// Unit test for the Prometheus exposition of a registry
+func TestMetricsRegistry(t *testing.T) {
+	r := NewMetricsRegistry()
+	r.ObserveOperation(OperationStats{Op: "EncodeFile", Duration: 30 * time.Millisecond, BytesIn: 100, BytesOut: 400, Symbols: 4})
+	r.ObserveOperation(OperationStats{Op: "EncodeFile", Duration: 2 * time.Second, Code: CodeMemoryLimit})
+	r.ObserveOperation(OperationStats{Op: "DecodeSymbols", Duration: time.Millisecond, BytesOut: 100})
+
+	var b strings.Builder
+	if err := r.WritePrometheus(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, line := range []string{
+		`rq_operations_total{op="EncodeFile"} 2`,
+		`rq_operations_total{op="DecodeSymbols"} 1`,
+		`rq_operation_failures_total{op="EncodeFile",code="-16",error="memory limit exceeded"} 1`,
+		`rq_operation_duration_seconds_bucket{op="EncodeFile",le="0.01"} 0`,
+		`rq_operation_duration_seconds_bucket{op="EncodeFile",le="0.05"} 1`,
+		`rq_operation_duration_seconds_bucket{op="EncodeFile",le="2.5"} 2`,
+		`rq_operation_duration_seconds_bucket{op="EncodeFile",le="+Inf"} 2`,
+		`rq_operation_duration_seconds_count{op="DecodeSymbols"} 1`,
+		`rq_operation_bytes_in_total{op="EncodeFile"} 100`,
+		`rq_operation_bytes_out_total{op="DecodeSymbols"} 100`,
+		`rq_symbols_generated_total 4`,
+		`# TYPE rq_sessions_active gauge`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Missing %q in:\n%s", line, out)
+		}
+	}
+}
+
+// System test measuring encoding and decoding (2MB, 1MB blocks)
+func TestSysMetrics(t *testing.T) {
+	registry := NewMetricsRegistry()
+	SetMetrics(registry)
+	defer SetMetrics(nil)
+
+	processor, err := NewDefaultRaptorQProcessor()
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+	if stats := ActiveSessions(); stats.Active == 0 || stats.MemoryMB < DefaultMaxMemoryMB {
+		t.Errorf("Expected the session to be counted, got %+v", stats)
+	}
+
+	ctx := NewTestContext(t, 2*1024*1024+100)
+	defer ctx.Cleanup()
+	res, err := processor.EncodeFile(ctx.InputFile, ctx.SymbolsDir, 1024*1024)
+	if err != nil {
+		t.Fatalf("Failed to encode file: %v", err)
+	}
+	if err := processor.DecodeSymbols(ctx.SymbolsDir, ctx.OutputFile, res.LayoutFilePath); err != nil {
+		t.Fatalf("Failed to decode symbols: %v", err)
+	}
+
+	// Remove every symbol of block 0 so that decoding fails
+	layout, err := LoadLayout(res.LayoutFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	written := symbolFilesSize(t, ctx.SymbolsDir, res.LayoutFilePath)
+	for _, id := range layout.Blocks[0].Symbols {
+		os.Remove(filepath.Join(ctx.SymbolsDir, blockDirName(0), id))
+	}
+	err = processor.DecodeSymbols(ctx.SymbolsDir, ctx.OutputFile, res.LayoutFilePath)
+	if err == nil {
+		t.Fatal("Expected decoding to fail without symbols")
+	}
+	code := ErrorCodeOf(err)
+
+	var b strings.Builder
+	if err := registry.WritePrometheus(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, line := range []string{
+		`rq_operations_total{op="EncodeFile"} 1`,
+		`rq_operations_total{op="DecodeSymbols"} 2`,
+		fmt.Sprintf(`rq_operation_failures_total{op="DecodeSymbols",code="%d",error=%q} 1`, int32(code), code.String()),
+		fmt.Sprintf(`rq_operation_bytes_in_total{op="EncodeFile"} %d`, 2*1024*1024+100),
+		fmt.Sprintf(`rq_operation_bytes_out_total{op="EncodeFile"} %d`, written),
+		fmt.Sprintf(`rq_operation_bytes_out_total{op="DecodeSymbols"} %d`, 2*1024*1024+100),
+		fmt.Sprintf(`rq_symbols_generated_total %d`, res.TotalSymbolsCount),
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Missing %q in:\n%s", line, out)
+		}
+	}
+}
+
+// symbolFilesSize returns the total size of the files under symbolsDir other
+// than the layout file
+func symbolFilesSize(t *testing.T, symbolsDir, layoutPath string) uint64 {
+	t.Helper()
+	var size uint64
+	err := filepath.WalkDir(symbolsDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path == layoutPath {
+			return err
+		}
+		info, err := d.Info()
+		if err == nil {
+			size += uint64(info.Size())
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return size
+}
+
+// metricsRecorder records the observed operations
+type metricsRecorder struct {
+	stats []OperationStats
+}
+
+func (r *metricsRecorder) ObserveOperation(stats OperationStats) {
+	r.stats = append(r.stats, stats)
+}
+
+// Unit test for measuring the symbols actually written
+func TestMetricsSymbolBytes(t *testing.T) {
+	recorder := &metricsRecorder{}
+	SetMetrics(recorder)
+	defer SetMetrics(nil)
+
+	cfg := DefaultProcessorConfig()
+	cfg.RedundancyFactor = 0
+	processor, err := newProcessor(NewPureGoBackend(), cfg)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	// Every symbol file holds a payload ID and a full symbol, so the bytes
+	// computed from the result match the files on disk
+	ctx := NewTestContext(t, 300*1024+100)
+	defer ctx.Cleanup()
+	res, err := processor.EncodeFile(ctx.InputFile, ctx.SymbolsDir, 100*1024)
+	if err != nil {
+		t.Fatalf("Failed to encode file: %v", err)
+	}
+	written := symbolFilesSize(t, ctx.SymbolsDir, res.LayoutFilePath)
+
+	data, err := os.ReadFile(ctx.InputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj, err := processor.EncodeBytes(data, 100*1024)
+	if err != nil {
+		t.Fatalf("Failed to encode bytes: %v", err)
+	}
+	var returned uint64
+	for _, symbol := range obj.Symbols {
+		returned += uint64(len(symbol))
+	}
+
+	if len(recorder.stats) != 2 {
+		t.Fatalf("Expected 2 operations, got %+v", recorder.stats)
+	}
+	for i, want := range []OperationStats{
+		{Op: "EncodeFile", BytesIn: 300*1024 + 100, BytesOut: written, Symbols: res.TotalSymbolsCount},
+		{Op: "EncodeBytes", BytesIn: 300*1024 + 100, BytesOut: returned, Symbols: obj.Result.TotalSymbolsCount},
+	} {
+		got := recorder.stats[i]
+		if got.Op != want.Op || got.BytesIn != want.BytesIn || got.BytesOut != want.BytesOut || got.Symbols != want.Symbols {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+//go:build cgo
+
+package rq_go
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/include
+
+// Platform-specific LDFLAGS for static linking
+#cgo linux LDFLAGS: -L${SRCDIR}/lib/linux/amd64 -Wl,-Bstatic -lrq_library -Wl,-Bdynamic -ldl -lm -lpthread
+#cgo darwin LDFLAGS: -L${SRCDIR}/lib/darwin/amd64 -lrq_library -framework Security -framework CoreFoundation -lm
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/lib/darwin/arm64 -lrq_library -framework Security -framework CoreFoundation -lm
+#cgo windows LDFLAGS: -L${SRCDIR}/lib/windows/amd64 -lrq_library -lws2_32 -luserenv -ladvapi32
+
+#include <stdlib.h>
+#include <stdint.h>
+#include <stdbool.h>
+#include <rq-library.h>
+
+// Function declarations matching the Rust library's exported functions
+extern uintptr_t raptorq_init_session(uint16_t symbol_size, uint8_t redundancy_factor, uint64_t max_memory_mb, uint64_t concurrency_limit);
+extern _Bool raptorq_free_session(uintptr_t session_id);
+extern int32_t raptorq_encode_file(uintptr_t session_id, const char *input_path, const char *output_dir, uintptr_t block_size, char *result_buffer, uintptr_t result_buffer_len);
+extern int32_t raptorq_create_metadata(uintptr_t session_id, const char *input_path, const char *layout_file, uintptr_t block_size, char *result_buffer, uintptr_t result_buffer_len);
+extern int32_t raptorq_get_last_error(uintptr_t session_id, char *error_buffer, uintptr_t error_buffer_len);
+extern int32_t raptorq_decode_symbols(uintptr_t session_id, const char *symbols_dir, const char *output_path, const char *layout_path);
+extern uintptr_t raptorq_get_recommended_block_size(uintptr_t session_id, uint64_t file_size);
+extern int32_t raptorq_version(char *version_buffer, uintptr_t version_buffer_len);
+*/
+import "C"
+import (
+	"encoding/json"
+	"unsafe"
+)
+
+// nativeLibraryLinked reports whether the build links the native library.
+const nativeLibraryLinked = true
+
+// defaultBackend is the backend of processors created without an explicit
+// one. Builds with cgo use the native library.
+var defaultBackend Backend = nativeBackend{}
+
+// nativeBackend implements Backend with the native RaptorQ library.
+type nativeBackend struct{}
+
+// NativeBackend returns the Backend calling the native RaptorQ library. It is
+// the default backend of builds with cgo and is not available in builds without it.
+func NativeBackend() Backend {
+	return nativeBackend{}
+}
+
+// resultBufSize is the size of the buffer receiving the JSON result of an
+// encode or metadata call (16KB should be enough for metadata).
+const resultBufSize = 16 * 1024
+
+// parseResult parses the JSON result written by an encode or metadata call.
+func parseResult(resultBuf *C.char) (*ProcessResult, ErrorCode) {
+	var result ProcessResult
+	if err := json.Unmarshal([]byte(C.GoString(resultBuf)), &result); err != nil {
+		return nil, CodeInvalidResponse
+	}
+	return &result, CodeSuccess
+}
+
+// InitSession implements Backend.
+func (nativeBackend) InitSession(cfg ProcessorConfig) uintptr {
+	return uintptr(C.raptorq_init_session(
+		C.uint16_t(cfg.SymbolSize),
+		C.uint8_t(cfg.RedundancyFactor),
+		C.uint64_t(cfg.MaxMemoryMB),
+		C.uint64_t(cfg.ConcurrencyLimit),
+	))
+}
+
+// FreeSession implements Backend.
+func (nativeBackend) FreeSession(sessionID uintptr) bool {
+	return bool(C.raptorq_free_session(C.uintptr_t(sessionID)))
+}
+
+// EncodeFile implements Backend.
+func (nativeBackend) EncodeFile(sessionID uintptr, inputPath, outputDir string, blockSize int) (*ProcessResult, ErrorCode) {
+	cInputPath := C.CString(inputPath)
+	defer C.free(unsafe.Pointer(cInputPath))
+
+	cOutputDir := C.CString(outputDir)
+	defer C.free(unsafe.Pointer(cOutputDir))
+
+	resultBuf := (*C.char)(C.malloc(C.size_t(resultBufSize)))
+	defer C.free(unsafe.Pointer(resultBuf))
+
+	res := C.raptorq_encode_file(
+		C.uintptr_t(sessionID),
+		cInputPath,
+		cOutputDir,
+		C.uintptr_t(blockSize),
+		resultBuf,
+		C.uintptr_t(resultBufSize),
+	)
+	if res != 0 {
+		return nil, ErrorCode(res)
+	}
+	return parseResult(resultBuf)
+}
+
+// CreateMetadata implements Backend.
+func (nativeBackend) CreateMetadata(sessionID uintptr, inputPath, layoutFile string, blockSize int) (*ProcessResult, ErrorCode) {
+	cInputPath := C.CString(inputPath)
+	defer C.free(unsafe.Pointer(cInputPath))
+
+	cLayoutFile := C.CString(layoutFile)
+	defer C.free(unsafe.Pointer(cLayoutFile))
+
+	resultBuf := (*C.char)(C.malloc(C.size_t(resultBufSize)))
+	defer C.free(unsafe.Pointer(resultBuf))
+
+	res := C.raptorq_create_metadata(
+		C.uintptr_t(sessionID),
+		cInputPath,
+		cLayoutFile,
+		C.uintptr_t(blockSize),
+		resultBuf,
+		C.uintptr_t(resultBufSize),
+	)
+	if res != 0 {
+		return nil, ErrorCode(res)
+	}
+	return parseResult(resultBuf)
+}
+
+// DecodeSymbols implements Backend.
+func (nativeBackend) DecodeSymbols(sessionID uintptr, symbolsDir, outputPath, layoutPath string) ErrorCode {
+	cSymbolsDir := C.CString(symbolsDir)
+	defer C.free(unsafe.Pointer(cSymbolsDir))
+
+	cOutputPath := C.CString(outputPath)
+	defer C.free(unsafe.Pointer(cOutputPath))
+
+	cLayoutPath := C.CString(layoutPath)
+	defer C.free(unsafe.Pointer(cLayoutPath))
+
+	return ErrorCode(C.raptorq_decode_symbols(
+		C.uintptr_t(sessionID),
+		cSymbolsDir,
+		cOutputPath,
+		cLayoutPath,
+	))
+}
+
+// RecommendedBlockSize implements Backend.
+func (nativeBackend) RecommendedBlockSize(sessionID uintptr, fileSize uint64) int {
+	return int(C.raptorq_get_recommended_block_size(
+		C.uintptr_t(sessionID),
+		C.uint64_t(fileSize),
+	))
+}
+
+// LastError implements Backend.
+func (nativeBackend) LastError(sessionID uintptr) (string, bool) {
+	bufSize := 1024
+	errorBuf := (*C.char)(C.malloc(C.size_t(bufSize)))
+	defer C.free(unsafe.Pointer(errorBuf))
+
+	result := C.raptorq_get_last_error(
+		C.uintptr_t(sessionID),
+		errorBuf,
+		C.size_t(bufSize),
+	)
+	if result != 0 {
+		return "", false
+	}
+	return C.GoString(errorBuf), true
+}
+
+// Version implements Backend.
+func (nativeBackend) Version() (string, bool) {
+	bufSize := 128
+	versionBuf := (*C.char)(C.malloc(C.size_t(bufSize)))
+	defer C.free(unsafe.Pointer(versionBuf))
+
+	if res := C.raptorq_version(versionBuf, C.size_t(bufSize)); res != 0 {
+		return "", false
+	}
+	return C.GoString(versionBuf), true
+}
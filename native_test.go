@@ -0,0 +1,149 @@
+//go:build cgo
+
+package rq_go
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// repairSymbolsSupported reports whether the build generates and decodes
+// repair symbols, which the native library does.
+const repairSymbolsSupported = true
+
+// System test checking that the native library and the pure-Go codec decode each other's output (3MB, 1MB blocks)
+func TestSysCrossImplementation(t *testing.T) {
+	processor, err := NewDefaultRaptorQProcessor()
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	// The pure-Go backend rejects the redundancy it cannot produce
+	goRedundant, err := NewBackendProcessor(NewPureGoBackend(), processor.Config())
+	if err != nil {
+		t.Fatalf("Failed to create pure-Go processor: %v", err)
+	}
+	defer goRedundant.Free()
+	cfg := processor.Config()
+	cfg.RedundancyFactor = 0
+	goProcessor, err := newProcessor(goRedundant.Backend(), cfg)
+	if err != nil {
+		t.Fatalf("Failed to create pure-Go processor: %v", err)
+	}
+	defer goProcessor.Free()
+
+	ctx := NewTestContext(t, 3*1024*1024+100)
+	defer ctx.Cleanup()
+
+	blockSize := 1024 * 1024
+	res, err := processor.EncodeFile(ctx.InputFile, ctx.SymbolsDir, blockSize)
+	if err != nil {
+		t.Fatalf("Failed to encode file natively: %v", err)
+	}
+	nativeLayout, err := LoadLayout(res.LayoutFilePath)
+	if err != nil {
+		t.Fatalf("Failed to load native layout: %v", err)
+	}
+
+	goDir := filepath.Join(ctx.TempDir, "go-symbols")
+	if _, err := goRedundant.EncodeFile(ctx.InputFile, goDir, blockSize); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters encoding with repair symbols in Go, got: %v", err)
+	}
+	goRes, err := goProcessor.EncodeFile(ctx.InputFile, goDir, blockSize)
+	if err != nil {
+		t.Fatalf("Failed to encode file in Go: %v", err)
+	}
+	goLayout, err := LoadLayout(goRes.LayoutFilePath)
+	if err != nil {
+		t.Fatalf("Failed to load Go layout: %v", err)
+	}
+
+	// Both layouts describe the same blocks, and the full Go symbol set of each
+	// block is exactly the set of native source symbols, byte for byte
+	if len(goLayout.Blocks) != len(nativeLayout.Blocks) {
+		t.Fatalf("Expected %d blocks, got %d", len(nativeLayout.Blocks), len(goLayout.Blocks))
+	}
+	for i, goBlock := range goLayout.Blocks {
+		nativeBlock := nativeLayout.Blocks[i]
+		if goBlock.Hash != nativeBlock.Hash || goBlock.Size != nativeBlock.Size ||
+			goBlock.OriginalOffset != nativeBlock.OriginalOffset ||
+			string(goBlock.EncoderParameters) != string(nativeBlock.EncoderParameters) {
+			t.Fatalf("Block %d differs:\n go:     %+v\n native: %+v", i, goBlock, nativeBlock)
+		}
+		oti, err := nativeBlock.OTI()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		nativeFiles := make(map[string][]byte)
+		if err := readBlockSymbols(ctx.SymbolsDir, nativeBlock.BlockID, nativeFiles); err != nil {
+			t.Fatal(err)
+		}
+		goFiles := make(map[string][]byte)
+		if err := readBlockSymbols(goDir, goBlock.BlockID, goFiles); err != nil {
+			t.Fatal(err)
+		}
+
+		nativeSource := make(map[string][]byte)
+		for id, data := range nativeFiles {
+			sbn, esi := int(data[0]), int(payloadID(data)&0xffffff)
+			if esi < oti.SourceBlockSymbols(sbn) {
+				nativeSource[id] = data
+			}
+		}
+		if len(nativeSource) != oti.SourceSymbols() || len(nativeFiles) <= len(nativeSource) {
+			t.Fatalf("Block %d: expected %d native source symbols and some repair symbols, got %d of %d",
+				i, oti.SourceSymbols(), len(nativeSource), len(nativeFiles))
+		}
+		if len(goFiles) != len(nativeSource) {
+			t.Fatalf("Block %d: expected %d Go symbols, got %d", i, len(nativeSource), len(goFiles))
+		}
+		for id, data := range goFiles {
+			if string(nativeSource[id]) != string(data) {
+				t.Fatalf("Block %d: Go symbol %s is not a native source symbol", i, id)
+			}
+		}
+	}
+
+	// Native symbols decoded by Go
+	if err := goProcessor.DecodeSymbols(ctx.SymbolsDir, ctx.OutputFile, res.LayoutFilePath); err != nil {
+		t.Fatalf("Failed to decode native symbols in Go: %v", err)
+	}
+	if !ctx.VerifyFilesMatch(t) {
+		t.Fatal("Go decoding of native symbols does not match the original")
+	}
+
+	// Go symbols decoded natively
+	if err := os.Remove(ctx.OutputFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.DecodeSymbols(goDir, ctx.OutputFile, goRes.LayoutFilePath); err != nil {
+		t.Fatalf("Failed to decode Go symbols natively: %v", err)
+	}
+	if !ctx.VerifyFilesMatch(t) {
+		t.Fatal("Native decoding of Go symbols does not match the original")
+	}
+
+	// Without a source symbol, only the native library recovers the block from
+	// its repair symbols
+	lost := filepath.Join(ctx.SymbolsDir, blockDirName(1), goLayout.Blocks[1].Symbols[0])
+	if err := os.Remove(lost); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(ctx.OutputFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.DecodeSymbols(ctx.SymbolsDir, ctx.OutputFile, res.LayoutFilePath); err != nil {
+		t.Fatalf("Failed to decode natively without a source symbol: %v", err)
+	}
+	if !ctx.VerifyFilesMatch(t) {
+		t.Fatal("Native decoding without a source symbol does not match the original")
+	}
+	err = goProcessor.DecodeSymbols(ctx.SymbolsDir, filepath.Join(ctx.TempDir, "go-output"), res.LayoutFilePath)
+	if !errors.Is(err, ErrInsufficientSymbols) {
+		t.Fatalf("Expected ErrInsufficientSymbols decoding without a source symbol in Go, got: %v", err)
+	}
+}
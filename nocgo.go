@@ -0,0 +1,53 @@
+//go:build !cgo
+
+package rq_go
+
+// Builds without cgo cannot link the native library. The package still
+// compiles, so that code depending on it can be built and cross-compiled, but
+// processors created with the default backend fail with
+// ErrNativeLibraryUnavailable.
+//
+// The pure-Go backend (see NewPureGoBackend) is not a replacement for the
+// native library: it only handles the source symbols of RaptorQ, so it never
+// serves as the default backend and has to be selected explicitly.
+
+// nativeLibraryLinked reports whether the build links the native library.
+const nativeLibraryLinked = false
+
+// defaultBackend is the backend of processors created without an explicit
+// one. Builds without cgo have no native library to call.
+var defaultBackend Backend = noNativeBackend{}
+
+// noNativeBackend is the default backend of builds without cgo. Every
+// operation fails, and no session can be created.
+type noNativeBackend struct{}
+
+// InitSession implements Backend.
+func (noNativeBackend) InitSession(ProcessorConfig) uintptr { return 0 }
+
+// FreeSession implements Backend.
+func (noNativeBackend) FreeSession(uintptr) bool { return false }
+
+// EncodeFile implements Backend.
+func (noNativeBackend) EncodeFile(uintptr, string, string, int) (*ProcessResult, ErrorCode) {
+	return nil, CodeInvalidSession
+}
+
+// CreateMetadata implements Backend.
+func (noNativeBackend) CreateMetadata(uintptr, string, string, int) (*ProcessResult, ErrorCode) {
+	return nil, CodeInvalidSession
+}
+
+// DecodeSymbols implements Backend.
+func (noNativeBackend) DecodeSymbols(uintptr, string, string, string) ErrorCode {
+	return CodeInvalidSession
+}
+
+// RecommendedBlockSize implements Backend.
+func (noNativeBackend) RecommendedBlockSize(uintptr, uint64) int { return 0 }
+
+// LastError implements Backend.
+func (noNativeBackend) LastError(uintptr) (string, bool) { return "", false }
+
+// Version implements Backend.
+func (noNativeBackend) Version() (string, bool) { return "", false }
@@ -0,0 +1,35 @@
+//go:build !cgo
+
+package rq_go
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"testing"
+)
+
+// repairSymbolsSupported reports whether the build generates and decodes
+// repair symbols. Builds without cgo have no native library to do so.
+const repairSymbolsSupported = false
+
+// TestMain skips the system tests, which need the native library that builds
+// without cgo do not link.
+func TestMain(m *testing.M) {
+	flag.Parse()
+	flag.Set("test.skip", "^TestSys")
+	os.Exit(m.Run())
+}
+
+// Unit test for the default backend of builds without cgo
+func TestNoNativeLibrary(t *testing.T) {
+	if _, err := NewDefaultRaptorQProcessor(); !errors.Is(err, ErrNativeLibraryUnavailable) {
+		t.Fatalf("Expected ErrNativeLibraryUnavailable, got: %v", err)
+	}
+	if _, err := NewBackendProcessor(nil, DefaultProcessorConfig()); !errors.Is(err, ErrNativeLibraryUnavailable) {
+		t.Fatalf("Expected ErrNativeLibraryUnavailable, got: %v", err)
+	}
+	if version := GetVersion(); version != "Unknown version" {
+		t.Fatalf("Expected an unknown version, got %q", version)
+	}
+}
@@ -0,0 +1,296 @@
+package rq_go
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Limits defined by RFC 6330 for the Object Transmission Information.
+const (
+	// MaxTransferLength is the largest object size RFC 6330 can encode.
+	MaxTransferLength uint64 = 946270874880
+
+	// MaxSourceSymbolsPerBlock is the largest number of source symbols (K'max)
+	// a single RFC 6330 source block may contain.
+	MaxSourceSymbolsPerBlock = 56403
+
+	// MaxSourceBlocks is the largest number of source blocks (Z) an object may
+	// be partitioned into, as Z is an 8-bit field of the OTI.
+	MaxSourceBlocks = 255
+)
+
+// ObjectTransmissionInfo is the decoded form of the 12-byte encoder parameters
+// stored with every block (Block.EncoderParameters and
+// BlockLayout.EncoderParameters).
+//
+// The parameters are the RFC 6330 Object Transmission Information: the common
+// FEC OTI (transfer length, a reserved byte and the symbol size) followed by
+// the scheme-specific OTI (number of source blocks, number of sub-blocks and
+// symbol alignment), all in network byte order:
+//
+//	| F (40 bits) | reserved (8 bits) | T (16 bits) | Z (8 bits) | N (16 bits) | Al (8 bits) |
+type ObjectTransmissionInfo struct {
+	// TransferLength (F) is the size in bytes of the encoded object. Blocks are
+	// encoded independently, so this is the size of the block.
+	TransferLength uint64 `json:"transfer_length"`
+
+	// SymbolSize (T) is the size of each symbol in bytes.
+	SymbolSize uint16 `json:"symbol_size"`
+
+	// SourceBlocks (Z) is the number of RFC 6330 source blocks the object is
+	// partitioned into.
+	SourceBlocks uint8 `json:"source_blocks"`
+
+	// SubBlocks (N) is the number of sub-blocks of each source block.
+	SubBlocks uint16 `json:"sub_blocks"`
+
+	// Alignment (Al) is the symbol alignment in bytes.
+	Alignment uint8 `json:"alignment"`
+}
+
+// ParseOTI decodes 12 bytes of encoder parameters.
+//
+// ParseOTI only checks the length of params; call Validate to check the values
+// against the RFC 6330 constraints.
+//
+// Parameters:
+//   - params: The encoder parameters of a block.
+//
+// Returns:
+//   - ObjectTransmissionInfo: The decoded parameters.
+//   - error: An error matching ErrInvalidLayout if params is not 12 bytes long.
+func ParseOTI(params []uint8) (ObjectTransmissionInfo, error) {
+	if len(params) != EncoderParametersSize {
+		return ObjectTransmissionInfo{}, fmt.Errorf("%w: encoder parameters must be %d bytes, got %d",
+			ErrInvalidLayout, EncoderParametersSize, len(params))
+	}
+
+	return ObjectTransmissionInfo{
+		TransferLength: uint64(params[0])<<32 | uint64(params[1])<<24 | uint64(params[2])<<16 |
+			uint64(params[3])<<8 | uint64(params[4]),
+		SymbolSize:   uint16(params[6])<<8 | uint16(params[7]),
+		SourceBlocks: params[8],
+		SubBlocks:    uint16(params[9])<<8 | uint16(params[10]),
+		Alignment:    params[11],
+	}, nil
+}
+
+// Bytes serializes the parameters into the 12-byte encoder parameters format.
+// The transfer length is truncated to 40 bits.
+func (o ObjectTransmissionInfo) Bytes() []uint8 {
+	return []uint8{
+		uint8(o.TransferLength >> 32),
+		uint8(o.TransferLength >> 24),
+		uint8(o.TransferLength >> 16),
+		uint8(o.TransferLength >> 8),
+		uint8(o.TransferLength),
+		0,
+		uint8(o.SymbolSize >> 8),
+		uint8(o.SymbolSize),
+		o.SourceBlocks,
+		uint8(o.SubBlocks >> 8),
+		uint8(o.SubBlocks),
+		o.Alignment,
+	}
+}
+
+// String returns a compact human-readable form of the parameters.
+func (o ObjectTransmissionInfo) String() string {
+	return fmt.Sprintf("F=%d T=%d Z=%d N=%d Al=%d",
+		o.TransferLength, o.SymbolSize, o.SourceBlocks, o.SubBlocks, o.Alignment)
+}
+
+// SourceSymbols returns the total number of source symbols (Kt) of the object,
+// i.e. the transfer length divided by the symbol size, rounded up. At least
+// that many symbols are needed to decode it.
+func (o ObjectTransmissionInfo) SourceSymbols() int {
+	if o.SymbolSize == 0 {
+		return 0
+	}
+	return int((o.TransferLength + uint64(o.SymbolSize) - 1) / uint64(o.SymbolSize))
+}
+
+// SourceBlockSymbols returns the number of source symbols of the RFC 6330
+// source block with the given number, following the partitioning of section
+// 4.4.1.2: the first blocks hold one symbol more than the remaining ones.
+// It returns 0 if sbn is out of range.
+func (o ObjectTransmissionInfo) SourceBlockSymbols(sbn int) int {
+	z := int(o.SourceBlocks)
+	if z == 0 || sbn < 0 || sbn >= z {
+		return 0
+	}
+	kt := o.SourceSymbols()
+	large, small := (kt+z-1)/z, kt/z
+	if sbn < kt-small*z {
+		return large
+	}
+	return small
+}
+
+// Validate checks the parameters against the constraints of RFC 6330.
+//
+// Returns:
+//   - error: nil if the parameters are usable by a decoder, otherwise an error
+//     matching ErrInvalidLayout describing every violated constraint.
+func (o ObjectTransmissionInfo) Validate() error {
+	var errs []error
+	for _, problem := range o.problems() {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidLayout, problem))
+	}
+	return errors.Join(errs...)
+}
+
+// problems lists the RFC 6330 constraints violated by the parameters.
+func (o ObjectTransmissionInfo) problems() []string {
+	var problems []string
+	fail := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if o.TransferLength == 0 {
+		fail("transfer length is zero")
+	} else if o.TransferLength > MaxTransferLength {
+		fail("transfer length %d exceeds the maximum of %d", o.TransferLength, MaxTransferLength)
+	}
+	if o.SymbolSize == 0 {
+		fail("symbol size is zero")
+	}
+	if o.Alignment == 0 {
+		fail("symbol alignment is zero")
+	} else if o.SymbolSize%uint16(o.Alignment) != 0 {
+		fail("symbol size %d is not a multiple of the alignment %d", o.SymbolSize, o.Alignment)
+	}
+	if o.SourceBlocks == 0 {
+		fail("number of source blocks is zero")
+	}
+	if o.SubBlocks == 0 {
+		fail("number of sub-blocks is zero")
+	} else if o.Alignment != 0 && o.SubBlocks > o.SymbolSize/uint16(o.Alignment) {
+		fail("%d sub-blocks cannot be formed from %d-byte symbols with alignment %d",
+			o.SubBlocks, o.SymbolSize, o.Alignment)
+	}
+	if o.SymbolSize != 0 && o.SourceBlocks != 0 {
+		if kt := o.SourceSymbols(); int(o.SourceBlocks) > kt {
+			fail("%d source blocks for only %d source symbols", o.SourceBlocks, kt)
+		} else if k := o.SourceBlockSymbols(0); k > MaxSourceSymbolsPerBlock {
+			fail("source blocks of %d symbols exceed the maximum of %d", k, MaxSourceSymbolsPerBlock)
+		}
+	}
+
+	return problems
+}
+
+// CheckBlockSize checks that the parameters describe a block of size bytes.
+func (o ObjectTransmissionInfo) CheckBlockSize(size uint64) error {
+	if problem := o.blockSizeProblem(size); problem != "" {
+		return fmt.Errorf("%w: %s", ErrInvalidLayout, problem)
+	}
+	return nil
+}
+
+// CheckSymbolSize checks that the parameters were produced with the given
+// configured symbol size. The encoder rounds the configured size down to a
+// multiple of the alignment, so both the configured and the aligned size are
+// accepted.
+func (o ObjectTransmissionInfo) CheckSymbolSize(symbolSize uint16) error {
+	if problem := o.symbolSizeProblem(symbolSize); problem != "" {
+		return fmt.Errorf("%w: %s", ErrInvalidLayout, problem)
+	}
+	return nil
+}
+
+func (o ObjectTransmissionInfo) blockSizeProblem(size uint64) string {
+	if o.TransferLength != size {
+		return fmt.Sprintf("transfer length %d does not match the block size %d", o.TransferLength, size)
+	}
+	return ""
+}
+
+func (o ObjectTransmissionInfo) symbolSizeProblem(symbolSize uint16) string {
+	aligned := symbolSize
+	if o.Alignment != 0 {
+		aligned -= symbolSize % uint16(o.Alignment)
+	}
+	if o.SymbolSize != symbolSize && o.SymbolSize != aligned {
+		return fmt.Sprintf("symbol size %d does not match the configured symbol size %d", o.SymbolSize, symbolSize)
+	}
+	return ""
+}
+
+// OTI decodes the encoder parameters of the block.
+//
+// Example:
+//
+//	for _, block := range result.Blocks {
+//	    oti, err := block.OTI()
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Printf("block %d: %d source symbols of %d bytes\n",
+//	        block.BlockID, oti.SourceSymbols(), oti.SymbolSize)
+//	}
+func (b Block) OTI() (ObjectTransmissionInfo, error) {
+	return ParseOTI(b.EncoderParameters)
+}
+
+// OTI decodes the encoder parameters of the block.
+func (b BlockLayout) OTI() (ObjectTransmissionInfo, error) {
+	return ParseOTI(b.EncoderParameters)
+}
+
+// CheckLayout checks that every block of a layout can be decoded by this
+// processor: its encoder parameters must be valid, describe a block of the
+// recorded size, and use the processor's configured symbol size.
+//
+// DecodeSymbols performs this check before invoking the native library, so
+// mismatched layouts fail fast instead of producing a decoder error.
+//
+// Returns:
+//   - error: nil if the layout matches, otherwise the problems of all blocks
+//     joined with errors.Join. Each of them is a *LayoutError for the
+//     encoder_parameters field, and all of them match ErrInvalidLayout.
+func (p *RaptorQProcessor) CheckLayout(layout *Layout) error {
+	var errs []error
+	for i, block := range layout.Blocks {
+		for _, problem := range p.blockOTIProblems(block.EncoderParameters, block.Size) {
+			errs = append(errs, &LayoutError{
+				Index:   i,
+				BlockID: block.BlockID,
+				Field:   "encoder_parameters",
+				Reason:  problem,
+			})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// blockOTIProblems lists the problems of the encoder parameters of a block of
+// the given size. The symbol size is only checked when the processor knows its
+// configuration.
+func (p *RaptorQProcessor) blockOTIProblems(params []uint8, size uint64) []string {
+	problems := otiProblems(params, size)
+	if len(problems) > 0 || p.config.SymbolSize == 0 {
+		return problems
+	}
+	oti, _ := ParseOTI(params)
+	if problem := oti.symbolSizeProblem(p.config.SymbolSize); problem != "" {
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+// otiProblems lists the problems of the encoder parameters of a block of the
+// given size that can be found without knowing the processor configuration.
+func otiProblems(params []uint8, size uint64) []string {
+	if len(params) != EncoderParametersSize {
+		return []string{fmt.Sprintf("expected %d bytes, got %d", EncoderParametersSize, len(params))}
+	}
+	oti, _ := ParseOTI(params)
+	problems := oti.problems()
+	if len(problems) == 0 {
+		if problem := oti.blockSizeProblem(size); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
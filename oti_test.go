@@ -0,0 +1,130 @@
+package rq_go
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// Unit test for parsing and serializing encoder parameters
+func TestParseOTI(t *testing.T) {
+	params := []uint8{0, 0, 25, 240, 160, 0, 195, 80, 1, 0, 1, 8}
+	oti, err := ParseOTI(params)
+	if err != nil {
+		t.Fatalf("Failed to parse OTI: %v", err)
+	}
+
+	expected := ObjectTransmissionInfo{
+		TransferLength: 1700000,
+		SymbolSize:     50000,
+		SourceBlocks:   1,
+		SubBlocks:      1,
+		Alignment:      8,
+	}
+	if oti != expected {
+		t.Fatalf("Expected %v, got %v", expected, oti)
+	}
+	if !bytes.Equal(oti.Bytes(), params) {
+		t.Fatalf("Serialized OTI %v does not match %v", oti.Bytes(), params)
+	}
+	if err := oti.Validate(); err != nil {
+		t.Fatalf("Expected valid OTI, got: %v", err)
+	}
+
+	block := Block{EncoderParameters: params, Size: 1700000}
+	if blockOTI, err := block.OTI(); err != nil || blockOTI != expected {
+		t.Fatalf("Block.OTI() = %v, %v", blockOTI, err)
+	}
+
+	if _, err := ParseOTI(params[:4]); !errors.Is(err, ErrInvalidLayout) {
+		t.Fatalf("Expected ErrInvalidLayout for short parameters, got: %v", err)
+	}
+
+	// 40-bit transfer lengths survive a round trip
+	large := ObjectTransmissionInfo{TransferLength: 1 << 39, SymbolSize: 65528, SourceBlocks: 200, SubBlocks: 1, Alignment: 8}
+	if parsed, _ := ParseOTI(large.Bytes()); parsed != large {
+		t.Fatalf("Expected %v, got %v", large, parsed)
+	}
+}
+
+// Unit test for source symbol counts and source block partitioning
+func TestOTISourceSymbols(t *testing.T) {
+	oti := ObjectTransmissionInfo{TransferLength: 1700000, SymbolSize: 50000, SourceBlocks: 1, SubBlocks: 1, Alignment: 8}
+	if got := oti.SourceSymbols(); got != 34 {
+		t.Fatalf("Expected 34 source symbols, got %d", got)
+	}
+	oti.TransferLength = 50000
+	if got := oti.SourceSymbols(); got != 1 {
+		t.Fatalf("Expected 1 source symbol, got %d", got)
+	}
+
+	// 10 symbols in 3 source blocks: 4, 3, 3
+	oti = ObjectTransmissionInfo{TransferLength: 10 * 64, SymbolSize: 64, SourceBlocks: 3, SubBlocks: 1, Alignment: 8}
+	for sbn, expected := range []int{4, 3, 3, 0} {
+		if got := oti.SourceBlockSymbols(sbn); got != expected {
+			t.Fatalf("Source block %d: expected %d symbols, got %d", sbn, expected, got)
+		}
+	}
+}
+
+// Unit test for the RFC 6330 and consistency checks
+func TestOTIValidate(t *testing.T) {
+	valid := ObjectTransmissionInfo{TransferLength: 1000, SymbolSize: 64, SourceBlocks: 1, SubBlocks: 1, Alignment: 8}
+
+	tests := []struct {
+		name   string
+		mutate func(o *ObjectTransmissionInfo)
+	}{
+		{"zero transfer length", func(o *ObjectTransmissionInfo) { o.TransferLength = 0 }},
+		{"transfer length too large", func(o *ObjectTransmissionInfo) { o.TransferLength = MaxTransferLength + 1 }},
+		{"unaligned symbol size", func(o *ObjectTransmissionInfo) { o.SymbolSize = 60 }},
+		{"zero alignment", func(o *ObjectTransmissionInfo) { o.Alignment = 0 }},
+		{"zero source blocks", func(o *ObjectTransmissionInfo) { o.SourceBlocks = 0 }},
+		{"too many source blocks", func(o *ObjectTransmissionInfo) { o.SourceBlocks = 100 }},
+		{"too many sub-blocks", func(o *ObjectTransmissionInfo) { o.SubBlocks = 9 }},
+		{"oversized source block", func(o *ObjectTransmissionInfo) { o.TransferLength = 64 * (MaxSourceSymbolsPerBlock + 1) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oti := valid
+			tt.mutate(&oti)
+			if err := oti.Validate(); !errors.Is(err, ErrInvalidLayout) {
+				t.Fatalf("Expected ErrInvalidLayout, got: %v", err)
+			}
+		})
+	}
+
+	if err := valid.CheckBlockSize(1000); err != nil {
+		t.Fatalf("Unexpected block size error: %v", err)
+	}
+	if err := valid.CheckBlockSize(999); !errors.Is(err, ErrInvalidLayout) {
+		t.Fatalf("Expected ErrInvalidLayout for a block size mismatch, got: %v", err)
+	}
+
+	// The encoder aligns the configured symbol size down to the alignment
+	aligned := ObjectTransmissionInfo{TransferLength: 1000, SymbolSize: 65528, SourceBlocks: 1, SubBlocks: 1, Alignment: 8}
+	if err := aligned.CheckSymbolSize(DefaultSymbolSize); err != nil {
+		t.Fatalf("Unexpected symbol size error: %v", err)
+	}
+	if err := aligned.CheckSymbolSize(50000); !errors.Is(err, ErrInvalidLayout) {
+		t.Fatalf("Expected ErrInvalidLayout for a symbol size mismatch, got: %v", err)
+	}
+}
+
+// Unit test for checking a layout against the processor configuration
+func TestCheckLayout(t *testing.T) {
+	p := &RaptorQProcessor{config: ProcessorConfig{SymbolSize: 64}}
+	if err := p.CheckLayout(testLayout()); err != nil {
+		t.Fatalf("Expected a matching layout, got: %v", err)
+	}
+
+	p.config.SymbolSize = DefaultSymbolSize
+	err := p.CheckLayout(testLayout())
+	var layoutErr *LayoutError
+	if !errors.As(err, &layoutErr) || layoutErr.Field != "encoder_parameters" {
+		t.Fatalf("Expected an encoder_parameters LayoutError, got: %v", err)
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 2 {
+		t.Fatalf("Expected both blocks to be reported, got %d: %v", n, err)
+	}
+}
@@ -0,0 +1,477 @@
+package rq_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed is returned by ProcessorPool.Acquire once the pool is closed.
+var ErrPoolClosed = errors.New("processor pool is closed")
+
+// PoolConfig holds the limits of a ProcessorPool.
+type PoolConfig struct {
+	// Default is the configuration of the sessions handed out by Acquire. A zero
+	// value selects the defaults of NewDefaultRaptorQProcessor.
+	Default ProcessorConfig `json:"default"`
+
+	// MaxSessions limits the number of sessions, idle or in use, across all
+	// configurations. 0 means no limit.
+	MaxSessions int `json:"max_sessions"`
+
+	// MaxIdlePerConfig is the number of idle sessions kept warm for each
+	// configuration; further released sessions are freed. 0 means no limit.
+	MaxIdlePerConfig int `json:"max_idle_per_config"`
+
+	// MemoryBudgetMB limits the sum of MaxMemoryMB over all sessions of the
+	// pool, since the native memory limit applies per session. 0 means no limit.
+	MemoryBudgetMB uint64 `json:"memory_budget_mb"`
+
+	// IdleTimeout is how long a session may stay idle before it is freed.
+	// 0 disables idle eviction.
+	IdleTimeout time.Duration `json:"idle_timeout"`
+
+	// Backend creates the sessions of the pool. nil selects DefaultBackend.
+	Backend Backend `json:"-"`
+}
+
+// PoolStats is a snapshot of the state of a ProcessorPool.
+type PoolStats struct {
+	// Sessions is the number of live sessions owned by the pool.
+	Sessions int `json:"sessions"`
+
+	// InUse is the number of sessions currently acquired.
+	InUse int `json:"in_use"`
+
+	// Idle is the number of warm sessions waiting to be acquired.
+	Idle int `json:"idle"`
+
+	// Waiting is the number of Acquire calls currently blocked.
+	Waiting int `json:"waiting"`
+
+	// MemoryMB is the sum of MaxMemoryMB over the live sessions.
+	MemoryMB uint64 `json:"memory_mb"`
+
+	// MemoryBudgetMB is the configured memory budget, 0 if unlimited.
+	MemoryBudgetMB uint64 `json:"memory_budget_mb"`
+
+	// Acquires counts successful Acquire calls.
+	Acquires uint64 `json:"acquires"`
+
+	// Reuses counts Acquire calls served by a warm session.
+	Reuses uint64 `json:"reuses"`
+
+	// Created counts sessions created by the pool.
+	Created uint64 `json:"created"`
+
+	// Evicted counts idle sessions freed because of the idle timeout, the
+	// idle limit, or to make room for a session of another configuration.
+	Evicted uint64 `json:"evicted"`
+
+	// Waits counts Acquire calls that had to wait for a session.
+	Waits uint64 `json:"waits"`
+}
+
+// ProcessorPool keeps warm RaptorQ sessions for services that process many
+// requests concurrently, so that each request neither pays the session
+// initialization cost nor adds an unbounded amount of native memory.
+//
+// Sessions are keyed by ProcessorConfig. Acquire hands out an idle session with
+// the requested configuration, or creates one if the session limit and the
+// global memory budget allow it, evicting idle sessions of other configurations
+// if needed; otherwise it waits until a session is released. Sessions owned by
+// the pool are recorded as such in the package session registry.
+//
+// A ProcessorPool is safe for concurrent use by multiple goroutines.
+type ProcessorPool struct {
+	cfg PoolConfig
+
+	mu       sync.Mutex
+	idle     map[ProcessorConfig][]idleSession
+	inUse    map[*RaptorQProcessor]ProcessorConfig
+	sessions int
+	memoryMB uint64
+	waiting  int
+	changed  chan struct{}
+	closed   bool
+	stats    PoolStats
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// idleSession is a warm session of a ProcessorPool.
+type idleSession struct {
+	p     *RaptorQProcessor
+	since time.Time
+}
+
+// NewProcessorPool creates an empty pool with the given limits. Sessions are
+// created on demand by Acquire.
+//
+// Example:
+//
+//	pool := raptorq.NewProcessorPool(raptorq.PoolConfig{
+//	    MaxSessions:    8,
+//	    MemoryBudgetMB: 32 * 1024,
+//	    IdleTimeout:    5 * time.Minute,
+//	})
+//	defer pool.Close()
+//
+//	processor, err := pool.Acquire(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	defer pool.Release(processor)
+func NewProcessorPool(cfg PoolConfig) *ProcessorPool {
+	if cfg.Default == (ProcessorConfig{}) {
+		cfg.Default = DefaultProcessorConfig()
+	}
+
+	pool := &ProcessorPool{
+		cfg:     cfg,
+		idle:    make(map[ProcessorConfig][]idleSession),
+		inUse:   make(map[*RaptorQProcessor]ProcessorConfig),
+		changed: make(chan struct{}),
+	}
+
+	if cfg.IdleTimeout > 0 {
+		pool.stop = make(chan struct{})
+		pool.done = make(chan struct{})
+		go pool.evictLoop()
+	}
+	return pool
+}
+
+// Acquire returns a session with the pool's default configuration.
+// See AcquireConfig.
+func (pp *ProcessorPool) Acquire(ctx context.Context) (*RaptorQProcessor, error) {
+	return pp.AcquireConfig(ctx, pp.cfg.Default)
+}
+
+// AcquireConfig returns a session with the given configuration, waiting until
+// the pool limits allow it or ctx ends.
+//
+// The returned processor must be handed back with Release instead of being
+// freed, so that it can be reused.
+//
+// Parameters:
+//   - ctx: Context bounding the time spent waiting for a session.
+//   - config: The configuration of the session.
+//
+// Returns:
+//   - *RaptorQProcessor: A session owned by the pool.
+//   - error: ctx.Err() if the context ended while waiting, ErrPoolClosed if the
+//     pool is closed, an error matching ErrMemoryLimit if the session alone
+//     exceeds the memory budget, or the error of NewRaptorQProcessor.
+func (pp *ProcessorPool) AcquireConfig(ctx context.Context, config ProcessorConfig) (_ *RaptorQProcessor, err error) {
+	waited := false
+	_, trace := startSpan(ctx, "rq.AcquireConfig")
+	defer func() {
+		trace.setAttributes(Attribute{Key: AttrWaited, Value: waited})
+		trace.end(err)
+	}()
+
+	if pp.cfg.MemoryBudgetMB > 0 && config.MaxMemoryMB > pp.cfg.MemoryBudgetMB {
+		return nil, fmt.Errorf("%w: session needs %d MB, pool budget is %d MB",
+			ErrMemoryLimit, config.MaxMemoryMB, pp.cfg.MemoryBudgetMB)
+	}
+
+	pp.mu.Lock()
+	for {
+		if pp.closed {
+			pp.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+
+		if p := pp.popIdle(config); p != nil {
+			pp.inUse[p] = config
+			pp.stats.Acquires++
+			pp.stats.Reuses++
+			pp.mu.Unlock()
+			return p, nil
+		}
+
+		if pp.reserve(config) {
+			pp.mu.Unlock()
+			return pp.create(config)
+		}
+
+		// Make room by freeing a warm session of another configuration.
+		if p := pp.popOldestIdle(); p != nil {
+			pp.release(p, true)
+			continue
+		}
+
+		changed := pp.changed
+		if !waited {
+			waited = true
+			pp.stats.Waits++
+		}
+		pp.waiting++
+		pp.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			pp.mu.Lock()
+			pp.waiting--
+			pp.mu.Unlock()
+			return nil, ctx.Err()
+		}
+
+		pp.mu.Lock()
+		pp.waiting--
+	}
+}
+
+// Release hands a session acquired from the pool back to it. The session is
+// kept warm for the next Acquire, unless the pool is closed, the idle limit
+// for its configuration is reached, or it has been freed, in which case its
+// resources are released. Processors that were not acquired from this pool
+// are left untouched.
+func (pp *ProcessorPool) Release(p *RaptorQProcessor) {
+	if p == nil {
+		return
+	}
+
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	config, ok := pp.inUse[p]
+	if !ok {
+		return
+	}
+	delete(pp.inUse, p)
+
+	switch {
+	case p.SessionID == 0:
+		// Freed by the caller: only the accounting is left to undo.
+		pp.sessions--
+		pp.memoryMB -= config.MaxMemoryMB
+		pp.notify()
+	case pp.closed || (pp.cfg.MaxIdlePerConfig > 0 && len(pp.idle[config]) >= pp.cfg.MaxIdlePerConfig):
+		pp.release(p, !pp.closed)
+	default:
+		pp.idle[config] = append(pp.idle[config], idleSession{p: p, since: time.Now()})
+		pp.notify()
+	}
+}
+
+// Warm creates idle sessions with the given configuration until n of them are
+// ready, so that the first requests do not pay the initialization cost.
+func (pp *ProcessorPool) Warm(ctx context.Context, config ProcessorConfig, n int) error {
+	var acquired []*RaptorQProcessor
+	defer func() {
+		for _, p := range acquired {
+			pp.Release(p)
+		}
+	}()
+
+	for len(acquired) < n {
+		p, err := pp.AcquireConfig(ctx, config)
+		if err != nil {
+			return err
+		}
+		acquired = append(acquired, p)
+	}
+	return nil
+}
+
+// Stats returns a snapshot of the pool state and counters.
+func (pp *ProcessorPool) Stats() PoolStats {
+	pp.mu.Lock()
+	stats := pp.stats
+	stats.InUse = len(pp.inUse)
+	for _, list := range pp.idle {
+		stats.Idle += len(list)
+	}
+	stats.Waiting = pp.waiting
+	stats.MemoryMB = pp.memoryMB
+	stats.MemoryBudgetMB = pp.cfg.MemoryBudgetMB
+	pp.mu.Unlock()
+
+	// Live sessions are counted in the package registry, so sessions freed
+	// behind the pool's back are not reported.
+	sessionMutex.Lock()
+	for _, info := range sessions {
+		if info.pool == pp {
+			stats.Sessions++
+		}
+	}
+	sessionMutex.Unlock()
+
+	return stats
+}
+
+// Close frees all idle sessions and stops idle eviction. Sessions still in use
+// are freed when they are released. Acquire fails with ErrPoolClosed afterwards.
+func (pp *ProcessorPool) Close() error {
+	pp.mu.Lock()
+	if pp.closed {
+		pp.mu.Unlock()
+		return nil
+	}
+	pp.closed = true
+	for config, list := range pp.idle {
+		for _, s := range list {
+			pp.release(s.p, false)
+		}
+		delete(pp.idle, config)
+	}
+	pp.notify()
+	pp.mu.Unlock()
+
+	if pp.stop != nil {
+		close(pp.stop)
+		<-pp.done
+	}
+	return nil
+}
+
+// reserve accounts for a new session if the limits allow it. It must be called
+// with pp.mu held.
+func (pp *ProcessorPool) reserve(config ProcessorConfig) bool {
+	if pp.cfg.MaxSessions > 0 && pp.sessions >= pp.cfg.MaxSessions {
+		return false
+	}
+	if pp.cfg.MemoryBudgetMB > 0 && pp.memoryMB+config.MaxMemoryMB > pp.cfg.MemoryBudgetMB {
+		return false
+	}
+	pp.sessions++
+	pp.memoryMB += config.MaxMemoryMB
+	return true
+}
+
+// create initializes a reserved session and registers it as owned by the pool.
+func (pp *ProcessorPool) create(config ProcessorConfig) (*RaptorQProcessor, error) {
+	backend := pp.cfg.Backend
+	if backend == nil {
+		backend = defaultBackend
+	}
+	p, err := newProcessor(backend, config)
+
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	if err != nil {
+		pp.sessions--
+		pp.memoryMB -= config.MaxMemoryMB
+		pp.notify()
+		return nil, err
+	}
+
+	sessionMutex.Lock()
+	if info, ok := sessions[p.sessionKey()]; ok {
+		info.pool = pp
+	}
+	sessionMutex.Unlock()
+
+	pp.inUse[p] = config
+	pp.stats.Acquires++
+	pp.stats.Created++
+	return p, nil
+}
+
+// release frees a session that is no longer in use or idle and undoes its
+// accounting. It must be called with pp.mu held.
+func (pp *ProcessorPool) release(p *RaptorQProcessor, evicted bool) {
+	p.Free()
+	pp.sessions--
+	pp.memoryMB -= p.config.MaxMemoryMB
+	if evicted {
+		pp.stats.Evicted++
+	}
+	pp.notify()
+}
+
+// popIdle removes and returns the most recently used idle session with the
+// given configuration. It must be called with pp.mu held.
+func (pp *ProcessorPool) popIdle(config ProcessorConfig) *RaptorQProcessor {
+	list := pp.idle[config]
+	if len(list) == 0 {
+		return nil
+	}
+	p := list[len(list)-1].p
+	if len(list) == 1 {
+		delete(pp.idle, config)
+	} else {
+		pp.idle[config] = list[:len(list)-1]
+	}
+	return p
+}
+
+// popOldestIdle removes and returns the least recently used idle session of
+// any configuration. It must be called with pp.mu held.
+func (pp *ProcessorPool) popOldestIdle() *RaptorQProcessor {
+	var oldest *ProcessorConfig
+	for config, list := range pp.idle {
+		if oldest == nil || list[0].since.Before(pp.idle[*oldest][0].since) {
+			c := config
+			oldest = &c
+		}
+	}
+	if oldest == nil {
+		return nil
+	}
+
+	list := pp.idle[*oldest]
+	p := list[0].p
+	if len(list) == 1 {
+		delete(pp.idle, *oldest)
+	} else {
+		pp.idle[*oldest] = list[1:]
+	}
+	return p
+}
+
+// notify wakes up every waiting Acquire. It must be called with pp.mu held.
+func (pp *ProcessorPool) notify() {
+	close(pp.changed)
+	pp.changed = make(chan struct{})
+}
+
+// evictLoop periodically frees sessions that have been idle for longer than
+// the idle timeout.
+func (pp *ProcessorPool) evictLoop() {
+	defer close(pp.done)
+
+	interval := pp.cfg.IdleTimeout / 2
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-pp.stop:
+			return
+		case now := <-ticker.C:
+			pp.evictIdle(now)
+		}
+	}
+}
+
+// evictIdle frees the sessions that have been idle since before now minus the
+// idle timeout.
+func (pp *ProcessorPool) evictIdle(now time.Time) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	deadline := now.Add(-pp.cfg.IdleTimeout)
+	for config, list := range pp.idle {
+		// Idle lists are ordered by release time, oldest first.
+		n := 0
+		for n < len(list) && list[n].since.Before(deadline) {
+			pp.release(list[n].p, true)
+			n++
+		}
+		if n == len(list) {
+			delete(pp.idle, config)
+		} else if n > 0 {
+			pp.idle[config] = list[n:]
+		}
+	}
+}
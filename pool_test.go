@@ -0,0 +1,157 @@
+package rq_go
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testPoolConfig returns a small session configuration using memMB of memory
+func testPoolConfig(memMB uint64) ProcessorConfig {
+	return ProcessorConfig{
+		SymbolSize:       DefaultProcessorConfig().SymbolSize,
+		RedundancyFactor: DefaultRedundancyFactor,
+		MaxMemoryMB:      memMB,
+		ConcurrencyLimit: 1,
+	}
+}
+
+// Test reuse of warm sessions and registration in the session registry
+func TestProcessorPoolReuse(t *testing.T) {
+	pool := NewProcessorPool(PoolConfig{Default: testPoolConfig(256), Backend: NewPureGoBackend()})
+	defer pool.Close()
+
+	p1, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to acquire processor: %v", err)
+	}
+	sessionMutex.Lock()
+	info := sessions[p1.sessionKey()]
+	sessionMutex.Unlock()
+	if info == nil || info.pool != pool || info.config != testPoolConfig(256) {
+		t.Fatalf("Session not registered as owned by the pool: %+v", info)
+	}
+
+	pool.Release(p1)
+	p2, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to acquire processor: %v", err)
+	}
+	if p2 != p1 {
+		t.Fatal("Expected the warm session to be reused")
+	}
+
+	// A different configuration gets its own session
+	p3, err := pool.AcquireConfig(context.Background(), testPoolConfig(128))
+	if err != nil {
+		t.Fatalf("Failed to acquire processor: %v", err)
+	}
+	if p3 == p1 {
+		t.Fatal("Expected a new session for a different configuration")
+	}
+
+	stats := pool.Stats()
+	if stats.Sessions != 2 || stats.InUse != 2 || stats.Created != 2 || stats.Reuses != 1 || stats.MemoryMB != 384 {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+
+	pool.Release(p2)
+	pool.Release(p3)
+	if stats := pool.Stats(); stats.Idle != 2 || stats.InUse != 0 {
+		t.Fatalf("Unexpected stats after release: %+v", stats)
+	}
+}
+
+// Test that the memory budget blocks Acquire and evicts idle sessions of other configurations
+func TestProcessorPoolMemoryBudget(t *testing.T) {
+	pool := NewProcessorPool(PoolConfig{Default: testPoolConfig(256), MemoryBudgetMB: 512, Backend: NewPureGoBackend()})
+	defer pool.Close()
+
+	if _, err := pool.AcquireConfig(context.Background(), testPoolConfig(1024)); !errors.Is(err, ErrMemoryLimit) {
+		t.Fatalf("Expected ErrMemoryLimit for a session above the budget, got: %v", err)
+	}
+
+	p1, _ := pool.Acquire(context.Background())
+	p2, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to acquire processor: %v", err)
+	}
+
+	// The budget is exhausted: Acquire waits until the context ends
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected DeadlineExceeded, got: %v", err)
+	}
+
+	// A waiting Acquire is served by a release
+	got := make(chan *RaptorQProcessor)
+	go func() {
+		p, _ := pool.Acquire(context.Background())
+		got <- p
+	}()
+	time.Sleep(20 * time.Millisecond)
+	pool.Release(p1)
+	select {
+	case p := <-got:
+		if p != p1 {
+			t.Fatal("Expected the released session to be handed to the waiter")
+		}
+		pool.Release(p)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Waiting Acquire was not woken up by Release")
+	}
+
+	// An idle session of another configuration is evicted to make room
+	pool.Release(p2)
+	p3, err := pool.AcquireConfig(context.Background(), testPoolConfig(512))
+	if err != nil {
+		t.Fatalf("Failed to acquire processor: %v", err)
+	}
+	stats := pool.Stats()
+	if stats.Evicted != 2 || stats.Sessions != 1 || stats.MemoryMB != 512 || stats.Waits != 2 {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+	pool.Release(p3)
+}
+
+// Test idle eviction, the idle limit and Close
+func TestProcessorPoolEviction(t *testing.T) {
+	pool := NewProcessorPool(PoolConfig{
+		Default:          testPoolConfig(64),
+		MaxIdlePerConfig: 1,
+		IdleTimeout:      30 * time.Millisecond,
+		Backend:          NewPureGoBackend(),
+	})
+
+	if err := pool.Warm(context.Background(), testPoolConfig(64), 2); err != nil {
+		t.Fatalf("Failed to warm pool: %v", err)
+	}
+	if stats := pool.Stats(); stats.Idle != 1 || stats.Evicted != 1 {
+		t.Fatalf("Expected the idle limit to keep a single session: %+v", stats)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for pool.Stats().Idle != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Idle session was not evicted: %+v", pool.Stats())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	p, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to acquire processor: %v", err)
+	}
+	pool.Close()
+	if _, err := pool.Acquire(context.Background()); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("Expected ErrPoolClosed, got: %v", err)
+	}
+
+	// Sessions in use when the pool closes are freed on release
+	pool.Release(p)
+	if p.SessionID != 0 || pool.Stats().Sessions != 0 {
+		t.Fatalf("Expected the session to be freed on release: %+v", pool.Stats())
+	}
+}
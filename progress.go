@@ -0,0 +1,264 @@
+package rq_go
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ProgressStage identifies the kind of a ProgressEvent.
+type ProgressStage int
+
+const (
+	// ProgressStarted is reported once, before the first block is processed.
+	ProgressStarted ProgressStage = iota
+
+	// ProgressBlockStarted is reported before a block is handed to the native library.
+	ProgressBlockStarted
+
+	// ProgressBlockFinished is reported after a block has been processed.
+	ProgressBlockFinished
+
+	// ProgressFinished is reported once, after the operation completed or failed.
+	ProgressFinished
+)
+
+// String returns the name of the stage.
+func (s ProgressStage) String() string {
+	switch s {
+	case ProgressStarted:
+		return "started"
+	case ProgressBlockStarted:
+		return "block started"
+	case ProgressBlockFinished:
+		return "block finished"
+	case ProgressFinished:
+		return "finished"
+	default:
+		return "unknown"
+	}
+}
+
+// ProgressEvent describes the progress of an EncodeFile, CreateMetadata or
+// DecodeSymbols operation. Every event carries the running totals of the
+// operation, so a consumer may look at the latest event only.
+type ProgressEvent struct {
+	// Op is the operation: "EncodeFile", "CreateMetadata" or "DecodeSymbols".
+	Op string
+
+	// Stage is the kind of the event.
+	Stage ProgressStage
+
+	// BlockID is the block the event refers to, for the block stages.
+	BlockID uint64
+
+	// Blocks is the total number of blocks of the operation.
+	Blocks int
+
+	// BlocksDone is the number of blocks processed so far.
+	BlocksDone int
+
+	// BytesProcessed is the number of input (or, when decoding, output) bytes
+	// processed so far.
+	BytesProcessed uint64
+
+	// TotalBytes is the size of the file being encoded or decoded.
+	TotalBytes uint64
+
+	// SymbolsWritten is the number of symbol files written so far. It stays
+	// zero for CreateMetadata and DecodeSymbols.
+	SymbolsWritten uint32
+
+	// Elapsed is the time since the operation started.
+	Elapsed time.Duration
+
+	// Remaining is the estimated time until the operation completes, based on
+	// the throughput so far. It is zero until the first block has finished.
+	Remaining time.Duration
+
+	// Err is the error the operation failed with, for ProgressFinished.
+	Err error
+}
+
+// ProgressFunc receives progress events.
+//
+// Events of an operation are delivered in order from a separate goroutine, so
+// a slow ProgressFunc never delays the encoding or decoding itself; events are
+// queued until it returns. ProgressStarted is always followed by ProgressFinished.
+type ProgressFunc func(ProgressEvent)
+
+// ProgressChannel returns a ProgressFunc that sends every event to ch. The
+// receiver should keep draining ch until it sees ProgressFinished; events are
+// queued in memory while ch is full.
+//
+// Example:
+//
+//	events := make(chan raptorq.ProgressEvent, 16)
+//	go func() {
+//	    for ev := range events {
+//	        fmt.Printf("%s: %d/%d bytes, %s remaining\n", ev.Op, ev.BytesProcessed, ev.TotalBytes, ev.Remaining)
+//	    }
+//	}()
+//	ctx := raptorq.WithProgress(context.Background(), raptorq.ProgressChannel(events))
+func ProgressChannel(ch chan<- ProgressEvent) ProgressFunc {
+	return func(ev ProgressEvent) { ch <- ev }
+}
+
+// progressKey is the context key of the ProgressFunc set with WithProgress.
+type progressKey struct{}
+
+// WithProgress returns a copy of ctx that makes EncodeFileContext,
+// CreateMetadataContext and DecodeSymbolsContext report progress to fn. It
+// takes precedence over a ProgressFunc installed with SetProgressFunc.
+//
+// Example:
+//
+//	ctx := raptorq.WithProgress(context.Background(), func(ev raptorq.ProgressEvent) {
+//	    if ev.Stage == raptorq.ProgressBlockFinished {
+//	        log.Printf("block %d done, %d/%d blocks", ev.BlockID, ev.BlocksDone, ev.Blocks)
+//	    }
+//	})
+//	result, err := processor.EncodeFileContext(ctx, "input.dat", "symbols/", 0)
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// SetProgressFunc installs fn as the receiver of progress events of all
+// operations of the processor, including EncodeFile, CreateMetadata and
+// DecodeSymbols. Passing nil removes it.
+//
+// With a ProgressFunc installed, these operations process the file one block at
+// a time like their Context variants, which produces the same symbols and
+// layout but lets progress be reported between blocks.
+func (p *RaptorQProcessor) SetProgressFunc(fn ProgressFunc) {
+	if fn == nil {
+		p.progress.Store(nil)
+		return
+	}
+	p.progress.Store(&fn)
+}
+
+// progressFunc returns the ProgressFunc of ctx, or the one installed with
+// SetProgressFunc. ctx may be nil.
+func (p *RaptorQProcessor) progressFunc(ctx context.Context) ProgressFunc {
+	if ctx != nil {
+		if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok && fn != nil {
+			return fn
+		}
+	}
+	if fn := p.progress.Load(); fn != nil {
+		return *fn
+	}
+	return nil
+}
+
+// startProgress returns a reporter for an operation on blocks blocks and
+// totalBytes bytes and reports ProgressStarted, or returns nil if no
+// ProgressFunc is set. All methods of progressReporter accept a nil receiver.
+func (p *RaptorQProcessor) startProgress(ctx context.Context, op string, blocks int, totalBytes uint64) *progressReporter {
+	fn := p.progressFunc(ctx)
+	if fn == nil {
+		return nil
+	}
+
+	r := &progressReporter{
+		fn:    fn,
+		start: time.Now(),
+		state: ProgressEvent{Op: op, Blocks: blocks, TotalBytes: totalBytes},
+		wake:  make(chan struct{}, 1),
+	}
+	go r.dispatch()
+	r.emit(ProgressStarted)
+	return r
+}
+
+// progressReporter tracks the progress of one operation and delivers its
+// events to a ProgressFunc from a dispatcher goroutine.
+type progressReporter struct {
+	fn    ProgressFunc
+	start time.Time
+
+	// state holds the running totals. It is only used by the goroutine
+	// running the operation.
+	state ProgressEvent
+
+	mu    sync.Mutex
+	queue []ProgressEvent
+	wake  chan struct{}
+}
+
+// blockStarted reports ProgressBlockStarted for a block.
+func (r *progressReporter) blockStarted(blockID uint64) {
+	if r == nil {
+		return
+	}
+	r.state.BlockID = blockID
+	r.emit(ProgressBlockStarted)
+}
+
+// blockFinished reports ProgressBlockFinished for a block of size bytes that
+// produced symbols symbol files.
+func (r *progressReporter) blockFinished(blockID, size uint64, symbols uint32) {
+	if r == nil {
+		return
+	}
+	r.state.BlockID = blockID
+	r.state.BlocksDone++
+	r.state.BytesProcessed += size
+	r.state.SymbolsWritten += symbols
+	r.emit(ProgressBlockFinished)
+}
+
+// finish reports ProgressFinished with the result of the operation.
+func (r *progressReporter) finish(err error) {
+	if r == nil {
+		return
+	}
+	r.state.Err = err
+	r.emit(ProgressFinished)
+}
+
+// emit queues an event for the dispatcher without waiting for its delivery.
+func (r *progressReporter) emit(stage ProgressStage) {
+	ev := r.state
+	ev.Stage = stage
+	ev.Elapsed = time.Since(r.start)
+	if stage != ProgressFinished {
+		ev.Remaining = estimateRemaining(ev.Elapsed, ev.BytesProcessed, ev.TotalBytes)
+	}
+
+	r.mu.Lock()
+	r.queue = append(r.queue, ev)
+	r.mu.Unlock()
+
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch delivers queued events in order until ProgressFinished.
+func (r *progressReporter) dispatch() {
+	for range r.wake {
+		r.mu.Lock()
+		events := r.queue
+		r.queue = nil
+		r.mu.Unlock()
+
+		for _, ev := range events {
+			r.fn(ev)
+			if ev.Stage == ProgressFinished {
+				return
+			}
+		}
+	}
+}
+
+// estimateRemaining extrapolates the time needed for the remaining bytes from
+// the throughput so far. It returns 0 when nothing has been processed yet.
+func estimateRemaining(elapsed time.Duration, done, total uint64) time.Duration {
+	if done == 0 || done >= total {
+		return 0
+	}
+	return time.Duration(float64(elapsed) * float64(total-done) / float64(done))
+}
@@ -0,0 +1,162 @@
+package rq_go
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// collectProgress returns a ProgressFunc and a function waiting for ProgressFinished
+// and returning all events received
+func collectProgress(t *testing.T) (ProgressFunc, func() []ProgressEvent) {
+	events := make(chan ProgressEvent, 64)
+	wait := func() []ProgressEvent {
+		var got []ProgressEvent
+		for {
+			select {
+			case ev := <-events:
+				got = append(got, ev)
+				if ev.Stage == ProgressFinished {
+					return got
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("ProgressFinished not received, got %+v", got)
+			}
+		}
+	}
+	return ProgressChannel(events), wait
+}
+
+// Unit test for event ordering, running totals and non-blocking delivery
+func TestProgressReporter(t *testing.T) {
+	p := &RaptorQProcessor{}
+	if r := p.startProgress(context.Background(), "EncodeFile", 2, 300); r != nil {
+		t.Fatal("Expected no reporter without a ProgressFunc")
+	}
+
+	// A callback that blocks until released must not block the operation
+	release := make(chan struct{})
+	fn, wait := collectProgress(t)
+	ctx := WithProgress(context.Background(), func(ev ProgressEvent) {
+		<-release
+		fn(ev)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		r := p.startProgress(ctx, "EncodeFile", 2, 300)
+		r.blockStarted(0)
+		r.blockFinished(0, 100, 7)
+		r.blockStarted(1)
+		r.blockFinished(1, 200, 9)
+		r.finish(nil)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Reporting blocked on a slow ProgressFunc")
+	}
+	close(release)
+
+	events := wait()
+	stages := []ProgressStage{ProgressStarted, ProgressBlockStarted, ProgressBlockFinished,
+		ProgressBlockStarted, ProgressBlockFinished, ProgressFinished}
+	if len(events) != len(stages) {
+		t.Fatalf("Expected %d events, got %d: %+v", len(stages), len(events), events)
+	}
+	for i, ev := range events {
+		if ev.Stage != stages[i] || ev.Op != "EncodeFile" || ev.Blocks != 2 || ev.TotalBytes != 300 {
+			t.Fatalf("Unexpected event %d: %+v", i, ev)
+		}
+	}
+	if ev := events[2]; ev.BlockID != 0 || ev.BlocksDone != 1 || ev.BytesProcessed != 100 || ev.SymbolsWritten != 7 {
+		t.Fatalf("Unexpected totals after the first block: %+v", ev)
+	}
+	if ev := events[5]; ev.BlocksDone != 2 || ev.BytesProcessed != 300 || ev.SymbolsWritten != 16 || ev.Remaining != 0 {
+		t.Fatalf("Unexpected totals at the end: %+v", ev)
+	}
+
+	if d := estimateRemaining(10*time.Second, 100, 300); d != 20*time.Second {
+		t.Fatalf("Expected 20s remaining, got %s", d)
+	}
+	if d := estimateRemaining(10*time.Second, 0, 300); d != 0 {
+		t.Fatalf("Expected no estimate before any progress, got %s", d)
+	}
+}
+
+// Test that a ProgressFunc of the context takes precedence over the processor's
+func TestProgressFuncPrecedence(t *testing.T) {
+	p := &RaptorQProcessor{}
+	if p.progressFunc(nil) != nil {
+		t.Fatal("Expected no ProgressFunc by default")
+	}
+
+	var got string
+	p.SetProgressFunc(func(ProgressEvent) { got = "processor" })
+	p.progressFunc(context.Background())(ProgressEvent{})
+	if got != "processor" {
+		t.Fatalf("Expected the processor's ProgressFunc, got %q", got)
+	}
+
+	ctx := WithProgress(context.Background(), func(ProgressEvent) { got = "context" })
+	p.progressFunc(ctx)(ProgressEvent{})
+	if got != "context" {
+		t.Fatalf("Expected the context's ProgressFunc, got %q", got)
+	}
+
+	p.SetProgressFunc(nil)
+	if p.progressFunc(nil) != nil {
+		t.Fatal("Expected SetProgressFunc(nil) to remove the ProgressFunc")
+	}
+}
+
+// System test for progress events of EncodeFile, CreateMetadata and DecodeSymbols (3MB, 1MB blocks)
+func TestSysProgress(t *testing.T) {
+	processor, err := NewDefaultRaptorQProcessor()
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	ctx := NewTestContext(t, 3*1024*1024)
+	defer ctx.Cleanup()
+
+	fn, wait := collectProgress(t)
+	processor.SetProgressFunc(fn)
+
+	blockSize := 1024 * 1024
+	res, err := processor.EncodeFile(ctx.InputFile, ctx.SymbolsDir, blockSize)
+	if err != nil {
+		t.Fatalf("Failed to encode file: %v", err)
+	}
+	events := wait()
+	last := events[len(events)-1]
+	if last.Err != nil || last.Blocks != 3 || last.BlocksDone != 3 || last.BytesProcessed != 3*1024*1024 ||
+		last.SymbolsWritten != res.TotalSymbolsCount {
+		t.Fatalf("Unexpected final encode event: %+v", last)
+	}
+	if len(events) != 8 {
+		t.Fatalf("Expected 8 encode events, got %d", len(events))
+	}
+
+	if _, err := processor.CreateMetadata(ctx.InputFile, filepath.Join(ctx.TempDir, "metadata.json"), blockSize); err != nil {
+		t.Fatalf("Failed to create metadata: %v", err)
+	}
+	events = wait()
+	if last := events[len(events)-1]; last.Op != "CreateMetadata" || last.BlocksDone != 3 || last.SymbolsWritten != 0 {
+		t.Fatalf("Unexpected final metadata event: %+v", last)
+	}
+
+	if err := processor.DecodeSymbols(ctx.SymbolsDir, ctx.OutputFile, res.LayoutFilePath); err != nil {
+		t.Fatalf("Failed to decode symbols: %v", err)
+	}
+	events = wait()
+	if last := events[len(events)-1]; last.Op != "DecodeSymbols" || last.BlocksDone != 3 || last.BytesProcessed != 3*1024*1024 {
+		t.Fatalf("Unexpected final decode event: %+v", last)
+	}
+	if !ctx.VerifyFilesMatch(t) {
+		t.Fatal("Decoded file does not match the original")
+	}
+}
@@ -0,0 +1,212 @@
+package rq_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DecodeRange decodes the bytes [offset, offset+length) of the original file and
+// writes them to w.
+//
+// Only the blocks overlapping the range are decoded, each with a separate
+// native call, so serving a small range of a large file costs about one block
+// of CPU and I/O instead of the whole file. Blocks are decoded in offset order
+// and written to w as soon as each one is recovered.
+//
+// Parameters:
+//   - symbolsDir: Directory containing the encoded symbols.
+//   - layoutPath: Path to the layout file containing metadata about the encoding.
+//   - offset: Offset of the first byte to decode in the original file.
+//   - length: Number of bytes to decode. The range must lie within the file.
+//   - w: Destination of the decoded bytes.
+//
+// Returns:
+//   - int64: The number of bytes written to w.
+//   - error: An error matching ErrInvalidParameters if the range is out of
+//     bounds, ErrInvalidLayout if the layout cannot be parsed, the decoding
+//     errors of DecodeSymbols, or the error returned by w.
+//
+// Example:
+//
+//	// Serve an HTTP range request
+//	n, err := processor.DecodeRange("symbols/", "symbols/_raptorq_layout.json", start, end-start, w)
+//	if err != nil {
+//	    return fmt.Errorf("range decoding failed after %d bytes: %w", n, err)
+//	}
+func (p *RaptorQProcessor) DecodeRange(symbolsDir, layoutPath string, offset, length uint64, w io.Writer) (int64, error) {
+	return p.DecodeRangeContext(context.Background(), symbolsDir, layoutPath, offset, length, w)
+}
+
+// DecodeRangeContext decodes a range of the original file like DecodeRange,
+// honouring cancellation and deadlines of ctx.
+//
+// ctx is checked before every block and passed to its decoding; once it is
+// done, ctx.Err() is returned (context.Canceled or context.DeadlineExceeded)
+// and w holds the bytes of the blocks decoded so far. A block that the native
+// library is decoding cannot be interrupted.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation of the operation.
+//   - symbolsDir, layoutPath, offset, length, w: As for DecodeRange.
+//
+// Returns:
+//   - int64: The number of bytes written to w.
+//   - error: ctx.Err() if the context ended, or the errors of DecodeRange.
+//
+// Example:
+//
+//	// Stop decoding when the client of an HTTP request goes away
+//	n, err := processor.DecodeRangeContext(r.Context(), dir, layoutPath, start, end-start, w)
+func (p *RaptorQProcessor) DecodeRangeContext(ctx context.Context, symbolsDir, layoutPath string, offset, length uint64, w io.Writer) (int64, error) {
+	layout, err := p.loadDecodeLayout(symbolsDir, layoutPath)
+	if err != nil {
+		return 0, err
+	}
+
+	total := layout.TotalSize()
+	if offset > total || length > total-offset {
+		return 0, fmt.Errorf("%w: range [%d, %d) is outside the %d bytes described by the layout",
+			ErrInvalidParameters, offset, offset+length, total)
+	}
+	if length == 0 {
+		return 0, nil
+	}
+	end := offset + length
+
+	stageDir, err := os.MkdirTemp("", "raptorq-range-")
+	if err != nil {
+		return 0, ioError("DecodeSymbols", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	var written int64
+	for _, block := range sortedBlocks(layout) {
+		blockEnd := block.OriginalOffset + block.Size
+		if blockEnd <= offset || block.OriginalOffset >= end {
+			continue
+		}
+
+		start := uint64(0)
+		if offset > block.OriginalOffset {
+			start = offset - block.OriginalOffset
+		}
+		stop := block.Size
+		if end < blockEnd {
+			stop = end - block.OriginalOffset
+		}
+
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		n, err := p.decodeBlockRange(ctx, stageDir, symbolsDir, block, start, stop-start, w)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// DecodeBlock decodes a single block of the original file and writes it to w.
+//
+// Parameters:
+//   - blockID: The ID of the block to decode, as listed in the layout.
+//   - symbolsDir: Directory containing the encoded symbols.
+//   - layoutPath: Path to the layout file containing metadata about the encoding.
+//   - w: Destination of the decoded block.
+//
+// Returns:
+//   - int64: The number of bytes written to w, the size of the block on success.
+//   - error: An error matching ErrInvalidParameters if the layout has no block
+//     with that ID, the decoding errors of DecodeSymbols, or the error returned by w.
+func (p *RaptorQProcessor) DecodeBlock(blockID uint64, symbolsDir, layoutPath string, w io.Writer) (int64, error) {
+	return p.DecodeBlockContext(context.Background(), blockID, symbolsDir, layoutPath, w)
+}
+
+// DecodeBlockContext decodes a single block like DecodeBlock, returning
+// ctx.Err() without decoding if ctx is done when it starts. The native
+// decoding of the block cannot be interrupted.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation of the operation.
+//   - blockID, symbolsDir, layoutPath, w: As for DecodeBlock.
+//
+// Returns:
+//   - int64: The number of bytes written to w.
+//   - error: ctx.Err() if the context ended, or the errors of DecodeBlock.
+func (p *RaptorQProcessor) DecodeBlockContext(ctx context.Context, blockID uint64, symbolsDir, layoutPath string, w io.Writer) (int64, error) {
+	layout, err := p.loadDecodeLayout(symbolsDir, layoutPath)
+	if err != nil {
+		return 0, err
+	}
+
+	block, ok := layout.Block(blockID)
+	if !ok {
+		return 0, fmt.Errorf("%w: layout has no block %d", ErrInvalidParameters, blockID)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	stageDir, err := os.MkdirTemp("", "raptorq-range-")
+	if err != nil {
+		return 0, ioError("DecodeSymbols", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	return p.decodeBlockRange(ctx, stageDir, symbolsDir, *block, 0, block.Size, w)
+}
+
+// loadDecodeLayout checks the common arguments of the partial decoding
+// functions and loads the layout.
+func (p *RaptorQProcessor) loadDecodeLayout(symbolsDir, layoutPath string) (*Layout, error) {
+	if p.SessionID == 0 {
+		return nil, ErrSessionClosed
+	}
+	if symbolsDir == "" || layoutPath == "" {
+		return nil, fmt.Errorf("%w: symbolsDir and layoutPath cannot be empty", ErrInvalidParameters)
+	}
+
+	layout, err := LoadLayout(layoutPath)
+	if err != nil {
+		if errors.Is(err, ErrInvalidLayout) {
+			return nil, err
+		}
+		return nil, ioError("DecodeSymbols", err)
+	}
+	return layout, nil
+}
+
+// decodeBlockRange decodes a block into stageDir and copies length bytes
+// starting at start within the block to w.
+func (p *RaptorQProcessor) decodeBlockRange(ctx context.Context, stageDir, symbolsDir string, block BlockLayout, start, length uint64, w io.Writer) (int64, error) {
+	blockOutput := filepath.Join(stageDir, "block.bin")
+	defer os.Remove(blockOutput)
+
+	if err := p.decodeBlockFile(ctx, stageDir, symbolsDir, block, blockOutput); err != nil {
+		return 0, err
+	}
+
+	data, err := os.Open(blockOutput)
+	if err != nil {
+		return 0, ioError("DecodeSymbols", err)
+	}
+	defer data.Close()
+
+	return io.Copy(w, io.NewSectionReader(data, int64(start), int64(length)))
+}
+
+// sortedBlocks returns the blocks of a layout ordered by offset.
+func sortedBlocks(layout *Layout) []BlockLayout {
+	blocks := append([]BlockLayout(nil), layout.Blocks...)
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].OriginalOffset < blocks[j].OriginalOffset
+	})
+	return blocks
+}
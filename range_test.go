@@ -0,0 +1,119 @@
+package rq_go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+// System test for decoding byte ranges and single blocks (3MB + 100 bytes, 1MB blocks)
+func TestSysDecodeRange(t *testing.T) {
+	processor, err := NewDefaultRaptorQProcessor()
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	ctx := NewTestContext(t, 3*1024*1024+100)
+	defer ctx.Cleanup()
+
+	blockSize := 1024 * 1024
+	res, err := processor.EncodeFile(ctx.InputFile, ctx.SymbolsDir, blockSize)
+	if err != nil {
+		t.Fatalf("Failed to encode file: %v", err)
+	}
+
+	original, err := os.ReadFile(ctx.InputFile)
+	if err != nil {
+		t.Fatalf("Failed to read input file: %v", err)
+	}
+
+	ranges := []struct {
+		offset, length uint64
+	}{
+		{0, 10},                                // start of the first block
+		{uint64(blockSize) - 5, 10},            // across the first block boundary
+		{100, 2*uint64(blockSize) + 50},        // spanning three blocks
+		{3 * uint64(blockSize), 100},           // the short last block
+		{uint64(len(original)) - 1, 1},         // the last byte
+		{uint64(blockSize), uint64(blockSize)}, // exactly one block
+		{uint64(len(original)), 0},             // empty range at the end
+	}
+	for _, r := range ranges {
+		var buf bytes.Buffer
+		n, err := processor.DecodeRange(ctx.SymbolsDir, res.LayoutFilePath, r.offset, r.length, &buf)
+		if err != nil {
+			t.Fatalf("Failed to decode range [%d, +%d): %v", r.offset, r.length, err)
+		}
+		if n != int64(r.length) || !bytes.Equal(buf.Bytes(), original[r.offset:r.offset+r.length]) {
+			t.Fatalf("Range [%d, +%d) does not match the original (%d bytes written)", r.offset, r.length, n)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := processor.DecodeBlock(2, ctx.SymbolsDir, res.LayoutFilePath, &buf); err != nil {
+		t.Fatalf("Failed to decode block 2: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), original[2*blockSize:3*blockSize]) {
+		t.Fatal("Block 2 does not match the original")
+	}
+
+	if _, err := processor.DecodeBlock(9, ctx.SymbolsDir, res.LayoutFilePath, &buf); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters for an unknown block, got: %v", err)
+	}
+	if _, err := processor.DecodeRange(ctx.SymbolsDir, res.LayoutFilePath, uint64(len(original)), 1, &buf); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters for a range past the end, got: %v", err)
+	}
+}
+
+// cancelWriter cancels a context once it has received a write
+type cancelWriter struct {
+	buf    bytes.Buffer
+	cancel context.CancelFunc
+}
+
+func (w *cancelWriter) Write(p []byte) (int, error) {
+	w.cancel()
+	return w.buf.Write(p)
+}
+
+// Unit test for cancelling range decoding between blocks
+func TestDecodeRangeContext(t *testing.T) {
+	cfg := DefaultProcessorConfig()
+	cfg.RedundancyFactor = 0
+	processor, err := newProcessor(NewPureGoBackend(), cfg)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	ctx := NewTestContext(t, 300*1024)
+	defer ctx.Cleanup()
+	blockSize := 100 * 1024
+	res, err := processor.EncodeFile(ctx.InputFile, ctx.SymbolsDir, blockSize)
+	if err != nil {
+		t.Fatalf("Failed to encode file: %v", err)
+	}
+
+	// The first block is written, then the cancellation stops the decoding
+	decodeCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &cancelWriter{cancel: cancel}
+	n, err := processor.DecodeRangeContext(decodeCtx, ctx.SymbolsDir, res.LayoutFilePath, 0, uint64(3*blockSize), w)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got: %v", err)
+	}
+	if n != int64(blockSize) || w.buf.Len() != blockSize {
+		t.Fatalf("Expected only the first block to be written, got %d bytes", n)
+	}
+
+	var buf bytes.Buffer
+	if _, err := processor.DecodeBlockContext(decodeCtx, 1, ctx.SymbolsDir, res.LayoutFilePath, &buf); !errors.Is(err, context.Canceled) || buf.Len() != 0 {
+		t.Fatalf("Expected context.Canceled without output, got %d bytes and: %v", buf.Len(), err)
+	}
+	if _, err := processor.DecodeBlockContext(context.Background(), 1, ctx.SymbolsDir, res.LayoutFilePath, &buf); err != nil || buf.Len() != blockSize {
+		t.Fatalf("Failed to decode block 1: %v", err)
+	}
+}
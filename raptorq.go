@@ -2,39 +2,19 @@
 // RaptorQ is a Forward Error Correction (FEC) code that allows for efficient recovery
 // of data from partial information. This package enables encoding files into RaptorQ
 // symbols and decoding them back, providing resilience against data loss.
+//
+// The package links the native RaptorQ library through cgo. Builds with
+// CGO_ENABLED=0 compile, but cannot create processors on the default backend;
+// see nocgo.go.
 package rq_go
 
-/*
-#cgo CFLAGS: -I${SRCDIR}/include
-
-// Platform-specific LDFLAGS for static linking
-#cgo linux LDFLAGS: -L${SRCDIR}/lib/linux/amd64 -Wl,-Bstatic -lrq_library -Wl,-Bdynamic -ldl -lm -lpthread
-#cgo darwin LDFLAGS: -L${SRCDIR}/lib/darwin/amd64 -lrq_library -framework Security -framework CoreFoundation -lm
-#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/lib/darwin/arm64 -lrq_library -framework Security -framework CoreFoundation -lm
-#cgo windows LDFLAGS: -L${SRCDIR}/lib/windows/amd64 -lrq_library -lws2_32 -luserenv -ladvapi32
-
-#include <stdlib.h>
-#include <stdint.h>
-#include <stdbool.h>
-#include <rq-library.h>
-
-// Function declarations matching the Rust library's exported functions
-extern uintptr_t raptorq_init_session(uint16_t symbol_size, uint8_t redundancy_factor, uint64_t max_memory_mb, uint64_t concurrency_limit);
-extern _Bool raptorq_free_session(uintptr_t session_id);
-extern int32_t raptorq_encode_file(uintptr_t session_id, const char *input_path, const char *output_dir, uintptr_t block_size, char *result_buffer, uintptr_t result_buffer_len);
-extern int32_t raptorq_create_metadata(uintptr_t session_id, const char *input_path, const char *layout_file, uintptr_t block_size, char *result_buffer, uintptr_t result_buffer_len);
-extern int32_t raptorq_get_last_error(uintptr_t session_id, char *error_buffer, uintptr_t error_buffer_len);
-extern int32_t raptorq_decode_symbols(uintptr_t session_id, const char *symbols_dir, const char *output_path, const char *layout_path);
-extern uintptr_t raptorq_get_recommended_block_size(uintptr_t session_id, uint64_t file_size);
-extern int32_t raptorq_version(char *version_buffer, uintptr_t version_buffer_len);
-*/
-import "C"
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"runtime"
 	"sync"
-	"unsafe"
+	"sync/atomic"
+	"time"
 )
 
 // Default configuration values for the RaptorQ processor.
@@ -72,8 +52,26 @@ const (
 var sessionMutex sync.Mutex
 
 // sessions tracks all active RaptorQ processing sessions.
-// The map uses session IDs as keys and empty structs as values to minimize memory usage.
-var sessions = make(map[uintptr]struct{})
+// The map is keyed by backend and session ID, since session IDs are only unique
+// within a backend, and records the configuration of each session and the
+// ProcessorPool that owns it, if any.
+var sessions = make(map[sessionKey]*sessionInfo)
+
+// sessionKey identifies a session in the sessions map.
+type sessionKey struct {
+	backend Backend
+	id      uintptr
+}
+
+// sessionInfo describes an active session in the sessions map.
+type sessionInfo struct {
+	// config is the configuration the session was created with.
+	config ProcessorConfig
+
+	// pool is the pool that owns the session, or nil for sessions created
+	// directly with NewRaptorQProcessor.
+	pool *ProcessorPool
+}
 
 // RaptorQProcessor represents a RaptorQ processing session that handles encoding and decoding operations.
 // Each processor maintains its own state and configuration, allowing for concurrent processing
@@ -83,6 +81,16 @@ type RaptorQProcessor struct {
 	// SessionID is the unique identifier for this processing session in the underlying C library.
 	// It's used in all C function calls to identify the specific session.
 	SessionID uintptr
+
+	// backend performs the operations of the session.
+	backend Backend
+
+	// config is the configuration the session was created with. It is used to
+	// check layouts before they are handed to the native library.
+	config ProcessorConfig
+
+	// progress is the ProgressFunc installed with SetProgressFunc, if any.
+	progress atomic.Pointer[ProgressFunc]
 }
 
 // ProcessorConfig holds configuration parameters for the RaptorQ processor.
@@ -91,19 +99,19 @@ type RaptorQProcessor struct {
 type ProcessorConfig struct {
 	// SymbolSize defines the size of each symbol in bytes.
 	// Larger symbols can improve throughput but increase memory usage.
-	SymbolSize uint16 `json:"symbol_size"`
+	SymbolSize uint16 `json:"symbol_size" yaml:"symbol_size"`
 
 	// RedundancyFactor determines how many repair symbols are generated per source symbol.
 	// Higher values provide better recovery capability but increase storage requirements.
-	RedundancyFactor uint8 `json:"redundancy_factor"`
+	RedundancyFactor uint8 `json:"redundancy_factor" yaml:"redundancy_factor"`
 
 	// MaxMemoryMB limits the maximum memory usage during encoding/decoding in megabytes.
 	// This prevents the process from consuming too much system memory.
-	MaxMemoryMB uint64 `json:"max_memory_mb"`
+	MaxMemoryMB uint64 `json:"max_memory_mb" yaml:"max_memory_mb"`
 
 	// ConcurrencyLimit controls the maximum number of concurrent operations.
 	// This helps manage CPU usage and prevents system overload.
-	ConcurrencyLimit uint64 `json:"concurrency_limit"`
+	ConcurrencyLimit uint64 `json:"concurrency_limit" yaml:"concurrency_limit"`
 }
 
 // ProcessResult holds information about the results of an encoding or metadata creation operation.
@@ -179,26 +187,37 @@ type Block struct {
 // The returned processor must be freed when no longer needed by calling the Free() method
 // to prevent memory leaks, although a finalizer is set to handle cleanup if Free() is not called.
 func NewRaptorQProcessor(symbolSize uint16, redundancyFactor uint8, maxMemoryMB uint64, concurrencyLimit uint64) (*RaptorQProcessor, error) {
-	sessionID := C.raptorq_init_session(
-		C.uint16_t(symbolSize),
-		C.uint8_t(redundancyFactor),
-		C.uint64_t(maxMemoryMB),
-		C.uint64_t(concurrencyLimit),
-	)
+	config := ProcessorConfig{
+		SymbolSize:       symbolSize,
+		RedundancyFactor: redundancyFactor,
+		MaxMemoryMB:      maxMemoryMB,
+		ConcurrencyLimit: concurrencyLimit,
+	}
+
+	return newProcessor(defaultBackend, config)
+}
 
+// newProcessor creates a session on backend and registers it.
+func newProcessor(backend Backend, config ProcessorConfig) (*RaptorQProcessor, error) {
+	if !nativeLibraryLinked && backend == defaultBackend {
+		return nil, fmt.Errorf("failed to initialize RaptorQ session: %w", ErrNativeLibraryUnavailable)
+	}
+	sessionID := backend.InitSession(config)
 	if sessionID == 0 {
 		return nil, fmt.Errorf("failed to initialize RaptorQ session")
 	}
 
+	processor := &RaptorQProcessor{
+		SessionID: sessionID,
+		backend:   backend,
+		config:    config,
+	}
+
 	// Register session
 	sessionMutex.Lock()
-	sessions[uintptr(sessionID)] = struct{}{}
+	sessions[processor.sessionKey()] = &sessionInfo{config: config}
 	sessionMutex.Unlock()
 
-	processor := &RaptorQProcessor{
-		SessionID: uintptr(sessionID),
-	}
-
 	// Set finalizer to clean up session
 	runtime.SetFinalizer(processor, finalizeProcessor)
 
@@ -207,8 +226,9 @@ func NewRaptorQProcessor(symbolSize uint16, redundancyFactor uint8, maxMemoryMB
 
 // NewDefaultRaptorQProcessor creates a new RaptorQ processor with default configuration.
 //
-// This is a convenience function that creates a processor with the following default values:
-//   - Symbol size: 65535 bytes (64KB - 1 byte)
+// This is a convenience function that creates a processor with the configuration
+// returned by DefaultProcessorConfig:
+//   - Symbol size: 65528 bytes (DefaultSymbolSize rounded down to SymbolAlignment)
 //   - Redundancy factor: 4 (4 repair symbols per source symbol)
 //   - Max memory: 16GB
 //   - Concurrency limit: 4 threads
@@ -225,12 +245,7 @@ func NewRaptorQProcessor(symbolSize uint16, redundancyFactor uint8, maxMemoryMB
 //	}
 //	defer processor.Free()
 func NewDefaultRaptorQProcessor() (*RaptorQProcessor, error) {
-	return NewRaptorQProcessor(
-		DefaultSymbolSize,
-		DefaultRedundancyFactor,
-		DefaultMaxMemoryMB,
-		DefaultConcurrencyLimit,
-	)
+	return NewProcessorFromConfig(DefaultProcessorConfig())
 }
 
 // Free manually frees the RaptorQ session and releases associated resources.
@@ -253,13 +268,12 @@ func NewDefaultRaptorQProcessor() (*RaptorQProcessor, error) {
 // if it's not called manually, but explicit calls are preferred for deterministic cleanup.
 func (p *RaptorQProcessor) Free() bool {
 	if p.SessionID != 0 {
-		result := C.raptorq_free_session(C.uintptr_t(p.SessionID))
-		success := bool(result)
+		success := p.backend.FreeSession(p.SessionID)
 
 		if success {
 			// Unregister session
 			sessionMutex.Lock()
-			delete(sessions, p.SessionID)
+			delete(sessions, p.sessionKey())
 			sessionMutex.Unlock()
 
 			p.SessionID = 0
@@ -288,6 +302,9 @@ func finalizeProcessor(p *RaptorQProcessor) {
 // the resulting symbols to the outputDir directory. The file is split into blocks
 // of the specified size, and each block is encoded separately.
 //
+// If a ProgressFunc is installed with SetProgressFunc, the file is encoded block
+// by block as by EncodeFileContext, and progress events are reported for every block.
+//
 // Parameters:
 //   - inputPath: Path to the input file to be encoded.
 //   - outputDir: Directory where the encoded symbols will be written.
@@ -300,13 +317,17 @@ func finalizeProcessor(p *RaptorQProcessor) {
 //   - error: An error if the encoding process fails. The error message will include details
 //     about the specific failure.
 //
-// Possible error conditions include:
-//   - Session closed
-//   - File not found
-//   - I/O errors
-//   - Memory limit exceeded
-//   - Concurrency limit reached
-//   - Invalid parameters
+// Possible error conditions include (match them with errors.Is):
+//   - Session closed (ErrSessionClosed)
+//   - File not found (ErrFileNotFound)
+//   - I/O errors (ErrIO)
+//   - Invalid paths (ErrInvalidPath)
+//   - Encoding failure (ErrEncodingFailed)
+//   - Memory limit exceeded (ErrMemoryLimit)
+//   - Concurrency limit reached (ErrConcurrencyLimit)
+//   - Invalid parameters (ErrInvalidParameters)
+//
+// Failures reported by the native library are returned as *RaptorQError.
 //
 // Example:
 //
@@ -324,65 +345,32 @@ func finalizeProcessor(p *RaptorQProcessor) {
 //
 //	fmt.Printf("Encoded file with %d total symbols\n", result.TotalSymbolsCount)
 func (p *RaptorQProcessor) EncodeFile(inputPath, outputDir string, blockSize int) (*ProcessResult, error) {
-	if p.SessionID == 0 {
-		return nil, fmt.Errorf("RaptorQ session is closed")
+	if p.progressFunc(nil) != nil {
+		return p.EncodeFileContext(context.Background(), inputPath, outputDir, blockSize)
 	}
+	ctx, trace := p.startOperation(context.Background(), "EncodeFile", intAttr(AttrBlockSize, int64(blockSize)))
+	result, err := p.encodeFile(ctx, inputPath, outputDir, blockSize)
+	trace.endResult(result, err)
+	return result, err
+}
 
-	cInputPath := C.CString(inputPath)
-	defer C.free(unsafe.Pointer(cInputPath))
-
-	cOutputDir := C.CString(outputDir)
-	defer C.free(unsafe.Pointer(cOutputDir))
-
-	// Buffer for result (16KB should be enough for metadata)
-	resultBufSize := 16 * 1024
-	resultBuf := (*C.char)(C.malloc(C.size_t(resultBufSize)))
-	defer C.free(unsafe.Pointer(resultBuf))
-
-	res := C.raptorq_encode_file(
-		C.uintptr_t(p.SessionID),
-		cInputPath,
-		cOutputDir,
-		C.uintptr_t(blockSize),
-		resultBuf,
-		C.uintptr_t(resultBufSize),
-	)
-
-	switch res {
-	case 0:
-		// Success
-	case -1:
-		return nil, fmt.Errorf("generic error")
-	case -2:
-		return nil, fmt.Errorf("invalid parameters")
-	case -3:
-		return nil, fmt.Errorf("invalid response (JSON serialization error)")
-	case -4:
-		return nil, fmt.Errorf("result buffer too small")
-	case -5:
-		return nil, fmt.Errorf("invalid session")
-	case -11:
-		return nil, fmt.Errorf("IO error: %s", p.getLastError())
-	case -12:
-		return nil, fmt.Errorf("file not found: %s", p.getLastError())
-	case -13:
-		return nil, fmt.Errorf("encoding failed: %s", p.getLastError())
-	case -15:
-		return nil, fmt.Errorf("memory limit exceeded: %s", p.getLastError())
-	case -16:
-		return nil, fmt.Errorf("concurrency limit reached: %s", p.getLastError())
-	default:
-		return nil, fmt.Errorf("unknown error code %d: %s", res, p.getLastError())
+// encodeFile performs a single native EncodeFile call, traced as a child of
+// the span of ctx.
+func (p *RaptorQProcessor) encodeFile(ctx context.Context, inputPath, outputDir string, blockSize int) (*ProcessResult, error) {
+	if p.SessionID == 0 {
+		return nil, ErrSessionClosed
 	}
 
-	// Parse the JSON result
-	resultJSON := C.GoString(resultBuf)
-	var result ProcessResult
-	if err := json.Unmarshal([]byte(resultJSON), &result); err != nil {
-		return nil, fmt.Errorf("failed to parse result: %w", err)
+	_, trace := p.startSpan(ctx, "rq.native.EncodeFile", intAttr(AttrBlockSize, int64(blockSize)))
+	start := time.Now()
+	result, res := p.backend.EncodeFile(p.SessionID, inputPath, outputDir, blockSize)
+	err := p.resultError("EncodeFile", res)
+	observeResult("EncodeFile", start, result, err)
+	trace.endResult(result, err)
+	if err != nil {
+		return nil, err
 	}
-
-	return &result, nil
+	return result, nil
 }
 
 // CreateMetadata creates metadata for RaptorQ encoding without writing symbol data.
@@ -395,6 +383,9 @@ func (p *RaptorQProcessor) EncodeFile(inputPath, outputDir string, blockSize int
 // The function writes the layout information to the specified layout file, which contains
 // details about how the file would be split into blocks and encoded.
 //
+// If a ProgressFunc is installed with SetProgressFunc, the file is processed block
+// by block as by CreateMetadataContext, and progress events are reported for every block.
+//
 // Parameters:
 //   - inputPath: Path to the input file to analyze.
 //   - layoutFile: Path where the layout information will be written.
@@ -407,14 +398,17 @@ func (p *RaptorQProcessor) EncodeFile(inputPath, outputDir string, blockSize int
 //   - error: An error if the metadata creation process fails. The error message will include
 //     details about the specific failure.
 //
-// Possible error conditions include:
-//   - Session closed
-//   - File not found
-//   - I/O errors
-//   - Invalid paths
-//   - Memory limit exceeded
-//   - Concurrency limit reached
-//   - Invalid parameters
+// Possible error conditions include (match them with errors.Is):
+//   - Session closed (ErrSessionClosed)
+//   - File not found (ErrFileNotFound)
+//   - I/O errors (ErrIO)
+//   - Invalid paths (ErrInvalidPath)
+//   - Encoding failure (ErrEncodingFailed)
+//   - Memory limit exceeded (ErrMemoryLimit)
+//   - Concurrency limit reached (ErrConcurrencyLimit)
+//   - Invalid parameters (ErrInvalidParameters)
+//
+// Failures reported by the native library are returned as *RaptorQError.
 //
 // Example:
 //
@@ -432,67 +426,32 @@ func (p *RaptorQProcessor) EncodeFile(inputPath, outputDir string, blockSize int
 //
 //	fmt.Printf("File would be encoded with %d total symbols\n", result.TotalSymbolsCount)
 func (p *RaptorQProcessor) CreateMetadata(inputPath, layoutFile string, blockSize int) (*ProcessResult, error) {
-	if p.SessionID == 0 {
-		return nil, fmt.Errorf("RaptorQ session is closed")
+	if p.progressFunc(nil) != nil {
+		return p.CreateMetadataContext(context.Background(), inputPath, layoutFile, blockSize)
 	}
+	ctx, trace := p.startOperation(context.Background(), "CreateMetadata", intAttr(AttrBlockSize, int64(blockSize)))
+	result, err := p.createMetadata(ctx, inputPath, layoutFile, blockSize)
+	trace.endResult(result, err)
+	return result, err
+}
 
-	cInputPath := C.CString(inputPath)
-	defer C.free(unsafe.Pointer(cInputPath))
-
-	cLayoutFile := C.CString(layoutFile)
-	defer C.free(unsafe.Pointer(cLayoutFile))
-
-	// Buffer for result (16KB should be enough for metadata)
-	resultBufSize := 16 * 1024
-	resultBuf := (*C.char)(C.malloc(C.size_t(resultBufSize)))
-	defer C.free(unsafe.Pointer(resultBuf))
-
-	res := C.raptorq_create_metadata(
-		C.uintptr_t(p.SessionID),
-		cInputPath,
-		cLayoutFile,
-		C.uintptr_t(blockSize),
-		resultBuf,
-		C.uintptr_t(resultBufSize),
-	)
-
-	switch res {
-	case 0:
-		// Success
-	case -1:
-		return nil, fmt.Errorf("generic error")
-	case -2:
-		return nil, fmt.Errorf("invalid parameters")
-	case -3:
-		return nil, fmt.Errorf("invalid response (JSON serialization error)")
-	case -4:
-		return nil, fmt.Errorf("result buffer too small")
-	case -5:
-		return nil, fmt.Errorf("invalid session")
-	case -11:
-		return nil, fmt.Errorf("IO error: %s", p.getLastError())
-	case -12:
-		return nil, fmt.Errorf("file not found: %s", p.getLastError())
-	case -13:
-		return nil, fmt.Errorf("invalid path: %s", p.getLastError())
-	case -14:
-		return nil, fmt.Errorf("encoding failed: %s", p.getLastError())
-	case -16:
-		return nil, fmt.Errorf("memory limit exceeded: %s", p.getLastError())
-	case -17:
-		return nil, fmt.Errorf("concurrency limit reached: %s", p.getLastError())
-	default:
-		return nil, fmt.Errorf("unknown error code %d: %s", res, p.getLastError())
+// createMetadata performs a single native CreateMetadata call, traced as a
+// child of the span of ctx.
+func (p *RaptorQProcessor) createMetadata(ctx context.Context, inputPath, layoutFile string, blockSize int) (*ProcessResult, error) {
+	if p.SessionID == 0 {
+		return nil, ErrSessionClosed
 	}
 
-	// Parse the JSON result
-	resultJSON := C.GoString(resultBuf)
-	var result ProcessResult
-	if err := json.Unmarshal([]byte(resultJSON), &result); err != nil {
-		return nil, fmt.Errorf("failed to parse result: %w", err)
+	_, trace := p.startSpan(ctx, "rq.native.CreateMetadata", intAttr(AttrBlockSize, int64(blockSize)))
+	start := time.Now()
+	result, res := p.backend.CreateMetadata(p.SessionID, inputPath, layoutFile, blockSize)
+	err := p.resultError("CreateMetadata", res)
+	observeResult("CreateMetadata", start, result, err)
+	trace.endResult(result, err)
+	if err != nil {
+		return nil, err
 	}
-
-	return &result, nil
+	return result, nil
 }
 
 // DecodeSymbols decodes RaptorQ symbols back to the original file.
@@ -502,6 +461,9 @@ func (p *RaptorQProcessor) CreateMetadata(inputPath, layoutFile string, blockSiz
 // The RaptorQ algorithm can recover the original file even if some symbols are missing,
 // as long as enough symbols are available.
 //
+// If a ProgressFunc is installed with SetProgressFunc, the layout is decoded block
+// by block as by DecodeSymbolsContext, and progress events are reported for every block.
+//
 // Parameters:
 //   - symbolsDir: Directory containing the encoded symbols.
 //   - outputPath: Path where the reconstructed file will be written.
@@ -512,15 +474,19 @@ func (p *RaptorQProcessor) CreateMetadata(inputPath, layoutFile string, blockSiz
 //   - error: An error if the decoding process fails. The error message will include
 //     details about the specific failure. Returns nil on success.
 //
-// Possible error conditions include:
-//   - Session closed
-//   - Empty parameters
-//   - File not found
-//   - I/O errors
-//   - Insufficient symbols for recovery
-//   - Memory limit exceeded
-//   - Concurrency limit reached
-//   - Invalid parameters
+// Possible error conditions include (match them with errors.Is):
+//   - Session closed (ErrSessionClosed)
+//   - Empty parameters (ErrInvalidParameters)
+//   - Encoder parameters that do not match the block sizes or the processor's
+//     symbol size (ErrInvalidLayout, see CheckLayout)
+//   - File not found (ErrFileNotFound)
+//   - I/O errors (ErrIO)
+//   - Insufficient symbols for recovery (ErrInsufficientSymbols)
+//   - Other decoder failures (ErrDecodingFailed)
+//   - Memory limit exceeded (ErrMemoryLimit)
+//   - Concurrency limit reached (ErrConcurrencyLimit)
+//
+// Failures reported by the native library are returned as *RaptorQError.
 //
 // Example:
 //
@@ -538,57 +504,53 @@ func (p *RaptorQProcessor) CreateMetadata(inputPath, layoutFile string, blockSiz
 //
 //	fmt.Println("File successfully recovered")
 func (p *RaptorQProcessor) DecodeSymbols(symbolsDir, outputPath, layoutPath string) error {
+	if p.progressFunc(nil) != nil {
+		return p.DecodeSymbolsContext(context.Background(), symbolsDir, outputPath, layoutPath)
+	}
+	ctx, trace := p.startOperation(context.Background(), "DecodeSymbols")
+	layout, err := LoadLayout(layoutPath)
+	if err != nil {
+		layout = nil
+	} else if trace != nil {
+		trace.setAttributes(layoutAttributes(layout)...)
+	}
+	err = p.decodeSymbols(ctx, symbolsDir, outputPath, layoutPath, layout)
+	trace.end(err)
+	return err
+}
+
+// decodeSymbols performs a single native DecodeSymbols call for a layout,
+// traced as a child of the span of ctx. layout is the content of layoutPath,
+// loaded by the caller, or nil if it could not be read.
+func (p *RaptorQProcessor) decodeSymbols(ctx context.Context, symbolsDir, outputPath, layoutPath string, layout *Layout) error {
 	if p.SessionID == 0 {
-		return fmt.Errorf("RaptorQ session is closed")
+		return ErrSessionClosed
 	}
 
 	// Input validation
 	if symbolsDir == "" || outputPath == "" || layoutPath == "" {
-		return fmt.Errorf("symbolsDir, outputPath, and layoutPath cannot be empty")
+		return fmt.Errorf("%w: symbolsDir, outputPath, and layoutPath cannot be empty", ErrInvalidParameters)
 	}
 
-	cSymbolsDir := C.CString(symbolsDir)
-	defer C.free(unsafe.Pointer(cSymbolsDir))
-
-	cOutputPath := C.CString(outputPath)
-	defer C.free(unsafe.Pointer(cOutputPath))
-
-	cLayoutPath := C.CString(layoutPath)
-	defer C.free(unsafe.Pointer(cLayoutPath))
-
-	res := C.raptorq_decode_symbols(
-		C.uintptr_t(p.SessionID),
-		cSymbolsDir,
-		cOutputPath,
-		cLayoutPath,
-	)
-
-	switch res {
-	case 0:
-		return nil
-	case -1:
-		return fmt.Errorf("generic error")
-	case -2:
-		return fmt.Errorf("invalid parameters")
-	case -3:
-		return fmt.Errorf("invalid response") // Assuming similar meaning as encode
-	case -4:
-		return fmt.Errorf("bad return buffer size") // Assuming similar meaning as encode
-	case -5:
-		return fmt.Errorf("invalid session")
-	case -11:
-		return fmt.Errorf("IO error: %s", p.getLastError())
-	case -12:
-		return fmt.Errorf("file not found: %s", p.getLastError())
-	case -14:
-		return fmt.Errorf("decoding failed: %s", p.getLastError())
-	case -15:
-		return fmt.Errorf("memory limit exceeded: %s", p.getLastError())
-	case -16:
-		return fmt.Errorf("concurrency limit reached: %s", p.getLastError())
-	default:
-		return fmt.Errorf("unknown error code %d: %s", res, p.getLastError())
+	// Catch layouts that do not match this processor before the native decoder
+	// runs. Layouts that cannot be read are left to the native library to report.
+	start := time.Now()
+	_, trace := p.startSpan(ctx, "rq.native.DecodeSymbols")
+	if layout != nil {
+		trace.setAttributes(layoutAttributes(layout)...)
+		if err := p.CheckLayout(layout); err != nil {
+			err = fmt.Errorf("DecodeSymbols: %s: %w", layoutPath, err)
+			observeDecode("DecodeSymbols", start, layout, err)
+			trace.end(err)
+			return err
+		}
 	}
+
+	res := p.backend.DecodeSymbols(p.SessionID, symbolsDir, outputPath, layoutPath)
+	err := p.resultError("DecodeSymbols", res)
+	observeDecode("DecodeSymbols", start, layout, err)
+	trace.end(err)
+	return err
 }
 
 // GetRecommendedBlockSize returns a recommended block size for a file of the given size.
@@ -625,21 +587,29 @@ func (p *RaptorQProcessor) DecodeSymbols(symbolsDir, outputPath, layoutPath stri
 //	blockSize := processor.GetRecommendedBlockSize(uint64(fileInfo.Size()))
 //	fmt.Printf("Recommended block size: %d bytes\n", blockSize)
 func (p *RaptorQProcessor) GetRecommendedBlockSize(fileSize uint64) int {
+	return p.recommendedBlockSize(context.Background(), fileSize)
+}
+
+// recommendedBlockSize implements GetRecommendedBlockSize, traced as a child
+// of the span of ctx.
+func (p *RaptorQProcessor) recommendedBlockSize(ctx context.Context, fileSize uint64) int {
 	if p.SessionID == 0 {
 		return 0
 	}
 
-	return int(C.raptorq_get_recommended_block_size(
-		C.uintptr_t(p.SessionID),
-		C.uint64_t(fileSize),
-	))
+	_, trace := p.startOperation(ctx, "GetRecommendedBlockSize", intAttr(AttrFileSize, int64(fileSize)))
+	blockSize := p.backend.RecommendedBlockSize(p.SessionID, fileSize)
+	trace.setAttributes(intAttr(AttrBlockSize, int64(blockSize)))
+	trace.end(nil)
+	return blockSize
 }
 
 // GetVersion returns the version of the underlying RaptorQ library.
 //
-// This function queries the C library for its version string, which typically
-// includes the version number and possibly build information. This can be useful
-// for logging, debugging, or ensuring compatibility with specific library versions.
+// This function queries the default backend (see DefaultBackend) for its version
+// string, which typically includes the version number and possibly build
+// information. This can be useful for logging, debugging, or ensuring
+// compatibility with specific library versions.
 //
 // Returns:
 //   - string: The version string of the RaptorQ library. Returns "Unknown version"
@@ -650,17 +620,23 @@ func (p *RaptorQProcessor) GetRecommendedBlockSize(fileSize uint64) int {
 //	version := raptorq.GetVersion()
 //	fmt.Printf("Using RaptorQ library version: %s\n", version)
 func GetVersion() string {
-	bufSize := 128
-	versionBuf := (*C.char)(C.malloc(C.size_t(bufSize)))
-	defer C.free(unsafe.Pointer(versionBuf))
-
-	res := C.raptorq_version(versionBuf, C.size_t(bufSize))
-
-	if res != 0 {
+	version, ok := defaultBackend.Version()
+	if !ok {
 		return "Unknown version"
 	}
+	return version
+}
 
-	return C.GoString(versionBuf)
+// resultError converts a native return code into an error.
+//
+// It returns nil for a successful call and otherwise a *RaptorQError built from
+// the shared code table, attaching the session's last error message when the
+// native library records one for that code.
+func (p *RaptorQProcessor) resultError(op string, res ErrorCode) error {
+	if res == CodeSuccess {
+		return nil
+	}
+	return newRaptorQError(op, res, p.getLastError)
 }
 
 // getLastError retrieves the last error message from the RaptorQ library for this session.
@@ -678,19 +654,9 @@ func (p *RaptorQProcessor) getLastError() string {
 		return "Session closed"
 	}
 
-	bufSize := 1024
-	errorBuf := (*C.char)(C.malloc(C.size_t(bufSize)))
-	defer C.free(unsafe.Pointer(errorBuf))
-
-	result := C.raptorq_get_last_error(
-		C.uintptr_t(p.SessionID),
-		errorBuf,
-		C.size_t(bufSize),
-	)
-
-	if result != 0 {
+	msg, ok := p.backend.LastError(p.SessionID)
+	if !ok {
 		return "Error retrieving error message"
 	}
-
-	return C.GoString(errorBuf)
+	return msg
 }
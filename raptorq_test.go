@@ -664,6 +664,7 @@ func TestSysCreateMetadataWithBlockSize(t *testing.T) {
 // Go-specific test for FFI interactions
 func TestGoSpecificFFIInteractions(t *testing.T) {
 	// This test verifies Go string/slice handling with C functions
+	skipWithoutNativeLibrary(t)
 
 	// Test creating and freeing a session
 	processor, err := NewDefaultRaptorQProcessor()
@@ -0,0 +1,271 @@
+package rq_go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// RepairResult is the result of RepairSymbols.
+type RepairResult struct {
+	// Blocks lists the blocks that had symbols restored, in layout order.
+	Blocks []RepairedBlock `json:"blocks"`
+
+	// Restored is the total number of symbols restored.
+	Restored int `json:"restored"`
+}
+
+// RepairedBlock lists the symbols restored in one block.
+type RepairedBlock struct {
+	// BlockID is the identifier of the block.
+	BlockID uint64 `json:"block_id"`
+
+	// Restored lists the IDs of the restored symbols, sorted.
+	Restored []string `json:"restored"`
+}
+
+// RepairSymbols is like RepairSymbolsContext but without cancellation.
+func (p *RaptorQProcessor) RepairSymbols(symbolsDir, layoutPath string, missingIDs []string) (*RepairResult, error) {
+	return p.RepairSymbolsContext(context.Background(), symbolsDir, layoutPath, missingIDs)
+}
+
+// RepairSymbolsContext regenerates the listed symbols of a symbols directory
+// from the surviving ones, restoring exactly the symbols of the layout: the
+// regenerated symbols have the same encoding symbol IDs and therefore the same
+// content and hashes as the lost ones, so the layout stays valid and no other
+// symbol is touched.
+//
+// The work is done block by block: only the blocks holding a listed symbol
+// are decoded, from the intact symbols of the block, and encoded again with
+// the encoder parameters of the layout. Corrupt symbols are ignored when
+// decoding, so missingIDs may list both missing and corrupt symbols, as
+// reported by VerifySymbols. If the layout has more repair symbols than the
+// processor generates, a temporary session with a sufficient redundancy
+// factor is used for encoding.
+//
+// The pure-Go backend does not generate repair symbols, so the blocks it
+// encodes have no symbol to spare: with it, RepairSymbols cannot restore any
+// lost or corrupt symbol and fails with ErrInsufficientSymbols.
+//
+// Blocks are restored one after the other; if an error occurs, the symbols of
+// the blocks processed before stay restored.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation between blocks.
+//   - symbolsDir: Directory containing the encoded symbols.
+//   - layoutPath: Path to the layout file the symbols were encoded with.
+//   - missingIDs: IDs of the symbols to regenerate. Every ID must be listed in
+//     the layout.
+//
+// Returns:
+//   - *RepairResult: The symbols restored per block.
+//   - error: An error matching ErrInvalidParameters if an ID is not in the
+//     layout, ErrInsufficientSymbols if a block has too few intact symbols to be
+//     decoded, ErrHashMismatch if a decoded block or a regenerated symbol does
+//     not match the layout, the errors of DecodeSymbols and EncodeFile, or
+//     ctx.Err().
+//
+// Example:
+//
+//	report, err := raptorq.VerifySymbols("symbols/", layout)
+//	if err != nil {
+//	    return err
+//	}
+//	var lost []string
+//	for _, b := range report.Blocks {
+//	    lost = append(append(lost, b.Missing...), b.Corrupt...)
+//	}
+//	result, err := processor.RepairSymbolsContext(ctx, "symbols/", "symbols/_raptorq_layout.json", lost)
+func (p *RaptorQProcessor) RepairSymbolsContext(ctx context.Context, symbolsDir, layoutPath string, missingIDs []string) (*RepairResult, error) {
+	layout, err := p.loadDecodeLayout(symbolsDir, layoutPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckLayout(layout); err != nil {
+		return nil, err
+	}
+
+	// Group the listed symbols by block
+	blockOf := make(map[string]int)
+	for i, block := range layout.Blocks {
+		for _, id := range block.Symbols {
+			blockOf[id] = i
+		}
+	}
+	lost := make(map[int][]string)
+	seen := make(map[string]bool)
+	for _, id := range missingIDs {
+		i, ok := blockOf[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: symbol %q is not listed in the layout", ErrInvalidParameters, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			lost[i] = append(lost[i], id)
+		}
+	}
+
+	result := &RepairResult{Blocks: []RepairedBlock{}}
+	for i, block := range layout.Blocks {
+		ids := lost[i]
+		if len(ids) == 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		sort.Strings(ids)
+		if err := p.repairBlock(ctx, symbolsDir, block, ids); err != nil {
+			return nil, fmt.Errorf("block %d: %w", block.BlockID, err)
+		}
+		result.Blocks = append(result.Blocks, RepairedBlock{BlockID: block.BlockID, Restored: ids})
+		result.Restored += len(ids)
+	}
+	return result, nil
+}
+
+// repairBlock regenerates the symbols ids of a block.
+func (p *RaptorQProcessor) repairBlock(ctx context.Context, symbolsDir string, block BlockLayout, ids []string) error {
+	stageDir, err := os.MkdirTemp("", "raptorq-repair-")
+	if err != nil {
+		return ioError("RepairSymbols", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	regenerated, _, err := p.regenerateBlock(ctx, stageDir, symbolsDir, block, len(block.Symbols))
+	if err != nil {
+		return err
+	}
+
+	dir := blockSymbolsDir(symbolsDir, block.BlockID)
+	for _, id := range ids {
+		data, err := os.ReadFile(filepath.Join(regenerated, id))
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: symbol %s was not regenerated", ErrEncodingFailed, id)
+		} else if err != nil {
+			return ioError("RepairSymbols", err)
+		}
+		if hashBytes(data) != id {
+			return fmt.Errorf("%w: regenerated symbol %s does not match its ID", ErrHashMismatch, id)
+		}
+		if err := writeFileAtomic(filepath.Join(dir, id), data, 0644); err != nil {
+			return ioError("RepairSymbols", err)
+		}
+	}
+	return nil
+}
+
+// regenerateBlock decodes a block from its intact symbols in symbolsDir and
+// encodes it again into stageDir, generating at least symbols symbols. It
+// returns the directory holding the regenerated symbols and their IDs in
+// encoding symbol ID order.
+func (p *RaptorQProcessor) regenerateBlock(ctx context.Context, stageDir, symbolsDir string, block BlockLayout, symbols int) (string, []string, error) {
+	// Only intact symbols are given to the decoder
+	check, err := verifyBlockSymbols(symbolsDir, block)
+	if err != nil {
+		return "", nil, err
+	}
+	if !check.Decodable {
+		return "", nil, fmt.Errorf("%w: %d of %d required symbols are intact",
+			ErrInsufficientSymbols, len(check.Valid), check.SourceSymbols)
+	}
+	intactDir := filepath.Join(stageDir, "intact")
+	blockDir := filepath.Join(intactDir, blockDirName(block.BlockID))
+	if err := os.MkdirAll(blockDir, 0755); err != nil {
+		return "", nil, ioError("RepairSymbols", err)
+	}
+	for _, id := range check.Valid {
+		if err := linkOrCopy(filepath.Join(check.Directory, id), filepath.Join(blockDir, id)); err != nil {
+			return "", nil, ioError("RepairSymbols", err)
+		}
+	}
+
+	blockFile := filepath.Join(stageDir, "block.bin")
+	if err := p.decodeBlockFile(ctx, stageDir, intactDir, block, blockFile); err != nil {
+		return "", nil, err
+	}
+	if hash, err := hashFile(blockFile); err != nil {
+		return "", nil, ioError("RepairSymbols", err)
+	} else if hash != block.Hash {
+		return "", nil, fmt.Errorf("%w: decoded block has hash %s, layout lists %s", ErrHashMismatch, hash, block.Hash)
+	}
+	if err := os.RemoveAll(intactDir); err != nil {
+		return "", nil, ioError("RepairSymbols", err)
+	}
+
+	oti, err := block.OTI()
+	if err != nil {
+		return "", nil, err
+	}
+	encoder, err := p.withRedundancy(redundancyFor(oti.SourceSymbols(), symbols))
+	if err != nil {
+		return "", nil, err
+	}
+	if encoder != p {
+		defer encoder.Free()
+	}
+
+	outDir := filepath.Join(stageDir, "out")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return "", nil, ioError("RepairSymbols", err)
+	}
+	outLayout := filepath.Join(outDir, layoutFileName)
+	if _, err := encoder.runBlock(ctx, true, blockFile, outDir, outLayout, int(block.Size)); err != nil {
+		return "", nil, err
+	}
+	doc, err := LoadLayout(outLayout)
+	if err != nil {
+		return "", nil, ioError("RepairSymbols", err)
+	}
+	if len(doc.Blocks) != 1 || !bytes.Equal(doc.Blocks[0].EncoderParameters, block.EncoderParameters) {
+		return "", nil, fmt.Errorf("%w: the block was not encoded with the encoder parameters of the layout", ErrEncodingFailed)
+	}
+	return filepath.Join(outDir, blockDirName(0)), doc.Blocks[0].Symbols, nil
+}
+
+// redundancyFor returns the redundancy factor that generates at least symbols
+// symbols for a block of source source symbols.
+func redundancyFor(source, symbols int) uint8 {
+	if source <= 0 || symbols <= source {
+		return 0
+	}
+	return uint8(min((symbols-source+source-1)/source, 255))
+}
+
+// withRedundancy returns p if its redundancy factor is at least factor, or a
+// new processor on the same backend with the configuration of p and the given
+// redundancy factor, which the caller must free.
+func (p *RaptorQProcessor) withRedundancy(factor uint8) (*RaptorQProcessor, error) {
+	if factor <= p.config.RedundancyFactor {
+		return p, nil
+	}
+	cfg := p.config
+	cfg.RedundancyFactor = factor
+	return newProcessor(p.backend, cfg)
+}
+
+// linkOrCopy hard-links src to dst, or copies it if linking fails.
+func linkOrCopy(src, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,129 @@
+package rq_go_test
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	raptorq "github.com/LumeraProtocol/rq-go"
+	"github.com/LumeraProtocol/rq-go/rqtest"
+)
+
+// newFakeProcessor returns a processor on a new rqtest.FakeBackend, which
+// decodes a block only from enough intact symbols of its directory
+func newFakeProcessor(t *testing.T) *raptorq.RaptorQProcessor {
+	t.Helper()
+	processor, err := raptorq.NewBackendProcessor(rqtest.NewFakeBackend(), raptorq.DefaultProcessorConfig())
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	t.Cleanup(func() { processor.Free() })
+	return processor
+}
+
+// encodeFake encodes size random bytes with processor into dir/symbols in
+// blocks of blockSize bytes
+func encodeFake(t *testing.T, processor *raptorq.RaptorQProcessor, dir string, size, blockSize int) (string, *raptorq.ProcessResult) {
+	t.Helper()
+	data := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(data)
+	inputPath := filepath.Join(dir, "input.bin")
+	if err := os.WriteFile(inputPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	symbolsDir := filepath.Join(dir, "symbols")
+	res, err := processor.EncodeFile(inputPath, symbolsDir, blockSize)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	return symbolsDir, res
+}
+
+// blockDirName returns the name of the symbol directory of a block
+func blockDirName(blockID uint64) string {
+	return fmt.Sprintf("block_%d", blockID)
+}
+
+// dropSymbols removes the symbol files ids of a block
+func dropSymbols(t *testing.T, symbolsDir string, blockID uint64, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		if err := os.Remove(filepath.Join(symbolsDir, blockDirName(blockID), id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkSymbols checks that every symbol of the layout is stored in its block
+// directory with content matching its ID
+func checkSymbols(t *testing.T, symbolsDir string, layout *raptorq.Layout) {
+	t.Helper()
+	for _, block := range layout.Blocks {
+		for _, id := range block.Symbols {
+			data, err := os.ReadFile(filepath.Join(symbolsDir, blockDirName(block.BlockID), id))
+			if err != nil {
+				t.Fatalf("Block %d: symbol %s: %v", block.BlockID, id, err)
+			}
+			if raptorq.ContentHash(data) != id {
+				t.Fatalf("Block %d: symbol %s does not match its hash", block.BlockID, id)
+			}
+		}
+	}
+}
+
+// Unit test for regenerating lost source and repair symbols from the surviving
+// symbols, decoded by the fake backend
+func TestRepairSymbolsFakeBackend(t *testing.T) {
+	processor := newFakeProcessor(t)
+	symbolsDir, res := encodeFake(t, processor, t.TempDir(), 3*1024*1024+100, 1024*1024)
+	layout, err := raptorq.LoadLayout(res.LayoutFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSymbols(t, symbolsDir, layout)
+
+	// Lose a source and a repair symbol of block 1 and corrupt another source
+	// symbol of block 2
+	block := layout.Blocks[1]
+	lost := []string{block.Symbols[0], block.Symbols[len(block.Symbols)-1]}
+	dropSymbols(t, symbolsDir, block.BlockID, lost...)
+	corrupt := layout.Blocks[2].Symbols[1]
+	if err := os.WriteFile(filepath.Join(symbolsDir, blockDirName(2), corrupt), []byte("corrupt"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	report, err := raptorq.VerifySymbols(symbolsDir, layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Healthy() || len(report.Blocks[1].Missing) != 2 || len(report.Blocks[2].Corrupt) != 1 {
+		t.Fatalf("Expected the lost symbols to be reported, got: %+v", report)
+	}
+
+	repaired, err := processor.RepairSymbols(symbolsDir, res.LayoutFilePath, append(lost, corrupt))
+	if err != nil {
+		t.Fatalf("Failed to repair symbols: %v", err)
+	}
+	if repaired.Restored != 3 || len(repaired.Blocks) != 2 ||
+		repaired.Blocks[0].BlockID != 1 || repaired.Blocks[1].BlockID != 2 {
+		t.Fatalf("Unexpected repair result: %+v", repaired)
+	}
+	checkSymbols(t, symbolsDir, layout)
+	if report, err := raptorq.VerifySymbols(symbolsDir, layout); err != nil || !report.Healthy() {
+		t.Fatalf("Symbols are not healthy after repair: %v", err)
+	}
+
+	// A block with fewer intact symbols than source symbols cannot be repaired
+	block = layout.Blocks[0]
+	oti, err := block.OTI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	spare := block.Symbols[oti.SourceSymbols()-1:]
+	dropSymbols(t, symbolsDir, block.BlockID, spare...)
+	if _, err := processor.RepairSymbols(symbolsDir, res.LayoutFilePath, spare); !errors.Is(err, raptorq.ErrInsufficientSymbols) {
+		t.Fatalf("Expected ErrInsufficientSymbols, got: %v", err)
+	}
+}
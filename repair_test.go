@@ -0,0 +1,103 @@
+package rq_go
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Unit test for the redundancy factor needed to regenerate a number of symbols
+func TestRedundancyFor(t *testing.T) {
+	tests := []struct {
+		source, symbols int
+		want            uint8
+	}{
+		{10, 10, 0},
+		{10, 11, 1},
+		{10, 50, 4},
+		{10, 51, 5},
+		{1, 1000, 255},
+		{0, 5, 0},
+	}
+	for _, tt := range tests {
+		if got := redundancyFor(tt.source, tt.symbols); got != tt.want {
+			t.Errorf("redundancyFor(%d, %d) = %d, expected %d", tt.source, tt.symbols, got, tt.want)
+		}
+	}
+}
+
+// System test regenerating lost symbols of an encoded file (3MB, 1MB blocks)
+func TestSysRepairSymbols(t *testing.T) {
+	processor, err := NewDefaultRaptorQProcessor()
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	ctx := NewTestContext(t, 3*1024*1024+100)
+	defer ctx.Cleanup()
+	res, err := processor.EncodeFile(ctx.InputFile, ctx.SymbolsDir, 1024*1024)
+	if err != nil {
+		t.Fatalf("Failed to encode file: %v", err)
+	}
+	layout, err := LoadLayout(res.LayoutFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := processor.RepairSymbols(ctx.SymbolsDir, res.LayoutFilePath, []string{"unknown"}); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("Expected ErrInvalidParameters for a symbol outside the layout, got: %v", err)
+	}
+	if repaired, err := processor.RepairSymbols(ctx.SymbolsDir, res.LayoutFilePath, nil); err != nil || repaired.Restored != 0 {
+		t.Fatalf("Expected nothing to repair, got %+v, %v", repaired, err)
+	}
+
+	// An intact symbol is regenerated with the same content
+	block := layout.Blocks[1]
+	id := block.Symbols[0]
+	path := filepath.Join(ctx.SymbolsDir, blockDirName(block.BlockID), id)
+	repaired, err := processor.RepairSymbols(ctx.SymbolsDir, res.LayoutFilePath, []string{id, id})
+	if err != nil {
+		t.Fatalf("Failed to regenerate an intact symbol: %v", err)
+	}
+	if repaired.Restored != 1 || len(repaired.Blocks) != 1 || repaired.Blocks[0].BlockID != block.BlockID {
+		t.Fatalf("Unexpected repair result: %+v", repaired)
+	}
+	if data, err := os.ReadFile(path); err != nil || hashBytes(data) != id {
+		t.Fatalf("Regenerated symbol does not match its ID: %v", err)
+	}
+
+	// Lose a source symbol and corrupt a repair symbol
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if res.TotalRepairSymbols == 0 {
+		if _, err := processor.RepairSymbols(ctx.SymbolsDir, res.LayoutFilePath, []string{id}); !errors.Is(err, ErrInsufficientSymbols) {
+			t.Fatalf("Expected ErrInsufficientSymbols without repair symbols, got: %v", err)
+		}
+	}
+	skipWithoutRepairSymbols(t)
+	corrupt := block.Symbols[len(block.Symbols)-1]
+	if err := os.WriteFile(filepath.Join(ctx.SymbolsDir, blockDirName(block.BlockID), corrupt), []byte("corrupt"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The repair symbols of the layout are regenerated even by a processor
+	// with a lower redundancy factor
+	small, err := NewRaptorQProcessor(DefaultSymbolSize, 1, DefaultMaxMemoryMB, DefaultConcurrencyLimit)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer small.Free()
+	if _, err := small.RepairSymbols(ctx.SymbolsDir, res.LayoutFilePath, []string{id, corrupt}); err != nil {
+		t.Fatalf("Failed to repair symbols: %v", err)
+	}
+	report, err := VerifySymbols(ctx.SymbolsDir, layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Healthy() {
+		t.Fatalf("Symbols are not healthy after repair: %+v", report.Blocks[1])
+	}
+}
@@ -0,0 +1,445 @@
+// Package rqtest provides test helpers for code using rq-go, most notably
+// FakeBackend, an in-memory backend that needs neither the native library nor
+// large symbol files and can be told to fail with any return code.
+package rqtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	rq "github.com/LumeraProtocol/rq-go"
+)
+
+// layoutFileName is the name of the layout file EncodeFile writes into the
+// output directory, as the native library does.
+const layoutFileName = "_raptorq_layout.json"
+
+// blockDirFormat is the name of the directory EncodeFile writes the symbols of
+// a block into, as the native library does.
+const blockDirFormat = "block_%d"
+
+// Op names a backend operation for failure injection.
+type Op string
+
+// Operations of FakeBackend that can be made to fail.
+const (
+	OpInitSession    Op = "InitSession"
+	OpEncodeFile     Op = "EncodeFile"
+	OpCreateMetadata Op = "CreateMetadata"
+	OpDecodeSymbols  Op = "DecodeSymbols"
+)
+
+// FakeBackend is an in-memory rq.Backend for tests.
+//
+// EncodeFile and CreateMetadata read the input file and write a layout file
+// with valid encoder parameters and block hashes, but keep the block data in
+// memory. Instead of real symbols, EncodeFile writes a small placeholder file
+// per symbol, RedundancyFactor repair symbols per source symbol: the
+// placeholder of the symbol with encoding symbol ID esi holds "<block
+// hash>/<esi>", and its symbol ID is the hash of that content, so the symbols
+// pass rq.VerifySymbols and encoding a block again with a higher redundancy
+// factor yields the same symbols followed by new ones. DecodeSymbols decodes a
+// block only if its directory holds at least as many intact symbols of the
+// layout as the block has source symbols, as a real decoder would need, and
+// then restores it from memory; blocks encoded by another FakeBackend cannot be
+// decoded.
+//
+// Failures are injected per operation with FailNext or FailAlways. A failing
+// operation returns the given code and records the message as the session's
+// last error, so the processor reports it exactly like a native failure, e.g.
+// as an error matching rq.ErrMemoryLimit for CodeMemoryLimit (-16).
+//
+// A FakeBackend is safe for concurrent use.
+type FakeBackend struct {
+	mu       sync.Mutex
+	nextID   uintptr
+	sessions map[uintptr]*fakeSession
+	blocks   map[string][]byte
+	failures map[Op][]failure
+	calls    map[Op]int
+}
+
+// fakeSession is the state of a FakeBackend session.
+type fakeSession struct {
+	config    rq.ProcessorConfig
+	lastError string
+}
+
+// failure is an injected failure.
+type failure struct {
+	code    rq.ErrorCode
+	message string
+	sticky  bool
+}
+
+// NewFakeBackend returns an empty FakeBackend.
+//
+// Example:
+//
+//	fake := rqtest.NewFakeBackend()
+//	processor, err := rq.NewBackendProcessor(fake, rq.DefaultProcessorConfig())
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	defer processor.Free()
+//
+//	fake.FailNext(rqtest.OpEncodeFile, rq.CodeMemoryLimit, "memory limit of 16384 MB exceeded")
+//	_, err = processor.EncodeFile("input.dat", "symbols/", 0)
+//	// errors.Is(err, rq.ErrMemoryLimit) == true
+func NewFakeBackend() *FakeBackend {
+	return &FakeBackend{
+		sessions: make(map[uintptr]*fakeSession),
+		blocks:   make(map[string][]byte),
+		failures: make(map[Op][]failure),
+		calls:    make(map[Op]int),
+	}
+}
+
+// FailNext makes the next call of op fail with code, recording message as the
+// session's last error. Calls queue up: FailNext twice fails the next two calls.
+// For OpInitSession the code is ignored and the session creation fails.
+func (f *FakeBackend) FailNext(op Op, code rq.ErrorCode, message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[op] = append(f.failures[op], failure{code: code, message: message})
+}
+
+// FailAlways makes every call of op fail with code until ClearFailures is called.
+func (f *FakeBackend) FailAlways(op Op, code rq.ErrorCode, message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[op] = append(f.failures[op], failure{code: code, message: message, sticky: true})
+}
+
+// ClearFailures removes all injected failures.
+func (f *FakeBackend) ClearFailures() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = make(map[Op][]failure)
+}
+
+// Calls returns how many times op has been called, including failed calls.
+func (f *FakeBackend) Calls(op Op) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[op]
+}
+
+// Sessions returns the number of live sessions.
+func (f *FakeBackend) Sessions() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.sessions)
+}
+
+// begin counts a call of op and returns its session and the failure to report,
+// if any. ok is false if the session does not exist.
+func (f *FakeBackend) begin(op Op, sessionID uintptr) (s *fakeSession, fail *failure, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[op]++
+
+	s, ok = f.sessions[sessionID]
+	if !ok {
+		return nil, nil, false
+	}
+	if queue := f.failures[op]; len(queue) > 0 {
+		next := queue[0]
+		if !next.sticky {
+			f.failures[op] = queue[1:]
+		}
+		fail = &next
+	}
+	return s, fail, true
+}
+
+// fail records msg as the last error of s and returns code.
+func (f *FakeBackend) fail(s *fakeSession, code rq.ErrorCode, msg string) rq.ErrorCode {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s.lastError = msg
+	return code
+}
+
+// InitSession implements rq.Backend.
+func (f *FakeBackend) InitSession(cfg rq.ProcessorConfig) uintptr {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[OpInitSession]++
+
+	if queue := f.failures[OpInitSession]; len(queue) > 0 {
+		if !queue[0].sticky {
+			f.failures[OpInitSession] = queue[1:]
+		}
+		return 0
+	}
+	f.nextID++
+	f.sessions[f.nextID] = &fakeSession{config: cfg}
+	return f.nextID
+}
+
+// FreeSession implements rq.Backend.
+func (f *FakeBackend) FreeSession(sessionID uintptr) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.sessions[sessionID]; !ok {
+		return false
+	}
+	delete(f.sessions, sessionID)
+	return true
+}
+
+// EncodeFile implements rq.Backend.
+func (f *FakeBackend) EncodeFile(sessionID uintptr, inputPath, outputDir string, blockSize int) (*rq.ProcessResult, rq.ErrorCode) {
+	s, fail, ok := f.begin(OpEncodeFile, sessionID)
+	if !ok {
+		return nil, rq.CodeInvalidSession
+	}
+	if fail != nil {
+		return nil, f.fail(s, fail.code, fail.message)
+	}
+	if inputPath == "" || outputDir == "" {
+		return nil, rq.CodeInvalidParameters
+	}
+	result, code, msg := f.encode(s.config, inputPath, outputDir, filepath.Join(outputDir, layoutFileName), blockSize)
+	if code != rq.CodeSuccess {
+		return nil, f.fail(s, code, msg)
+	}
+	result.SymbolsDirectory = outputDir
+	return result, rq.CodeSuccess
+}
+
+// CreateMetadata implements rq.Backend.
+func (f *FakeBackend) CreateMetadata(sessionID uintptr, inputPath, layoutFile string, blockSize int) (*rq.ProcessResult, rq.ErrorCode) {
+	s, fail, ok := f.begin(OpCreateMetadata, sessionID)
+	if !ok {
+		return nil, rq.CodeInvalidSession
+	}
+	if fail != nil {
+		return nil, f.fail(s, fail.code, fail.message)
+	}
+	if inputPath == "" || layoutFile == "" {
+		return nil, rq.CodeInvalidParameters
+	}
+	result, code, msg := f.encode(s.config, inputPath, "", layoutFile, blockSize)
+	if code != rq.CodeSuccess {
+		return nil, f.fail(s, code, msg)
+	}
+	return result, rq.CodeSuccess
+}
+
+// encode writes the layout of inputPath and keeps its blocks in memory. If
+// outputDir is not empty, the placeholder symbols are written into it.
+func (f *FakeBackend) encode(cfg rq.ProcessorConfig, inputPath, outputDir, layoutPath string, blockSize int) (*rq.ProcessResult, rq.ErrorCode, string) {
+	data, err := os.ReadFile(inputPath)
+	if os.IsNotExist(err) {
+		return nil, rq.CodeFileNotFound, err.Error()
+	} else if err != nil {
+		return nil, rq.CodeIO, err.Error()
+	}
+	if len(data) == 0 {
+		return nil, rq.CodeInvalidParameters, fmt.Sprintf("input file %s is empty", inputPath)
+	}
+
+	symbolSize := cfg.SymbolSize - cfg.SymbolSize%rq.SymbolAlignment
+	if symbolSize == 0 {
+		return nil, rq.CodeInvalidParameters, fmt.Sprintf("invalid symbol size %d", cfg.SymbolSize)
+	}
+	size := blockSize
+	if size <= 0 || size > len(data) {
+		size = len(data)
+	}
+
+	result := &rq.ProcessResult{LayoutFilePath: layoutPath}
+	layout := &rq.Layout{}
+	for offset := 0; offset < len(data); offset += size {
+		block := data[offset:min(offset+size, len(data))]
+		hash := rq.ContentHash(block)
+		oti := rq.ObjectTransmissionInfo{
+			TransferLength: uint64(len(block)),
+			SymbolSize:     symbolSize,
+			SourceBlocks:   1,
+			SubBlocks:      1,
+			Alignment:      rq.SymbolAlignment,
+		}
+
+		source := oti.SourceSymbols()
+		total := source * (1 + int(cfg.RedundancyFactor))
+		symbols := make([]string, total)
+		for esi := range symbols {
+			symbols[esi] = rq.ContentHash(placeholder(hash, esi))
+		}
+		if outputDir != "" {
+			if err := writePlaceholders(filepath.Join(outputDir, fmt.Sprintf(blockDirFormat, len(layout.Blocks))), hash, symbols); err != nil {
+				return nil, rq.CodeIO, err.Error()
+			}
+		}
+
+		entry := rq.BlockLayout{
+			BlockID:           uint64(len(layout.Blocks)),
+			EncoderParameters: oti.Bytes(),
+			OriginalOffset:    uint64(offset),
+			Size:              uint64(len(block)),
+			Symbols:           symbols,
+			Hash:              hash,
+		}
+		layout.Blocks = append(layout.Blocks, entry)
+		result.Blocks = append(result.Blocks, rq.Block{
+			BlockID:            entry.BlockID,
+			EncoderParameters:  entry.EncoderParameters,
+			OriginalOffset:     entry.OriginalOffset,
+			Size:               entry.Size,
+			SymbolsCount:       uint32(total),
+			SourceSymbolsCount: uint32(source),
+			Hash:               hash,
+		})
+		result.TotalSymbolsCount += uint32(total)
+		result.TotalRepairSymbols += uint32(total - source)
+
+		f.mu.Lock()
+		f.blocks[hash] = append([]byte(nil), block...)
+		f.mu.Unlock()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(layoutPath), 0755); err != nil {
+		return nil, rq.CodeIO, err.Error()
+	}
+	if err := layout.Save(layoutPath); err != nil {
+		return nil, rq.CodeIO, err.Error()
+	}
+	return result, rq.CodeSuccess, ""
+}
+
+// placeholder returns the content of the placeholder file of a symbol.
+func placeholder(blockHash string, esi int) []byte {
+	return []byte(fmt.Sprintf("%s/%d", blockHash, esi))
+}
+
+// writePlaceholders writes the placeholder files of the symbols of a block
+// into dir.
+func writePlaceholders(dir, blockHash string, symbols []string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for esi, id := range symbols {
+		if err := os.WriteFile(filepath.Join(dir, id), placeholder(blockHash, esi), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeSymbols implements rq.Backend.
+func (f *FakeBackend) DecodeSymbols(sessionID uintptr, symbolsDir, outputPath, layoutPath string) rq.ErrorCode {
+	s, fail, ok := f.begin(OpDecodeSymbols, sessionID)
+	if !ok {
+		return rq.CodeInvalidSession
+	}
+	if fail != nil {
+		return f.fail(s, fail.code, fail.message)
+	}
+
+	layout, err := rq.LoadLayout(layoutPath)
+	if os.IsNotExist(err) {
+		return f.fail(s, rq.CodeFileNotFound, err.Error())
+	} else if err != nil {
+		return f.fail(s, rq.CodeInvalidParameters, err.Error())
+	}
+
+	out, err := os.Create(outputPath)
+	if err != nil {
+		return f.fail(s, rq.CodeIO, err.Error())
+	}
+	defer out.Close()
+
+	for _, block := range layout.Blocks {
+		oti, err := block.OTI()
+		if err != nil {
+			return f.fail(s, rq.CodeInvalidParameters, err.Error())
+		}
+		intact, err := intactSymbols(symbolsDir, block)
+		if err != nil {
+			return f.fail(s, rq.CodeIO, err.Error())
+		}
+		if intact < oti.SourceSymbols() {
+			return f.fail(s, rq.CodeDecodingFailed, fmt.Sprintf("block %d: %d intact symbols are insufficient, %d are needed",
+				block.BlockID, intact, oti.SourceSymbols()))
+		}
+
+		f.mu.Lock()
+		data, ok := f.blocks[block.Hash]
+		f.mu.Unlock()
+		if !ok {
+			return f.fail(s, rq.CodeDecodingFailed,
+				fmt.Sprintf("insufficient symbols: block %d was not encoded by this backend", block.BlockID))
+		}
+		if _, err := out.WriteAt(data, int64(block.OriginalOffset)); err != nil {
+			return f.fail(s, rq.CodeIO, err.Error())
+		}
+	}
+	if err := out.Close(); err != nil {
+		return f.fail(s, rq.CodeIO, err.Error())
+	}
+	return rq.CodeSuccess
+}
+
+// intactSymbols counts the symbol files of a block in symbolsDir that are
+// listed in its layout and hold a placeholder of the block matching their ID.
+func intactSymbols(symbolsDir string, block rq.BlockLayout) (int, error) {
+	listed := make(map[string]bool, len(block.Symbols))
+	for _, id := range block.Symbols {
+		listed[id] = true
+	}
+	dir := filepath.Join(symbolsDir, fmt.Sprintf(blockDirFormat, block.BlockID))
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	intact := 0
+	for _, entry := range entries {
+		id := entry.Name()
+		if !listed[id] {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, id))
+		if err != nil {
+			return 0, err
+		}
+		if rq.ContentHash(data) == id && strings.HasPrefix(string(data), block.Hash+"/") {
+			intact++
+		}
+	}
+	return intact, nil
+}
+
+// RecommendedBlockSize implements rq.Backend. It recommends a single block.
+func (f *FakeBackend) RecommendedBlockSize(sessionID uintptr, fileSize uint64) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.sessions[sessionID]; !ok {
+		return 0
+	}
+	return int(fileSize)
+}
+
+// LastError implements rq.Backend.
+func (f *FakeBackend) LastError(sessionID uintptr) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.sessions[sessionID]
+	if !ok {
+		return "", false
+	}
+	return s.lastError, true
+}
+
+// Version implements rq.Backend.
+func (f *FakeBackend) Version() (string, bool) {
+	return "rqtest fake", true
+}
@@ -0,0 +1,215 @@
+package rqtest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	rq "github.com/LumeraProtocol/rq-go"
+)
+
+// newFakeProcessor returns a processor on a new FakeBackend
+func newFakeProcessor(t *testing.T) (*FakeBackend, *rq.RaptorQProcessor) {
+	t.Helper()
+	fake := NewFakeBackend()
+	processor, err := rq.NewBackendProcessor(fake, rq.DefaultProcessorConfig())
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	t.Cleanup(func() { processor.Free() })
+	return fake, processor
+}
+
+// writeInput writes size random bytes to a file in dir
+func writeInput(t *testing.T, dir string, size int) (string, []byte) {
+	t.Helper()
+	data := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(data)
+	path := filepath.Join(dir, "input.bin")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path, data
+}
+
+// Unit test for encoding and decoding through the fake backend
+func TestFakeBackendRoundTrip(t *testing.T) {
+	fake, processor := newFakeProcessor(t)
+	dir := t.TempDir()
+	inputPath, data := writeInput(t, dir, 3*1024*1024+100)
+
+	symbolsDir := filepath.Join(dir, "symbols")
+	res, err := processor.EncodeFile(inputPath, symbolsDir, 1024*1024)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	if len(res.Blocks) != 4 || res.TotalRepairSymbols == 0 {
+		t.Fatalf("Unexpected result: %+v", res)
+	}
+
+	layout, err := rq.LoadLayout(res.LayoutFilePath)
+	if err != nil {
+		t.Fatalf("Failed to load layout: %v", err)
+	}
+	if err := layout.Validate(); err != nil {
+		t.Fatalf("Layout is not valid: %v", err)
+	}
+
+	outputPath := filepath.Join(dir, "output.bin")
+	if err := processor.DecodeSymbols(symbolsDir, outputPath, res.LayoutFilePath); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	decoded, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Fatal("Decoded data does not match the original")
+	}
+	if fake.Calls(OpEncodeFile) != 1 || fake.Calls(OpDecodeSymbols) != 1 {
+		t.Fatalf("Unexpected call counts: encode %d, decode %d", fake.Calls(OpEncodeFile), fake.Calls(OpDecodeSymbols))
+	}
+
+	// Layouts of another backend are unknown to the fake
+	other, otherProcessor := newFakeProcessor(t)
+	err = otherProcessor.DecodeSymbols(symbolsDir, outputPath, res.LayoutFilePath)
+	if !errors.Is(err, rq.ErrInsufficientSymbols) {
+		t.Fatalf("Expected ErrInsufficientSymbols, got: %v", err)
+	}
+	if other.Sessions() != 1 {
+		t.Fatalf("Expected 1 session, got %d", other.Sessions())
+	}
+}
+
+// Unit test for the errors reported for injected failures
+func TestFakeBackendFailures(t *testing.T) {
+	fake, processor := newFakeProcessor(t)
+	dir := t.TempDir()
+	inputPath, _ := writeInput(t, dir, 10000)
+	symbolsDir := filepath.Join(dir, "symbols")
+
+	tests := []struct {
+		code rq.ErrorCode
+		want error
+	}{
+		{rq.CodeIO, rq.ErrIO},
+		{rq.CodeFileNotFound, rq.ErrFileNotFound},
+		{rq.CodeEncodingFailed, rq.ErrEncodingFailed},
+		{rq.CodeDecodingFailed, rq.ErrDecodingFailed},
+		{rq.CodeInvalidPath, rq.ErrInvalidPath},
+		{rq.CodeMemoryLimit, rq.ErrMemoryLimit},
+		{rq.CodeConcurrencyLimit, rq.ErrConcurrencyLimit},
+	}
+	for _, tt := range tests {
+		fake.FailNext(OpEncodeFile, tt.code, "injected failure")
+		_, err := processor.EncodeFile(inputPath, symbolsDir, 0)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Code %d: expected %v, got: %v", tt.code, tt.want, err)
+		}
+		var rqErr *rq.RaptorQError
+		if !errors.As(err, &rqErr) || rqErr.Code != tt.code || rqErr.Detail != "injected failure" {
+			t.Errorf("Code %d: unexpected error %#v", tt.code, err)
+		}
+	}
+
+	// The queue is drained, so the next call succeeds
+	if _, err := processor.EncodeFile(inputPath, symbolsDir, 0); err != nil {
+		t.Fatalf("Expected encoding to succeed, got: %v", err)
+	}
+
+	fake.FailAlways(OpCreateMetadata, rq.CodeMemoryLimit, "memory limit exceeded")
+	for i := 0; i < 2; i++ {
+		if _, err := processor.CreateMetadata(inputPath, filepath.Join(dir, "layout.json"), 0); !errors.Is(err, rq.ErrMemoryLimit) {
+			t.Fatalf("Expected ErrMemoryLimit, got: %v", err)
+		}
+	}
+	fake.ClearFailures()
+	if _, err := processor.CreateMetadata(inputPath, filepath.Join(dir, "layout.json"), 0); err != nil {
+		t.Fatalf("Expected metadata creation to succeed, got: %v", err)
+	}
+
+	fake.FailNext(OpInitSession, rq.CodeGeneric, "")
+	if _, err := rq.NewBackendProcessor(fake, rq.DefaultProcessorConfig()); err == nil {
+		t.Fatal("Expected processor creation to fail")
+	}
+}
+
+// Unit test for the retries of the streaming decoder through the fake backend
+func TestFakeBackendDecoderRetries(t *testing.T) {
+	fake, processor := newFakeProcessor(t)
+	dir := t.TempDir()
+	inputPath, data := writeInput(t, dir, 10000)
+	symbolsDir := filepath.Join(dir, "symbols")
+	res, err := processor.EncodeFile(inputPath, symbolsDir, 0)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	layoutData, err := os.ReadFile(res.LayoutFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout, err := rq.ParseLayout(layoutData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oti, err := layout.Blocks[0].OTI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	symbols := layout.Blocks[0].Symbols
+	k := oti.SourceSymbols()
+	symbol := func(id string) []byte {
+		data, err := os.ReadFile(filepath.Join(symbolsDir, fmt.Sprintf(blockDirFormat, 0), id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	// A plain decoding failure, as the native library reports a block that
+	// needs more symbols: the block is decoded again with the next symbol
+	var out bytes.Buffer
+	dec, err := processor.NewDecoder(layoutData, &out)
+	if err != nil {
+		t.Fatalf("Failed to create decoder: %v", err)
+	}
+	fake.FailNext(OpDecodeSymbols, rq.CodeDecodingFailed, "Decoding failed: block 0 could not be decoded")
+	for _, id := range symbols[:k] {
+		if err := dec.AddSymbol(id, symbol(id)); err != nil {
+			t.Fatalf("Expected the decoder to wait for more symbols, got: %v", err)
+		}
+	}
+	if dec.Done() {
+		t.Fatal("Expected the block to be pending")
+	}
+	if err := dec.AddSymbol(symbols[k], symbol(symbols[k])); err != nil {
+		t.Fatalf("Failed to add symbol: %v", err)
+	}
+	if err := dec.Close(); err != nil || !bytes.Equal(out.Bytes(), data) {
+		t.Fatalf("Failed to decode after a retry: %v", err)
+	}
+
+	// Failures other than decoding failures are returned as they are
+	dec, err = processor.NewDecoder(layoutData, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to create decoder: %v", err)
+	}
+	fake.FailNext(OpDecodeSymbols, rq.CodeIO, "failed to read block 0")
+	for _, id := range symbols[:k-1] {
+		if err := dec.AddSymbol(id, symbol(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = dec.AddSymbol(symbols[k-1], symbol(symbols[k-1]))
+	if !errors.Is(err, rq.ErrIO) {
+		t.Fatalf("Expected ErrIO, got: %v", err)
+	}
+	if closeErr := dec.Close(); closeErr != err {
+		t.Fatalf("Expected Close to return %v, got: %v", err, closeErr)
+	}
+}
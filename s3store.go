@@ -0,0 +1,467 @@
+package rq_go
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Defaults of S3Config.
+const (
+	DefaultS3Region     = "us-east-1"
+	DefaultS3Retries    = 3
+	DefaultS3RetryDelay = 100 * time.Millisecond
+)
+
+// S3Config configures an S3Store.
+type S3Config struct {
+	// Endpoint is the base URL of the S3 API, for example
+	// "https://s3.eu-central-1.amazonaws.com" or "http://localhost:9000" for
+	// a local MinIO server.
+	Endpoint string
+
+	// Region is the region requests are signed for. If empty,
+	// DefaultS3Region is used.
+	Region string
+
+	// Bucket is the name of the bucket holding the symbols.
+	Bucket string
+
+	// Prefix is prepended to the key of every symbol, so several encodings can
+	// share a bucket. A trailing slash is added if missing.
+	Prefix string
+
+	// AccessKeyID, SecretAccessKey and SessionToken are the credentials
+	// requests are signed with. Requests are sent unsigned if AccessKeyID is
+	// empty.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// PathStyle selects path-style URLs (endpoint/bucket/key), as needed by
+	// most S3-compatible servers, instead of virtual-hosted URLs
+	// (bucket.endpoint/key).
+	PathStyle bool
+
+	// Retries is the number of times a request failing with a network error,
+	// a throttling response or a server error is retried. If 0,
+	// DefaultS3Retries is used; a negative value disables retries.
+	Retries int
+
+	// RetryDelay is the delay before the first retry, doubled for every
+	// further retry. If 0, DefaultS3RetryDelay is used.
+	RetryDelay time.Duration
+
+	// Client is the HTTP client used for requests. If nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+}
+
+// S3Store is a SymbolStore keeping symbols as objects of an S3 bucket, using
+// the S3 REST API directly. It works with Amazon S3 and S3-compatible servers
+// such as MinIO.
+//
+// The symbols of block N are stored under the key "<prefix>block_N/<symbol
+// ID>", mirroring the block directories of a DirStore, so a symbols directory
+// written by EncodeFile can be uploaded with any S3 tool and read with an
+// S3Store.
+type S3Store struct {
+	cfg      S3Config
+	endpoint *url.URL
+	prefix   string
+}
+
+// NewS3Store validates cfg and returns an S3Store for it. No request is sent
+// until the store is used.
+//
+// Returns:
+//   - *S3Store: The store.
+//   - error: An error matching ErrInvalidParameters if the endpoint or bucket
+//     is missing or invalid.
+//
+// Example:
+//
+//	store, err := raptorq.NewS3Store(raptorq.S3Config{
+//	    Endpoint:        "http://localhost:9000",
+//	    Bucket:          "symbols",
+//	    Prefix:          "objects/1234",
+//	    AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+//	    SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+//	    PathStyle:       true,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	result, err := processor.EncodeToStore(ctx, "input.dat", "input.layout.json", store, 0)
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("%w: invalid S3 endpoint %q", ErrInvalidParameters, cfg.Endpoint)
+	}
+	if cfg.Bucket == "" || strings.Contains(cfg.Bucket, "/") {
+		return nil, fmt.Errorf("%w: invalid S3 bucket %q", ErrInvalidParameters, cfg.Bucket)
+	}
+	if cfg.Region == "" {
+		cfg.Region = DefaultS3Region
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = DefaultS3Retries
+	}
+	if cfg.RetryDelay == 0 {
+		cfg.RetryDelay = DefaultS3RetryDelay
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+
+	prefix := strings.Trim(cfg.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	endpoint.Path = strings.TrimSuffix(endpoint.Path, "/")
+	return &S3Store{cfg: cfg, endpoint: endpoint, prefix: prefix}, nil
+}
+
+// S3Error is returned for a request the S3 server answered with an error
+// status. It matches ErrIO through errors.Is.
+type S3Error struct {
+	// Op is the operation that failed, such as "Put" or "List".
+	Op string
+
+	// Key is the object key or prefix of the request.
+	Key string
+
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+
+	// Code and Message are taken from the error document of the response, if
+	// any, for example "AccessDenied".
+	Code    string
+	Message string
+}
+
+// Error implements the error interface.
+func (e *S3Error) Error() string {
+	msg := fmt.Sprintf("S3 %s %s: status %d", e.Op, e.Key, e.StatusCode)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Unwrap returns ErrIO.
+func (e *S3Error) Unwrap() error {
+	return ErrIO
+}
+
+// key returns the object key of a symbol.
+func (s *S3Store) key(blockID uint64, symbolID string) string {
+	return s.blockPrefix(blockID) + symbolID
+}
+
+// blockPrefix returns the common key prefix of the symbols of a block.
+func (s *S3Store) blockPrefix(blockID uint64) string {
+	return s.prefix + blockDirName(blockID) + "/"
+}
+
+// Put implements SymbolStore.
+func (s *S3Store) Put(ctx context.Context, blockID uint64, symbolID string, data []byte) error {
+	if err := checkSymbolID(symbolID); err != nil {
+		return err
+	}
+	key := s.key(blockID, symbolID)
+	resp, err := s.do(ctx, "Put", http.MethodPut, key, nil, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get implements SymbolStore.
+func (s *S3Store) Get(ctx context.Context, blockID uint64, symbolID string) ([]byte, error) {
+	if err := checkSymbolID(symbolID); err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, "Get", http.MethodGet, s.key(blockID, symbolID), nil, nil)
+	var s3Err *S3Error
+	if errors.As(err, &s3Err) && s3Err.StatusCode == http.StatusNotFound && s3Err.Code != "NoSuchBucket" {
+		return nil, symbolNotFound(blockID, symbolID)
+	} else if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: S3 Get %s: %v", ErrIO, s.key(blockID, symbolID), err)
+	}
+	return data, nil
+}
+
+// listBucketResult is the response of ListObjectsV2.
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List implements SymbolStore.
+func (s *S3Store) List(ctx context.Context, blockID uint64) ([]string, error) {
+	prefix := s.blockPrefix(blockID)
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	ids := []string{}
+	for {
+		resp, err := s.do(ctx, "List", http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: S3 List %s: invalid response: %v", ErrIO, prefix, err)
+		}
+
+		for _, obj := range result.Contents {
+			id := strings.TrimPrefix(obj.Key, prefix)
+			if id != obj.Key && checkSymbolID(id) == nil {
+				ids = append(ids, id)
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Delete implements SymbolStore.
+func (s *S3Store) Delete(ctx context.Context, blockID uint64, symbolID string) error {
+	if err := checkSymbolID(symbolID); err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, "Delete", http.MethodDelete, s.key(blockID, symbolID), nil, nil)
+	var s3Err *S3Error
+	if errors.As(err, &s3Err) && s3Err.StatusCode == http.StatusNotFound && s3Err.Code != "NoSuchBucket" {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends a signed request for key, or for the bucket if key is empty, and
+// retries it as configured. It returns the response if its status is 2xx, and
+// an *S3Error otherwise.
+func (s *S3Store) do(ctx context.Context, op, method, key string, query url.Values, body []byte) (*http.Response, error) {
+	delay := s.cfg.RetryDelay
+	for attempt := 0; ; attempt++ {
+		resp, err := s.send(ctx, method, key, query, body)
+		if err == nil && resp.StatusCode/100 == 2 {
+			return resp, nil
+		}
+		if err == nil {
+			err = s.responseError(op, key, resp)
+		} else if ctx.Err() == nil {
+			err = fmt.Errorf("%w: S3 %s %s: %v", ErrIO, op, key, err)
+		}
+
+		if attempt >= s.cfg.Retries || !retryable(ctx, err) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// send sends a single request.
+func (s *S3Store) send(ctx context.Context, method, key string, query url.Values, body []byte) (*http.Response, error) {
+	u := *s.endpoint
+	path := "/" + key
+	if s.cfg.PathStyle {
+		path = "/" + s.cfg.Bucket + path
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+	}
+	u.Path = u.Path + path
+	u.RawPath = s3Escape(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if s.cfg.AccessKeyID != "" {
+		signer := s3Signer{
+			accessKeyID:     s.cfg.AccessKeyID,
+			secretAccessKey: s.cfg.SecretAccessKey,
+			sessionToken:    s.cfg.SessionToken,
+			region:          s.cfg.Region,
+		}
+		signer.sign(req, sha256Hex(body), time.Now())
+	}
+	return s.cfg.Client.Do(req)
+}
+
+// responseError reads the error document of a failed response and closes it.
+func (s *S3Store) responseError(op, key string, resp *http.Response) error {
+	defer resp.Body.Close()
+	var doc struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	xml.Unmarshal(data, &doc)
+	if key == "" {
+		key = s.cfg.Bucket
+	}
+	return &S3Error{Op: op, Key: key, StatusCode: resp.StatusCode, Code: doc.Code, Message: doc.Message}
+}
+
+// retryable reports whether a failed request may succeed when sent again:
+// network errors, throttling and server errors are, unless ctx has ended.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var s3Err *S3Error
+	if errors.As(err, &s3Err) {
+		return s3Err.StatusCode == http.StatusTooManyRequests || s3Err.StatusCode >= 500
+	}
+	return true
+}
+
+// s3Signer signs requests with AWS Signature Version 4.
+type s3Signer struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	region          string
+}
+
+// sign adds the signature headers to req for a payload with the given hex
+// SHA-256 hash. Every header already set on req is signed, along with Host.
+func (s s3Signer) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+	req.Header.Set("Authorization", s.authorization(req, payloadHash, amzDate))
+}
+
+// authorization returns the Authorization header of req, signing the headers
+// of req except Authorization itself.
+func (s s3Signer) authorization(req *http.Request, payloadHash, amzDate string) string {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name != "authorization" {
+			headers[name] = strings.Join(values, ",")
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	canonical.WriteString(req.Method + "\n")
+	canonical.WriteString(s3Escape(req.URL.Path, false) + "\n")
+	canonical.WriteString(canonicalQuery(req.URL.Query()) + "\n")
+	for _, name := range names {
+		canonical.WriteString(name + ":" + strings.Join(strings.Fields(headers[name]), " ") + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonical.WriteString("\n" + signedHeaders + "\n" + payloadHash)
+
+	date := amzDate[:8]
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonical.String()))
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), date)
+	for _, part := range []string{s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	return "AWS4-HMAC-SHA256 Credential=" + s.accessKeyID + "/" + scope +
+		",SignedHeaders=" + signedHeaders + ",Signature=" + signature
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// sha256Hex returns the hex-encoded SHA-256 hash of data.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalQuery encodes query in the canonical form of Signature Version 4:
+// sorted by name, with names and values escaped by s3Escape.
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var parts []string
+	for _, name := range names {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3Escape(name, true)+"="+s3Escape(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Escape percent-encodes every byte of s except the unreserved characters
+// of RFC 3986 and, unless encodeSlash is set, the slash.
+func s3Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,285 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	raptorq "github.com/LumeraProtocol/rq-go"
+	"github.com/LumeraProtocol/rq-go/server/rqpb"
+	"google.golang.org/grpc"
+)
+
+// Client calls the SymbolService of a Server over gRPC, see Server.ServeRPC.
+// It is safe for concurrent use.
+//
+// Errors of the server are returned as *RemoteError values, which match the
+// sentinel errors of the raptorq package with errors.Is. Failures of the
+// connection match raptorq.ErrIO. When ctx ends, a call is aborted and returns
+// ctx.Err().
+type Client struct {
+	rpc rqpb.SymbolServiceClient
+}
+
+// NewClient returns a Client calling the service over cc, typically a
+// *grpc.ClientConn. The connection stays owned by the caller.
+//
+// Example:
+//
+//	conn, err := grpc.NewClient("node-1:9090",
+//	    grpc.WithTransportCredentials(insecure.NewCredentials()))
+//	if err != nil {
+//	    return err
+//	}
+//	defer conn.Close()
+//	client := server.NewClient(conn)
+//	layout, err := client.GetLayout(ctx, objectID)
+func NewClient(cc grpc.ClientConnInterface) *Client {
+	return &Client{rpc: rqpb.NewSymbolServiceClient(cc)}
+}
+
+// callError converts the error of a call of method, returning ctx.Err() if
+// ctx ended.
+func callError(ctx context.Context, method string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return remoteError(method, err)
+}
+
+// Encode encodes the data read from r into a new object on the server.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation of the call.
+//   - r: The data of the object.
+//   - size: The number of bytes r provides, or -1 if unknown. The server
+//     chooses the block size from it.
+//
+// Returns:
+//   - *EncodeResponse: The ID of the new object and its encoding. The paths
+//     of the result are empty.
+//   - error: The error of the read, of the server, or of the connection.
+func (c *Client) Encode(ctx context.Context, r io.Reader, size int64) (*EncodeResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.rpc.Encode(ctx)
+	if err != nil {
+		return nil, callError(ctx, methodEncode, err)
+	}
+	if size < 0 {
+		size = 0
+	}
+	buf := make([]byte, rpcChunkSize)
+	first := true
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 || first {
+			msg := &rqpb.EncodeRequest{Data: buf[:n]}
+			if first {
+				msg.Size = size
+				first = false
+			}
+			if err := stream.Send(msg); err == io.EOF {
+				// The server ended the call; CloseAndRecv returns its status
+				break
+			} else if err != nil {
+				return nil, callError(ctx, methodEncode, err)
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		} else if readErr != nil {
+			return nil, readErr
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, callError(ctx, methodEncode, err)
+	}
+	if res.Result == nil {
+		return nil, fmt.Errorf("%s: %w: missing result", methodEncode, raptorq.ErrInvalidResponse)
+	}
+	return &EncodeResponse{ObjectID: res.ObjectId, Result: resultFromProto(res.Result)}, nil
+}
+
+// Decode writes the bytes [offset, offset+length) of an object to w, decoded
+// block by block on the server. A length of 0 selects the rest of the object.
+//
+// Returns:
+//   - int64: The number of bytes written to w.
+//   - error: The error of the server, of w, or of the connection. A failure
+//     after the first bytes leaves w with a prefix of the range.
+func (c *Client) Decode(ctx context.Context, objectID string, offset, length uint64, w io.Writer) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.rpc.Decode(ctx, &rqpb.DecodeRequest{ObjectId: objectID, Offset: offset, Length: length})
+	if err != nil {
+		return 0, callError(ctx, methodDecode, err)
+	}
+	var written int64
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return written, nil
+		} else if err != nil {
+			return written, callError(ctx, methodDecode, err)
+		}
+		n, err := w.Write(chunk.Data)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+// GetLayout returns the layout of an object.
+func (c *Client) GetLayout(ctx context.Context, objectID string) (*raptorq.Layout, error) {
+	res, err := c.rpc.GetLayout(ctx, &rqpb.ObjectRequest{ObjectId: objectID})
+	if err != nil {
+		return nil, callError(ctx, methodGetLayout, err)
+	}
+	return raptorq.ParseLayout(res.Layout)
+}
+
+// GetSymbols streams the intact symbols selected by req to fn, block by block
+// in layout order. Missing and corrupt symbols of the server are skipped.
+// Every symbol is checked on arrival: its data must match its hash and its
+// ID.
+//
+// Returns:
+//   - error: An error matching raptorq.ErrHashMismatch if a symbol arrived
+//     damaged, the error returned by fn, or the error of the server or of the
+//     connection.
+//
+// Example:
+//
+//	// Copy the symbols of block 0 to a local store
+//	err := client.GetSymbols(ctx, server.GetSymbolsRequest{ObjectID: id, BlockIDs: []uint64{0}},
+//	    func(s *server.Symbol) error {
+//	        return store.Put(ctx, s.BlockID, s.SymbolID, s.Data)
+//	    })
+func (c *Client) GetSymbols(ctx context.Context, req GetSymbolsRequest, fn func(*Symbol) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.rpc.GetSymbols(ctx, &rqpb.GetSymbolsRequest{
+		ObjectId:  req.ObjectID,
+		BlockIds:  req.BlockIDs,
+		SymbolIds: req.SymbolIDs,
+	})
+	if err != nil {
+		return callError(ctx, methodGetSymbols, err)
+	}
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return callError(ctx, methodGetSymbols, err)
+		}
+		sym := symbolFromProto(msg)
+		if hash := raptorq.ContentHash(sym.Data); hash != sym.Hash || hash != sym.SymbolID {
+			return fmt.Errorf("%s: %w: symbol %s of block %d", methodGetSymbols, raptorq.ErrHashMismatch, sym.SymbolID, sym.BlockID)
+		}
+		if err := fn(sym); err != nil {
+			return err
+		}
+	}
+}
+
+// SymbolSender is the client side of a PutSymbols call.
+type SymbolSender struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	stream rqpb.SymbolService_PutSymbolsClient
+	err    error
+}
+
+// PutSymbols starts storing symbols of an object on the server. The server
+// checks every symbol against the layout of the object on arrival; symbols
+// that fail are reported in the response instead of being stored. If layout
+// is not nil, the object is created with it unless it exists already, which
+// lets nodes receive objects they do not hold yet.
+//
+// Send the symbols with Send, then call CloseAndRecv, which also ends the
+// call.
+//
+// Example:
+//
+//	sender, err := dst.PutSymbols(ctx, id, layout)
+//	if err != nil {
+//	    return err
+//	}
+//	err = src.GetSymbols(ctx, server.GetSymbolsRequest{ObjectID: id}, sender.Send)
+//	res, closeErr := sender.CloseAndRecv()
+func (c *Client) PutSymbols(ctx context.Context, objectID string, layout *raptorq.Layout) (*SymbolSender, error) {
+	req := &rqpb.PutSymbolsRequest{ObjectId: objectID}
+	if layout != nil {
+		data, err := layout.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		req.Layout = data
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := c.rpc.PutSymbols(ctx)
+	if err != nil {
+		cancel()
+		return nil, callError(ctx, methodPutSymbols, err)
+	}
+	s := &SymbolSender{ctx: ctx, cancel: cancel, stream: stream}
+	s.send(req)
+	return s, nil
+}
+
+// send sends a message, recording the failure of the stream. io.EOF means the
+// server ended the call, whose status CloseAndRecv returns.
+func (s *SymbolSender) send(msg *rqpb.PutSymbolsRequest) error {
+	if err := s.stream.Send(msg); err == io.EOF {
+		s.err = fmt.Errorf("%s: %w: the server ended the call", methodPutSymbols, raptorq.ErrIO)
+	} else if err != nil {
+		s.err = callError(s.ctx, methodPutSymbols, err)
+	}
+	return s.err
+}
+
+// Send sends a symbol. Hash is filled in if it is empty. If Send fails, the
+// call is broken and CloseAndRecv returns its error.
+func (s *SymbolSender) Send(sym *Symbol) error {
+	if s.err != nil {
+		return s.err
+	}
+	msg := symbolToProto(sym)
+	if msg.Hash == "" {
+		msg.Hash = raptorq.ContentHash(sym.Data)
+	}
+	return s.send(&rqpb.PutSymbolsRequest{Symbol: msg})
+}
+
+// CloseAndRecv ends the stream of symbols and returns the response of the
+// server.
+func (s *SymbolSender) CloseAndRecv() (*PutSymbolsResponse, error) {
+	defer s.cancel()
+	// Even if sending failed, the server may have reported the reason
+	msg, err := s.stream.CloseAndRecv()
+	if err != nil {
+		return nil, callError(s.ctx, methodPutSymbols, err)
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	res := &PutSymbolsResponse{Stored: int(msg.Stored)}
+	for _, r := range msg.Rejected {
+		res.Rejected = append(res.Rejected, RejectedSymbol{BlockID: r.BlockId, SymbolID: r.SymbolId, Reason: r.Reason})
+	}
+	return res, nil
+}
+
+// Verify checks the symbols of an object against its layout on the server, as
+// raptorq.VerifySymbols does. The Directory of the blocks is empty.
+func (c *Client) Verify(ctx context.Context, objectID string) (*raptorq.VerificationReport, error) {
+	res, err := c.rpc.Verify(ctx, &rqpb.ObjectRequest{ObjectId: objectID})
+	if err != nil {
+		return nil, callError(ctx, methodVerify, err)
+	}
+	return reportFromProto(res), nil
+}
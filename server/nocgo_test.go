@@ -0,0 +1,17 @@
+//go:build !cgo
+
+package server
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+// TestMain skips the system tests, which need the native library that builds
+// without cgo do not link.
+func TestMain(m *testing.M) {
+	flag.Parse()
+	flag.Set("test.skip", "^TestSys")
+	os.Exit(m.Run())
+}
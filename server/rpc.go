@@ -0,0 +1,678 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	raptorq "github.com/LumeraProtocol/rq-go"
+	"github.com/LumeraProtocol/rq-go/server/rqpb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The RPC methods implement the SymbolService of rqpb/rq.proto over gRPC, so
+// any client generated from rq.proto can call them. A failed call carries a
+// google.rpc.ErrorInfo detail in errorDomain, whose reason names the error
+// class; Client turns it back into an error matching the sentinel errors.
+
+// RPC method names, as reported by RemoteError.
+const (
+	methodEncode     = "Encode"
+	methodDecode     = "Decode"
+	methodGetLayout  = "GetLayout"
+	methodPutSymbols = "PutSymbols"
+	methodGetSymbols = "GetSymbols"
+	methodVerify     = "Verify"
+)
+
+// errorDomain is the domain of the ErrorInfo details of failed calls.
+const errorDomain = "rq-go"
+
+// rpcChunkSize is the size of the data chunks of Encode and Decode.
+const rpcChunkSize = 64 * 1024
+
+// Symbol is a symbol in transit. The receiver checks Hash against the data
+// and the symbol ID, and the symbol ID against the layout of the object.
+type Symbol struct {
+	BlockID  uint64
+	SymbolID string
+
+	// Hash is the base58 BLAKE3 hash of Data, computed by the sender.
+	Hash string
+	Data []byte
+}
+
+// PutSymbolsResponse is the result of PutSymbols.
+type PutSymbolsResponse struct {
+	// Stored is the number of symbols stored.
+	Stored int
+
+	// Rejected lists the symbols that failed verification and were not
+	// stored.
+	Rejected []RejectedSymbol
+}
+
+// RejectedSymbol describes a symbol refused by PutSymbols.
+type RejectedSymbol struct {
+	BlockID  uint64
+	SymbolID string
+	Reason   string
+}
+
+// GetSymbolsRequest selects the symbols of GetSymbols.
+type GetSymbolsRequest struct {
+	ObjectID string
+
+	// BlockIDs selects blocks. If empty, all blocks are selected.
+	BlockIDs []uint64
+
+	// SymbolIDs selects symbols of the selected blocks. If empty, all their
+	// symbols are selected.
+	SymbolIDs []string
+}
+
+// rpcCodes maps the error classes transmitted over RPC to their sentinel
+// errors and gRPC status codes. More specific errors come first.
+var rpcCodes = []struct {
+	reason string
+	err    error
+	code   codes.Code
+}{
+	{"SYMBOL_NOT_FOUND", raptorq.ErrSymbolNotFound, codes.NotFound},
+	{"NOT_FOUND", raptorq.ErrFileNotFound, codes.NotFound},
+	{"INVALID_PARAMETERS", raptorq.ErrInvalidParameters, codes.InvalidArgument},
+	{"INVALID_LAYOUT", raptorq.ErrInvalidLayout, codes.InvalidArgument},
+	{"INVALID_PATH", raptorq.ErrInvalidPath, codes.InvalidArgument},
+	{"HASH_MISMATCH", raptorq.ErrHashMismatch, codes.DataLoss},
+	{"OBJECT_TOO_LARGE", ErrObjectTooLarge, codes.ResourceExhausted},
+	{"INSUFFICIENT_SYMBOLS", raptorq.ErrInsufficientSymbols, codes.FailedPrecondition},
+	{"DECODING_FAILED", raptorq.ErrDecodingFailed, codes.Internal},
+	{"ENCODING_FAILED", raptorq.ErrEncodingFailed, codes.Internal},
+	{"MEMORY_LIMIT", raptorq.ErrMemoryLimit, codes.ResourceExhausted},
+	{"CONCURRENCY_LIMIT", raptorq.ErrConcurrencyLimit, codes.ResourceExhausted},
+	{"POOL_CLOSED", raptorq.ErrPoolClosed, codes.Unavailable},
+	{"CANCELED", context.Canceled, codes.Canceled},
+	{"DEADLINE_EXCEEDED", context.DeadlineExceeded, codes.DeadlineExceeded},
+	{"IO", raptorq.ErrIO, codes.Internal},
+}
+
+// statusOfError returns the gRPC status error of a failed call, with an
+// ErrorInfo detail naming the class of err.
+func statusOfError(err error) error {
+	reason, code := "INTERNAL", codes.Internal
+	for _, c := range rpcCodes {
+		if errors.Is(err, c.err) {
+			reason, code = c.reason, c.code
+			break
+		}
+	}
+	st := status.New(code, err.Error())
+	if detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: errorDomain}); detailErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// RemoteError is an error returned by the server of an RPC call. It unwraps
+// to the sentinel error of its class, so errors.Is(err, raptorq.ErrFileNotFound)
+// works across the connection.
+type RemoteError struct {
+	// Method is the called method.
+	Method string
+
+	// Code names the error class, the reason of the ErrorInfo detail of the
+	// call, e.g. "NOT_FOUND" or "HASH_MISMATCH".
+	Code string
+
+	// Message is the error message of the server.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *RemoteError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Method, e.Message)
+}
+
+// Unwrap returns the sentinel error of the code, or nil for internal errors.
+func (e *RemoteError) Unwrap() error {
+	for _, c := range rpcCodes {
+		if c.reason == e.Code {
+			return c.err
+		}
+	}
+	return nil
+}
+
+// remoteError converts the error of a call of method into a *RemoteError if
+// the server reported it, or into an error matching raptorq.ErrIO if the call
+// failed in transit.
+func remoteError(method string, err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return fmt.Errorf("%s: %w: %v", method, raptorq.ErrIO, err)
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == errorDomain {
+			return &RemoteError{Method: method, Code: info.Reason, Message: st.Message()}
+		}
+	}
+	return fmt.Errorf("%s: %w: %s", method, raptorq.ErrIO, st.Message())
+}
+
+// ServeRPC accepts gRPC connections on l and serves the SymbolService of
+// rqpb/rq.proto on them, until l fails or Close is called. The methods mirror
+// the HTTP endpoints:
+//
+//	Encode      Encode a stream of data chunks into a new object
+//	Decode      Stream the decoded bytes of a range of an object
+//	GetLayout   The layout of an object
+//	PutSymbols  Store a stream of symbols, each verified against the layout
+//	GetSymbols  Stream the intact symbols of an object
+//	Verify      Check the symbols of an object against its layout
+//
+// ServeRPC returns nil after Close, and the error of l otherwise. To serve
+// with other options, e.g. TLS credentials, register the service on a
+// grpc.Server with RegisterRPC instead.
+//
+// Example:
+//
+//	l, err := net.Listen("tcp", ":9090")
+//	if err != nil {
+//	    return err
+//	}
+//	go srv.ServeRPC(l)
+func (s *Server) ServeRPC(l net.Listener) error {
+	g := grpc.NewServer()
+	s.RegisterRPC(g)
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return nil
+	}
+	s.grpcServers[g] = true
+	s.mu.Unlock()
+
+	err := g.Serve(l)
+	s.mu.Lock()
+	delete(s.grpcServers, g)
+	closed := s.closed
+	s.mu.Unlock()
+	if closed || errors.Is(err, grpc.ErrServerStopped) {
+		return nil
+	}
+	return err
+}
+
+// RegisterRPC registers the SymbolService on r, typically a *grpc.Server. Close
+// aborts the calls in progress on r as well, but does not stop r.
+func (s *Server) RegisterRPC(r grpc.ServiceRegistrar) {
+	rqpb.RegisterSymbolServiceServer(r, &rpcService{s: s})
+}
+
+// startCall registers a call in progress and returns its context, which ends
+// with ctx or when the Server is closed. The returned function ends the call.
+func (s *Server) startCall(ctx context.Context) (context.Context, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, nil, status.Error(codes.Unavailable, "server closed")
+	}
+	s.calls.Add(1)
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(s.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+		s.calls.Done()
+	}, nil
+}
+
+// rpcService implements the SymbolService on a Server.
+type rpcService struct {
+	rqpb.UnimplementedSymbolServiceServer
+	s *Server
+}
+
+// Encode implements rqpb.SymbolServiceServer.
+func (r *rpcService) Encode(stream rqpb.SymbolService_EncodeServer) error {
+	ctx, done, err := r.s.startCall(stream.Context())
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	first, err := stream.Recv()
+	empty := err == io.EOF
+	if err != nil && !empty {
+		return err
+	}
+	size := first.GetSize()
+	if size <= 0 {
+		size = -1
+	}
+	if r.s.cfg.MaxObjectSize > 0 && size > r.s.cfg.MaxObjectSize {
+		return statusOfError(fmt.Errorf("%w: object exceeds %d bytes", ErrObjectTooLarge, r.s.cfg.MaxObjectSize))
+	}
+	id, obj, err := r.s.encodeObject(ctx, &chunkReader{stream: stream, buf: first.GetData(), done: empty}, size)
+	if err != nil {
+		return statusOfError(err)
+	}
+	return stream.SendAndClose(&rqpb.EncodeResponse{ObjectId: id, Result: resultToProto(obj.Result)})
+}
+
+// chunkReader reads the data of an Encode stream.
+type chunkReader struct {
+	stream rqpb.SymbolService_EncodeServer
+	buf    []byte
+	done   bool
+}
+
+// Read implements io.Reader.
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		msg, err := r.stream.Recv()
+		if err == io.EOF {
+			r.done = true
+		} else if err != nil {
+			return 0, fmt.Errorf("%w: %v", raptorq.ErrIO, err)
+		}
+		r.buf = msg.GetData()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Decode implements rqpb.SymbolServiceServer.
+func (r *rpcService) Decode(req *rqpb.DecodeRequest, stream rqpb.SymbolService_DecodeServer) error {
+	ctx, done, err := r.s.startCall(stream.Context())
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	layout, err := r.s.loadObjectLayout(req.ObjectId)
+	if err != nil {
+		return statusOfError(err)
+	}
+	length := req.Length
+	if size := layout.TotalSize(); length == 0 && req.Offset <= size {
+		length = size - req.Offset
+	}
+
+	processor, err := r.s.pool.AcquireConfig(ctx, r.s.decodeConfig(layout))
+	if err != nil {
+		return statusOfError(err)
+	}
+	defer r.s.pool.Release(processor)
+	dir := r.s.objectDir(req.ObjectId)
+	if _, err := processor.DecodeRangeContext(ctx, dir, filepath.Join(dir, layoutFileName), req.Offset, length, chunkWriter{stream}); err != nil {
+		return statusOfError(err)
+	}
+	return nil
+}
+
+// chunkWriter writes the data of a Decode stream.
+type chunkWriter struct {
+	stream rqpb.SymbolService_DecodeServer
+}
+
+// Write implements io.Writer.
+func (w chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), rpcChunkSize)
+		if err := w.stream.Send(&rqpb.DataChunk{Data: p[:n]}); err != nil {
+			return written, fmt.Errorf("%w: %v", raptorq.ErrIO, err)
+		}
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// GetLayout implements rqpb.SymbolServiceServer.
+func (r *rpcService) GetLayout(ctx context.Context, req *rqpb.ObjectRequest) (*rqpb.GetLayoutResponse, error) {
+	_, done, err := r.s.startCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	if !validObjectID(req.ObjectId) {
+		return nil, statusOfError(unknownObject(req.ObjectId))
+	}
+	data, err := os.ReadFile(filepath.Join(r.s.objectDir(req.ObjectId), layoutFileName))
+	if err != nil {
+		return nil, statusOfError(notFound(err, req.ObjectId))
+	}
+	return &rqpb.GetLayoutResponse{Layout: data}, nil
+}
+
+// PutSymbols implements rqpb.SymbolServiceServer.
+func (r *rpcService) PutSymbols(stream rqpb.SymbolService_PutSymbolsServer) error {
+	ctx, done, err := r.s.startCall(stream.Context())
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return statusOfError(fmt.Errorf("%w: missing object ID", raptorq.ErrInvalidParameters))
+	} else if err != nil {
+		return err
+	}
+	res, err := r.s.putSymbols(ctx, first, stream)
+	if err != nil {
+		return statusOfError(err)
+	}
+	return stream.SendAndClose(res)
+}
+
+// putSymbols stores the symbols of a PutSymbols stream whose first message is
+// first.
+func (s *Server) putSymbols(ctx context.Context, first *rqpb.PutSymbolsRequest, stream rqpb.SymbolService_PutSymbolsServer) (*rqpb.PutSymbolsResponse, error) {
+	if !validObjectID(first.ObjectId) {
+		return nil, fmt.Errorf("%w: invalid object ID %q", raptorq.ErrInvalidParameters, first.ObjectId)
+	}
+	if len(first.Layout) > 0 {
+		if err := s.createObject(first.ObjectId, first.Layout); err != nil {
+			return nil, err
+		}
+	}
+	layout, err := s.loadObjectLayout(first.ObjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	store := raptorq.NewDirStore(s.objectDir(first.ObjectId))
+	res := &rqpb.PutSymbolsResponse{}
+	put := func(msg *rqpb.Symbol) error {
+		if msg == nil {
+			return nil
+		}
+		sym := symbolFromProto(msg)
+		if reason := checkSymbol(layout, sym); reason != "" {
+			res.Rejected = append(res.Rejected, &rqpb.RejectedSymbol{BlockId: sym.BlockID, SymbolId: sym.SymbolID, Reason: reason})
+			return nil
+		}
+		if err := store.Put(ctx, sym.BlockID, sym.SymbolID, sym.Data); err != nil {
+			return err
+		}
+		res.Stored++
+		return nil
+	}
+	if err := put(first.Symbol); err != nil {
+		return nil, err
+	}
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return res, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %v", raptorq.ErrIO, err)
+		}
+		if err := put(msg.Symbol); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// checkSymbol verifies a received symbol against layout and returns the
+// reason it is rejected, or "" if it is accepted.
+func checkSymbol(layout *raptorq.Layout, sym *Symbol) string {
+	block, ok := layout.Block(sym.BlockID)
+	if !ok {
+		return fmt.Sprintf("the object has no block %d", sym.BlockID)
+	}
+	listed := false
+	for _, id := range block.Symbols {
+		listed = listed || id == sym.SymbolID
+	}
+	if !listed {
+		return fmt.Sprintf("block %d has no symbol %s", sym.BlockID, sym.SymbolID)
+	}
+	if oti, err := block.OTI(); err == nil && len(sym.Data) > payloadIDSize+int(oti.SymbolSize) {
+		return fmt.Sprintf("symbol of %d bytes exceeds the symbol size %d", len(sym.Data), oti.SymbolSize)
+	}
+	if hash := raptorq.ContentHash(sym.Data); hash != sym.Hash || hash != sym.SymbolID {
+		return "hash mismatch"
+	}
+	return ""
+}
+
+// createObject creates an object with the given layout file content, unless
+// it exists with the same layout.
+func (s *Server) createObject(id string, data []byte) error {
+	layout, err := raptorq.ParseLayout(data)
+	if err != nil {
+		return err
+	}
+	sameLayout := func() error {
+		existing, err := s.loadObjectLayout(id)
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(existing, layout) {
+			return fmt.Errorf("%w: object %s exists with a different layout", raptorq.ErrInvalidParameters, id)
+		}
+		return nil
+	}
+	if _, err := os.Stat(s.objectDir(id)); err == nil {
+		return sameLayout()
+	}
+
+	staging, err := os.MkdirTemp(s.cfg.Dir, ".staging-")
+	if err != nil {
+		return fmt.Errorf("%w: %v", raptorq.ErrIO, err)
+	}
+	defer os.RemoveAll(staging)
+	if err := os.WriteFile(filepath.Join(staging, layoutFileName), data, 0644); err != nil {
+		return fmt.Errorf("%w: %v", raptorq.ErrIO, err)
+	}
+	if err := os.Rename(staging, s.objectDir(id)); err != nil {
+		// Another call created the object in the meantime
+		return sameLayout()
+	}
+	return nil
+}
+
+// GetSymbols implements rqpb.SymbolServiceServer. Missing and corrupt symbols
+// are skipped.
+func (r *rpcService) GetSymbols(req *rqpb.GetSymbolsRequest, stream rqpb.SymbolService_GetSymbolsServer) error {
+	ctx, done, err := r.s.startCall(stream.Context())
+	if err != nil {
+		return err
+	}
+	defer done()
+	if err := r.s.getSymbols(ctx, req, stream); err != nil {
+		return statusOfError(err)
+	}
+	return nil
+}
+
+// getSymbols sends the symbols selected by req to stream.
+func (s *Server) getSymbols(ctx context.Context, req *rqpb.GetSymbolsRequest, stream rqpb.SymbolService_GetSymbolsServer) error {
+	layout, err := s.loadObjectLayout(req.ObjectId)
+	if err != nil {
+		return err
+	}
+
+	blocks := layout.Blocks
+	if len(req.BlockIds) > 0 {
+		blocks = nil
+		for _, id := range req.BlockIds {
+			block, ok := layout.Block(id)
+			if !ok {
+				return fmt.Errorf("%w: object %s has no block %d", raptorq.ErrInvalidParameters, req.ObjectId, id)
+			}
+			blocks = append(blocks, *block)
+		}
+	}
+	selected := make(map[string]bool, len(req.SymbolIds))
+	for _, id := range req.SymbolIds {
+		selected[id] = true
+	}
+
+	store := raptorq.NewDirStore(s.objectDir(req.ObjectId))
+	for _, block := range blocks {
+		for _, id := range block.Symbols {
+			if len(selected) > 0 && !selected[id] {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			data, err := store.Get(ctx, block.BlockID, id)
+			if errors.Is(err, raptorq.ErrSymbolNotFound) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			hash := raptorq.ContentHash(data)
+			if hash != id {
+				continue
+			}
+			if err := stream.Send(&rqpb.Symbol{BlockId: block.BlockID, SymbolId: id, Hash: hash, Data: data}); err != nil {
+				return fmt.Errorf("%w: %v", raptorq.ErrIO, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Verify implements rqpb.SymbolServiceServer.
+func (r *rpcService) Verify(ctx context.Context, req *rqpb.ObjectRequest) (*rqpb.VerificationReport, error) {
+	_, done, err := r.s.startCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	layout, err := r.s.loadObjectLayout(req.ObjectId)
+	if err != nil {
+		return nil, statusOfError(err)
+	}
+	report, err := raptorq.VerifySymbols(r.s.objectDir(req.ObjectId), layout)
+	if err != nil {
+		return nil, statusOfError(err)
+	}
+	return reportToProto(report), nil
+}
+
+// loadObjectLayout checks the object ID and loads the layout of the object.
+func (s *Server) loadObjectLayout(id string) (*raptorq.Layout, error) {
+	if !validObjectID(id) {
+		return nil, unknownObject(id)
+	}
+	return s.loadLayout(id)
+}
+
+// unknownObject returns the error of an object ID that does not exist.
+func unknownObject(id string) error {
+	return fmt.Errorf("%w: unknown object %q", raptorq.ErrFileNotFound, id)
+}
+
+// resultToProto converts the result of an encoding into its message. The
+// paths of the server are left out.
+func resultToProto(result *raptorq.ProcessResult) *rqpb.ProcessResult {
+	msg := &rqpb.ProcessResult{
+		TotalSymbolsCount:  result.TotalSymbolsCount,
+		TotalRepairSymbols: result.TotalRepairSymbols,
+	}
+	for _, b := range result.Blocks {
+		msg.Blocks = append(msg.Blocks, &rqpb.Block{
+			BlockId:            b.BlockID,
+			EncoderParameters:  b.EncoderParameters,
+			OriginalOffset:     b.OriginalOffset,
+			Size:               b.Size,
+			SymbolsCount:       b.SymbolsCount,
+			SourceSymbolsCount: b.SourceSymbolsCount,
+			Hash:               b.Hash,
+		})
+	}
+	return msg
+}
+
+// resultFromProto converts a ProcessResult message.
+func resultFromProto(msg *rqpb.ProcessResult) *raptorq.ProcessResult {
+	result := &raptorq.ProcessResult{
+		TotalSymbolsCount:  msg.GetTotalSymbolsCount(),
+		TotalRepairSymbols: msg.GetTotalRepairSymbols(),
+	}
+	for _, b := range msg.GetBlocks() {
+		result.Blocks = append(result.Blocks, raptorq.Block{
+			BlockID:            b.BlockId,
+			EncoderParameters:  b.EncoderParameters,
+			OriginalOffset:     b.OriginalOffset,
+			Size:               b.Size,
+			SymbolsCount:       b.SymbolsCount,
+			SourceSymbolsCount: b.SourceSymbolsCount,
+			Hash:               b.Hash,
+		})
+	}
+	return result
+}
+
+// reportToProto converts a verification report into its message. The
+// directories of the server mean nothing to the client and are left out.
+func reportToProto(report *raptorq.VerificationReport) *rqpb.VerificationReport {
+	msg := &rqpb.VerificationReport{Decodable: report.Decodable}
+	for _, b := range report.Blocks {
+		msg.Blocks = append(msg.Blocks, &rqpb.BlockVerification{
+			BlockId:       b.BlockID,
+			Expected:      int32(b.Expected),
+			SourceSymbols: int32(b.SourceSymbols),
+			Valid:         b.Valid,
+			Missing:       b.Missing,
+			Corrupt:       b.Corrupt,
+			Unexpected:    b.Unexpected,
+			Duplicate:     b.Duplicate,
+			Decodable:     b.Decodable,
+		})
+	}
+	return msg
+}
+
+// reportFromProto converts a VerificationReport message.
+func reportFromProto(msg *rqpb.VerificationReport) *raptorq.VerificationReport {
+	report := &raptorq.VerificationReport{Decodable: msg.GetDecodable()}
+	for _, b := range msg.GetBlocks() {
+		report.Blocks = append(report.Blocks, raptorq.BlockVerification{
+			BlockID:       b.BlockId,
+			Expected:      int(b.Expected),
+			SourceSymbols: int(b.SourceSymbols),
+			Valid:         b.Valid,
+			Missing:       b.Missing,
+			Corrupt:       b.Corrupt,
+			Unexpected:    b.Unexpected,
+			Duplicate:     b.Duplicate,
+			Decodable:     b.Decodable,
+		})
+	}
+	return report
+}
+
+// symbolToProto converts a symbol into its message.
+func symbolToProto(sym *Symbol) *rqpb.Symbol {
+	return &rqpb.Symbol{BlockId: sym.BlockID, SymbolId: sym.SymbolID, Hash: sym.Hash, Data: sym.Data}
+}
+
+// symbolFromProto converts a Symbol message.
+func symbolFromProto(msg *rqpb.Symbol) *Symbol {
+	return &Symbol{BlockID: msg.BlockId, SymbolID: msg.SymbolId, Hash: msg.Hash, Data: msg.Data}
+}
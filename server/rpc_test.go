@@ -0,0 +1,244 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	raptorq "github.com/LumeraProtocol/rq-go"
+	"github.com/LumeraProtocol/rq-go/server/rqpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newRPCNode starts a server serving RPC on an in-memory listener and returns
+// a gRPC connection to it
+func newRPCNode(t *testing.T, cfg Config) (*Server, *grpc.ClientConn) {
+	t.Helper()
+	srv, _ := newTestServer(t, cfg)
+	l := bufconn.Listen(1 << 20)
+	done := make(chan error, 1)
+	go func() { done <- srv.ServeRPC(l) }()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		srv.Close()
+		if err := <-done; err != nil {
+			t.Errorf("ServeRPC failed: %v", err)
+		}
+	})
+	return srv, conn
+}
+
+// Unit test for transmitting error classes
+func TestRemoteError(t *testing.T) {
+	for _, err := range []error{
+		raptorq.ErrSymbolNotFound, raptorq.ErrInsufficientSymbols, raptorq.ErrHashMismatch,
+		ErrObjectTooLarge, context.Canceled,
+	} {
+		remote := remoteError(methodDecode, statusOfError(err))
+		if !errors.Is(remote, err) {
+			t.Errorf("Error %v transmitted as %v does not match", err, remote)
+		}
+	}
+	if errors.Is(&RemoteError{Code: "SYMBOL_NOT_FOUND"}, raptorq.ErrHashMismatch) {
+		t.Error("Unexpected match of another error class")
+	}
+	if code := status.Code(statusOfError(raptorq.ErrHashMismatch)); code != codes.DataLoss {
+		t.Errorf("Expected DataLoss, got %v", code)
+	}
+	var remote *RemoteError
+	if err := remoteError(methodDecode, statusOfError(errors.New("boom"))); !errors.As(err, &remote) || remote.Code != "INTERNAL" {
+		t.Errorf("Expected INTERNAL, got %v", err)
+	}
+	// Statuses without details are failures in transit
+	if err := remoteError(methodDecode, status.Error(codes.Unavailable, "connection refused")); !errors.Is(err, raptorq.ErrIO) {
+		t.Errorf("Expected ErrIO, got %v", err)
+	}
+}
+
+// System test moving an object between two nodes over RPC
+func TestSysRPC(t *testing.T) {
+	ctx := context.Background()
+	src, srcConn := newRPCNode(t, Config{})
+	_, dstConn := newRPCNode(t, Config{})
+	srcClient, dstClient := NewClient(srcConn), NewClient(dstConn)
+	data := make([]byte, 300*1024+17)
+	rand.New(rand.NewSource(2)).Read(data)
+
+	res, err := srcClient.Encode(ctx, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	if len(res.Result.Blocks) != 3 || !validObjectID(res.ObjectID) {
+		t.Fatalf("Unexpected encoding result: %+v", res)
+	}
+	id := res.ObjectID
+
+	var out bytes.Buffer
+	if n, err := srcClient.Decode(ctx, id, 0, 0, &out); err != nil || n != int64(len(data)) || !bytes.Equal(out.Bytes(), data) {
+		t.Fatalf("Failed to decode (%d bytes): %v", n, err)
+	}
+	out.Reset()
+	if _, err := srcClient.Decode(ctx, id, 200000, 1000, &out); err != nil || !bytes.Equal(out.Bytes(), data[200000:201000]) {
+		t.Fatalf("Failed to decode a range: %v", err)
+	}
+
+	layout, err := srcClient.GetLayout(ctx, id)
+	if err != nil || len(layout.Blocks) != 3 {
+		t.Fatalf("Failed to get the layout: %v", err)
+	}
+
+	// Copy the symbols of every block but one, and one damaged symbol
+	sender, err := dstClient.PutSymbols(ctx, id, layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skipped := layout.Blocks[1].Symbols[0]
+	err = srcClient.GetSymbols(ctx, GetSymbolsRequest{ObjectID: id}, func(s *Symbol) error {
+		if s.SymbolID == skipped {
+			return nil
+		}
+		return sender.Send(s)
+	})
+	if err != nil {
+		t.Fatalf("Failed to get symbols: %v", err)
+	}
+	if err := sender.Send(&Symbol{BlockID: 1, SymbolID: skipped, Data: []byte("tampered")}); err != nil {
+		t.Fatal(err)
+	}
+	put, err := sender.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("Failed to put symbols: %v", err)
+	}
+	if put.Stored != layout.TotalSymbols()-1 || len(put.Rejected) != 1 || put.Rejected[0].SymbolID != skipped {
+		t.Fatalf("Unexpected PutSymbols response: stored %d, rejected %+v", put.Stored, put.Rejected)
+	}
+
+	report, err := dstClient.Verify(ctx, id)
+	if err != nil {
+		t.Fatalf("Failed to verify: %v", err)
+	}
+	if report.Healthy() || len(report.Blocks[1].Missing) != 1 || report.Blocks[1].Missing[0] != skipped {
+		t.Fatalf("Expected the skipped symbol to be missing: %+v", report.Blocks[1])
+	}
+
+	// Restore the symbol from the source node
+	sender, err = dstClient.PutSymbols(ctx, id, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = srcClient.GetSymbols(ctx, GetSymbolsRequest{ObjectID: id, BlockIDs: []uint64{1}, SymbolIDs: []string{skipped}}, sender.Send)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if put, err := sender.CloseAndRecv(); err != nil || put.Stored != 1 {
+		t.Fatalf("Failed to restore the symbol: %+v, %v", put, err)
+	}
+	if report, err := dstClient.Verify(ctx, id); err != nil || !report.Healthy() {
+		t.Fatalf("Expected healthy symbols on the destination: %v", err)
+	}
+	out.Reset()
+	if _, err := dstClient.Decode(ctx, id, 0, 0, &out); err != nil || !bytes.Equal(out.Bytes(), data) {
+		t.Fatalf("Failed to decode on the destination: %v", err)
+	}
+
+	// Corrupt symbols of the source are not sent
+	corrupt := filepath.Join(src.objectDir(id), "block_0", layout.Blocks[0].Symbols[0])
+	if err := os.WriteFile(corrupt, []byte("corrupt"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sent := 0
+	if err := srcClient.GetSymbols(ctx, GetSymbolsRequest{ObjectID: id, BlockIDs: []uint64{0}}, func(*Symbol) error { sent++; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if sent != len(layout.Blocks[0].Symbols)-1 {
+		t.Errorf("Expected the corrupt symbol to be skipped, got %d symbols", sent)
+	}
+
+	// Errors
+	unknown := "0123456789abcdef0123456789abcdef"
+	if _, err := srcClient.GetLayout(ctx, unknown); !errors.Is(err, raptorq.ErrFileNotFound) {
+		t.Errorf("Expected ErrFileNotFound for an unknown object, got %v", err)
+	}
+	if _, err := srcClient.Decode(ctx, id, uint64(len(data)), 10, io.Discard); !errors.Is(err, raptorq.ErrInvalidParameters) {
+		t.Errorf("Expected ErrInvalidParameters for a range beyond the end, got %v", err)
+	}
+	sender, _ = dstClient.PutSymbols(ctx, unknown, nil)
+	if _, err := sender.CloseAndRecv(); !errors.Is(err, raptorq.ErrFileNotFound) {
+		t.Errorf("Expected ErrFileNotFound for symbols of an unknown object, got %v", err)
+	}
+	other := *layout
+	other.Blocks = layout.Blocks[:1]
+	sender, _ = dstClient.PutSymbols(ctx, id, &other)
+	if _, err := sender.CloseAndRecv(); !errors.Is(err, raptorq.ErrInvalidParameters) {
+		t.Errorf("Expected ErrInvalidParameters for a different layout, got %v", err)
+	}
+
+	// Clients generated from rq.proto speak the same protocol
+	raw := rqpb.NewSymbolServiceClient(srcConn)
+	if res, err := raw.GetLayout(ctx, &rqpb.ObjectRequest{ObjectId: id}); err != nil || len(res.Layout) == 0 {
+		t.Fatalf("Failed to get the layout with the generated client: %v", err)
+	}
+	if _, err := raw.GetLayout(ctx, &rqpb.ObjectRequest{ObjectId: unknown}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound from the generated client, got %v", err)
+	}
+
+	_, limitedConn := newRPCNode(t, Config{MaxObjectSize: 1000})
+	if _, err := NewClient(limitedConn).Encode(ctx, bytes.NewReader(data), -1); !errors.Is(err, ErrObjectTooLarge) {
+		t.Errorf("Expected ErrObjectTooLarge, got %v", err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := srcClient.Verify(canceled, id); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// Unit test for aborting a Decode call in progress with Close
+func TestRPCCloseAbortsDecode(t *testing.T) {
+	srv, conn := newRPCNode(t, Config{Pool: newFakePool(t)})
+	blockSize := 256 * 1024
+	data := make([]byte, 4*blockSize)
+	rand.New(rand.NewSource(6)).Read(data)
+	id := storeObject(t, srv, data, blockSize)
+
+	closed := make(chan error, 1)
+	var out bytes.Buffer
+	w := writerFunc(func(p []byte) (int, error) {
+		if out.Len() == 0 {
+			go func() { closed <- srv.Close() }()
+			<-srv.ctx.Done()
+		}
+		return out.Write(p)
+	})
+	n, err := NewClient(conn).Decode(context.Background(), id, 0, 0, w)
+	if err == nil || n >= int64(len(data)) {
+		t.Fatalf("Expected the call to be aborted, got %d bytes and: %v", n, err)
+	}
+	if err := <-closed; err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+}
+
+// writerFunc is an io.Writer calling a function
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
@@ -0,0 +1,12 @@
+// Package rqpb holds the Go code generated from rq.proto: the messages of the
+// SymbolService and its gRPC client and server interfaces. Package server
+// implements the service on a Server and wraps the client.
+//
+// Regenerate the code from the root of the repository with:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	    --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+//	    server/rqpb/rq.proto
+package rqpb
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative server/rqpb/rq.proto
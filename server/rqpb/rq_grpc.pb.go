@@ -0,0 +1,325 @@
+// SymbolService moves RaptorQ objects and their symbols between nodes.
+//
+// Server.ServeRPC and Client in package server implement and call the service
+// over gRPC, with the Go code generated from this file into package rqpb.
+// Failed calls carry a google.rpc.ErrorInfo detail in the "rq-go" domain whose
+// reason names the error class, e.g. "HASH_MISMATCH".
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: server/rqpb/rq.proto
+
+package rqpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SymbolService_Encode_FullMethodName     = "/rq.v1.SymbolService/Encode"
+	SymbolService_Decode_FullMethodName     = "/rq.v1.SymbolService/Decode"
+	SymbolService_GetLayout_FullMethodName  = "/rq.v1.SymbolService/GetLayout"
+	SymbolService_PutSymbols_FullMethodName = "/rq.v1.SymbolService/PutSymbols"
+	SymbolService_GetSymbols_FullMethodName = "/rq.v1.SymbolService/GetSymbols"
+	SymbolService_Verify_FullMethodName     = "/rq.v1.SymbolService/Verify"
+)
+
+// SymbolServiceClient is the client API for SymbolService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SymbolServiceClient interface {
+	// Encode encodes a stream of data chunks into a new object.
+	Encode(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[EncodeRequest, EncodeResponse], error)
+	// Decode streams the decoded bytes of a range of an object.
+	Decode(ctx context.Context, in *DecodeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DataChunk], error)
+	// GetLayout returns the layout file of an object.
+	GetLayout(ctx context.Context, in *ObjectRequest, opts ...grpc.CallOption) (*GetLayoutResponse, error)
+	// PutSymbols stores a stream of symbols, each verified against the layout
+	// on arrival. Symbols failing verification are reported, not stored.
+	PutSymbols(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PutSymbolsRequest, PutSymbolsResponse], error)
+	// GetSymbols streams the intact symbols of an object.
+	GetSymbols(ctx context.Context, in *GetSymbolsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Symbol], error)
+	// Verify checks the symbols of an object against its layout.
+	Verify(ctx context.Context, in *ObjectRequest, opts ...grpc.CallOption) (*VerificationReport, error)
+}
+
+type symbolServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSymbolServiceClient(cc grpc.ClientConnInterface) SymbolServiceClient {
+	return &symbolServiceClient{cc}
+}
+
+func (c *symbolServiceClient) Encode(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[EncodeRequest, EncodeResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SymbolService_ServiceDesc.Streams[0], SymbolService_Encode_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[EncodeRequest, EncodeResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SymbolService_EncodeClient = grpc.ClientStreamingClient[EncodeRequest, EncodeResponse]
+
+func (c *symbolServiceClient) Decode(ctx context.Context, in *DecodeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DataChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SymbolService_ServiceDesc.Streams[1], SymbolService_Decode_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DecodeRequest, DataChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SymbolService_DecodeClient = grpc.ServerStreamingClient[DataChunk]
+
+func (c *symbolServiceClient) GetLayout(ctx context.Context, in *ObjectRequest, opts ...grpc.CallOption) (*GetLayoutResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetLayoutResponse)
+	err := c.cc.Invoke(ctx, SymbolService_GetLayout_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *symbolServiceClient) PutSymbols(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PutSymbolsRequest, PutSymbolsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SymbolService_ServiceDesc.Streams[2], SymbolService_PutSymbols_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PutSymbolsRequest, PutSymbolsResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SymbolService_PutSymbolsClient = grpc.ClientStreamingClient[PutSymbolsRequest, PutSymbolsResponse]
+
+func (c *symbolServiceClient) GetSymbols(ctx context.Context, in *GetSymbolsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Symbol], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SymbolService_ServiceDesc.Streams[3], SymbolService_GetSymbols_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetSymbolsRequest, Symbol]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SymbolService_GetSymbolsClient = grpc.ServerStreamingClient[Symbol]
+
+func (c *symbolServiceClient) Verify(ctx context.Context, in *ObjectRequest, opts ...grpc.CallOption) (*VerificationReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerificationReport)
+	err := c.cc.Invoke(ctx, SymbolService_Verify_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SymbolServiceServer is the server API for SymbolService service.
+// All implementations must embed UnimplementedSymbolServiceServer
+// for forward compatibility.
+type SymbolServiceServer interface {
+	// Encode encodes a stream of data chunks into a new object.
+	Encode(grpc.ClientStreamingServer[EncodeRequest, EncodeResponse]) error
+	// Decode streams the decoded bytes of a range of an object.
+	Decode(*DecodeRequest, grpc.ServerStreamingServer[DataChunk]) error
+	// GetLayout returns the layout file of an object.
+	GetLayout(context.Context, *ObjectRequest) (*GetLayoutResponse, error)
+	// PutSymbols stores a stream of symbols, each verified against the layout
+	// on arrival. Symbols failing verification are reported, not stored.
+	PutSymbols(grpc.ClientStreamingServer[PutSymbolsRequest, PutSymbolsResponse]) error
+	// GetSymbols streams the intact symbols of an object.
+	GetSymbols(*GetSymbolsRequest, grpc.ServerStreamingServer[Symbol]) error
+	// Verify checks the symbols of an object against its layout.
+	Verify(context.Context, *ObjectRequest) (*VerificationReport, error)
+	mustEmbedUnimplementedSymbolServiceServer()
+}
+
+// UnimplementedSymbolServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSymbolServiceServer struct{}
+
+func (UnimplementedSymbolServiceServer) Encode(grpc.ClientStreamingServer[EncodeRequest, EncodeResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Encode not implemented")
+}
+func (UnimplementedSymbolServiceServer) Decode(*DecodeRequest, grpc.ServerStreamingServer[DataChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method Decode not implemented")
+}
+func (UnimplementedSymbolServiceServer) GetLayout(context.Context, *ObjectRequest) (*GetLayoutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLayout not implemented")
+}
+func (UnimplementedSymbolServiceServer) PutSymbols(grpc.ClientStreamingServer[PutSymbolsRequest, PutSymbolsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method PutSymbols not implemented")
+}
+func (UnimplementedSymbolServiceServer) GetSymbols(*GetSymbolsRequest, grpc.ServerStreamingServer[Symbol]) error {
+	return status.Errorf(codes.Unimplemented, "method GetSymbols not implemented")
+}
+func (UnimplementedSymbolServiceServer) Verify(context.Context, *ObjectRequest) (*VerificationReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Verify not implemented")
+}
+func (UnimplementedSymbolServiceServer) mustEmbedUnimplementedSymbolServiceServer() {}
+func (UnimplementedSymbolServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeSymbolServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SymbolServiceServer will
+// result in compilation errors.
+type UnsafeSymbolServiceServer interface {
+	mustEmbedUnimplementedSymbolServiceServer()
+}
+
+func RegisterSymbolServiceServer(s grpc.ServiceRegistrar, srv SymbolServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSymbolServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SymbolService_ServiceDesc, srv)
+}
+
+func _SymbolService_Encode_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SymbolServiceServer).Encode(&grpc.GenericServerStream[EncodeRequest, EncodeResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SymbolService_EncodeServer = grpc.ClientStreamingServer[EncodeRequest, EncodeResponse]
+
+func _SymbolService_Decode_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DecodeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SymbolServiceServer).Decode(m, &grpc.GenericServerStream[DecodeRequest, DataChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SymbolService_DecodeServer = grpc.ServerStreamingServer[DataChunk]
+
+func _SymbolService_GetLayout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ObjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SymbolServiceServer).GetLayout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SymbolService_GetLayout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SymbolServiceServer).GetLayout(ctx, req.(*ObjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SymbolService_PutSymbols_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SymbolServiceServer).PutSymbols(&grpc.GenericServerStream[PutSymbolsRequest, PutSymbolsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SymbolService_PutSymbolsServer = grpc.ClientStreamingServer[PutSymbolsRequest, PutSymbolsResponse]
+
+func _SymbolService_GetSymbols_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetSymbolsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SymbolServiceServer).GetSymbols(m, &grpc.GenericServerStream[GetSymbolsRequest, Symbol]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SymbolService_GetSymbolsServer = grpc.ServerStreamingServer[Symbol]
+
+func _SymbolService_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ObjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SymbolServiceServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SymbolService_Verify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SymbolServiceServer).Verify(ctx, req.(*ObjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SymbolService_ServiceDesc is the grpc.ServiceDesc for SymbolService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SymbolService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "rq.v1.SymbolService",
+	HandlerType: (*SymbolServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLayout",
+			Handler:    _SymbolService_GetLayout_Handler,
+		},
+		{
+			MethodName: "Verify",
+			Handler:    _SymbolService_Verify_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Encode",
+			Handler:       _SymbolService_Encode_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Decode",
+			Handler:       _SymbolService_Decode_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "PutSymbols",
+			Handler:       _SymbolService_PutSymbols_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "GetSymbols",
+			Handler:       _SymbolService_GetSymbols_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "server/rqpb/rq.proto",
+}
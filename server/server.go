@@ -0,0 +1,613 @@
+// Package server exposes RaptorQ encoding and decoding over HTTP.
+//
+// A Server stores every uploaded object as a symbols directory with its
+// layout, named by a random object ID, and serves the following endpoints:
+//
+//	POST /objects                                   Encode the request body, returns the object ID and the ProcessResult
+//	GET  /objects/{id}                              Decode the object; a Range header selects part of it
+//	GET  /objects/{id}/layout                       The layout of the object
+//	GET  /objects/{id}/blocks/{block}/symbols/{sid} A symbol
+//	PUT  /objects/{id}/blocks/{block}/symbols/{sid} Store a symbol of the layout, checked against its ID
+//
+// Request and response bodies are streamed: uploads are encoded one block of
+// at most Config.MaxBlockSize bytes at a time as they are read, and downloads
+// are decoded one block at a time as they are written. Sessions are taken from a ProcessorPool, which bounds the
+// number of concurrent operations and their memory.
+//
+// Server.ServeRPC serves the same objects to Client over gRPC for moving
+// symbols between nodes, implementing the SymbolService defined in
+// rqpb/rq.proto. Symbols in transit carry their block ID, symbol ID and hash,
+// and are verified against the layout on arrival.
+//
+// Errors are reported as JSON objects with an "error" member, with a status
+// derived from the error class: 400 for invalid input, 404 for unknown
+// objects and symbols, 413 for oversized uploads, and 503 when an object
+// cannot currently be decoded or a resource limit is reached.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	raptorq "github.com/LumeraProtocol/rq-go"
+	"google.golang.org/grpc"
+)
+
+// layoutFileName is the name of the layout file in the directory of an object.
+const layoutFileName = "_raptorq_layout.json"
+
+// payloadIDSize is the size of the RFC 6330 payload ID in front of the data of
+// every symbol file.
+const payloadIDSize = 4
+
+// ErrObjectTooLarge is returned for uploads exceeding Config.MaxObjectSize.
+var ErrObjectTooLarge = errors.New("object too large")
+
+// DefaultMaxSessions is the session limit of the pool a Server creates when
+// Config.Pool is nil.
+const DefaultMaxSessions = 4
+
+// Config configures a Server.
+type Config struct {
+	// Dir is the directory the objects are stored in. It is created if it
+	// does not exist.
+	Dir string
+
+	// Processor is the configuration of the sessions encoding uploads. Objects
+	// are decoded with the symbol size of their layout. A zero value selects
+	// raptorq.DefaultProcessorConfig.
+	Processor raptorq.ProcessorConfig
+
+	// Pool provides the sessions. If nil, the Server creates a pool limited to
+	// MaxSessions sessions and closes it in Close.
+	Pool *raptorq.ProcessorPool
+
+	// MaxSessions is the session limit of the pool created when Pool is nil.
+	// If 0, DefaultMaxSessions is used.
+	MaxSessions int
+
+	// BlockSize is the block size uploads are encoded with. If 0, it is chosen
+	// from the Content-Length of the upload as EncodeFile does, or
+	// raptorq.DefaultStreamBlockSize if the length is unknown.
+	BlockSize int
+
+	// MaxBlockSize caps the block size uploads are encoded with, since every
+	// block is held in memory while it is encoded; the size chosen from the
+	// Content-Length can otherwise be the whole upload. If 0, it is BlockSize,
+	// or raptorq.DefaultStreamBlockSize if BlockSize is 0.
+	MaxBlockSize int
+
+	// MaxObjectSize limits the size of uploads in bytes. 0 means no limit.
+	MaxObjectSize int64
+}
+
+// Server is an http.Handler serving the endpoints described in the package
+// documentation. ServeRPC serves the same objects over gRPC.
+type Server struct {
+	cfg     Config
+	pool    *raptorq.ProcessorPool
+	ownPool bool
+
+	// ctx is canceled by Close and aborts the RPC calls in progress.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	closed      bool
+	grpcServers map[*grpc.Server]bool
+	calls       sync.WaitGroup
+}
+
+// New validates cfg and returns a Server storing its objects in cfg.Dir.
+//
+// Example:
+//
+//	srv, err := server.New(server.Config{Dir: "/var/lib/rq", MaxSessions: 8})
+//	if err != nil {
+//	    return err
+//	}
+//	defer srv.Close()
+//	log.Fatal(http.ListenAndServe(":8080", srv))
+func New(cfg Config) (*Server, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("%w: Dir cannot be empty", raptorq.ErrInvalidParameters)
+	}
+	if cfg.Processor == (raptorq.ProcessorConfig{}) {
+		cfg.Processor = raptorq.DefaultProcessorConfig()
+	}
+	if err := cfg.Processor.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Processor.ValidateBlockSize(cfg.BlockSize); err != nil {
+		return nil, err
+	}
+	if cfg.MaxBlockSize == 0 {
+		cfg.MaxBlockSize = cfg.BlockSize
+		if cfg.MaxBlockSize == 0 {
+			cfg.MaxBlockSize = raptorq.DefaultStreamBlockSize
+		}
+	}
+	if cfg.MaxBlockSize < 0 || cfg.BlockSize > cfg.MaxBlockSize {
+		return nil, fmt.Errorf("%w: MaxBlockSize %d must be positive and at least BlockSize %d",
+			raptorq.ErrInvalidParameters, cfg.MaxBlockSize, cfg.BlockSize)
+	}
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("%w: %v", raptorq.ErrIO, err)
+	}
+
+	s := &Server{
+		cfg:         cfg,
+		pool:        cfg.Pool,
+		grpcServers: make(map[*grpc.Server]bool),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	if s.pool == nil {
+		maxSessions := cfg.MaxSessions
+		if maxSessions == 0 {
+			maxSessions = DefaultMaxSessions
+		}
+		s.pool = raptorq.NewProcessorPool(raptorq.PoolConfig{Default: cfg.Processor, MaxSessions: maxSessions})
+		s.ownPool = true
+	}
+	return s, nil
+}
+
+// Close stops the gRPC servers started by ServeRPC, aborts the RPC calls in
+// progress and closes the pool of the Server if the Server created it. HTTP
+// requests are not affected; shut down the http.Server first.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	servers := make([]*grpc.Server, 0, len(s.grpcServers))
+	for g := range s.grpcServers {
+		servers = append(servers, g)
+	}
+	s.mu.Unlock()
+	s.cancel()
+	for _, g := range servers {
+		g.Stop()
+	}
+	s.calls.Wait()
+
+	if s.ownPool {
+		return s.pool.Close()
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	if parts[0] != "objects" {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	switch {
+	case len(parts) == 1:
+		if allow(w, r, http.MethodPost) {
+			s.encode(w, r)
+		}
+	case !validObjectID(parts[1]):
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown object %q", parts[1]))
+	case len(parts) == 2:
+		if allow(w, r, http.MethodGet, http.MethodHead) {
+			s.decode(w, r, parts[1])
+		}
+	case len(parts) == 3 && parts[2] == "layout":
+		if allow(w, r, http.MethodGet, http.MethodHead) {
+			s.layout(w, r, parts[1])
+		}
+	case len(parts) == 6 && parts[2] == "blocks" && parts[4] == "symbols":
+		blockID, err := strconv.ParseUint(parts[3], 10, 64)
+		if err != nil {
+			writeError(w, http.StatusNotFound, fmt.Errorf("invalid block ID %q", parts[3]))
+			return
+		}
+		if allow(w, r, http.MethodGet, http.MethodHead, http.MethodPut) {
+			if r.Method == http.MethodPut {
+				s.putSymbol(w, r, parts[1], blockID, parts[5])
+			} else {
+				s.getSymbol(w, r, parts[1], blockID, parts[5])
+			}
+		}
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+// allow reports whether the method of r is one of methods, and answers with
+// 405 Method Not Allowed otherwise.
+func allow(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+// EncodeResponse is the response of POST /objects.
+type EncodeResponse struct {
+	// ObjectID identifies the object in the other endpoints.
+	ObjectID string `json:"object_id"`
+
+	// Result describes the encoding. SymbolsDirectory and LayoutFilePath are
+	// empty.
+	Result *raptorq.ProcessResult `json:"result"`
+}
+
+// encode handles POST /objects.
+func (s *Server) encode(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.MaxObjectSize > 0 && r.ContentLength > s.cfg.MaxObjectSize {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("%w: object exceeds %d bytes", ErrObjectTooLarge, s.cfg.MaxObjectSize))
+		return
+	}
+	id, obj, err := s.encodeObject(r.Context(), r.Body, r.ContentLength)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	w.Header().Set("Location", "/objects/"+id)
+	writeJSON(w, http.StatusCreated, EncodeResponse{ObjectID: id, Result: obj.Result})
+}
+
+// encodeObject encodes the object read from r, of size bytes or -1 if the size
+// is unknown, into a new object directory and returns its ID.
+func (s *Server) encodeObject(ctx context.Context, r io.Reader, size int64) (string, *raptorq.EncodedObject, error) {
+	body := &limitReader{r: r, limit: s.cfg.MaxObjectSize}
+	processor, err := s.pool.AcquireConfig(ctx, s.cfg.Processor)
+	if err != nil {
+		return "", nil, err
+	}
+	defer s.pool.Release(processor)
+
+	id, err := newObjectID()
+	if err != nil {
+		return "", nil, err
+	}
+	staging, err := os.MkdirTemp(s.cfg.Dir, ".staging-")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	store := raptorq.NewDirStore(staging)
+	enc := processor.NewEncoder(size, s.cfg.BlockSize)
+	if enc.BlockSize() > s.cfg.MaxBlockSize {
+		enc = processor.NewEncoder(size, s.cfg.MaxBlockSize)
+	}
+	obj, err := enc.Encode(ctx, body, func(b *raptorq.EncodedBlock) error {
+		for symbolID, data := range b.Symbols {
+			if err := store.Put(ctx, b.Block.BlockID, symbolID, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// The encoder reports read errors as I/O errors; an oversized upload is
+		// recognized from the reader itself.
+		if body.exceeded {
+			err = fmt.Errorf("%w: object exceeds %d bytes", ErrObjectTooLarge, s.cfg.MaxObjectSize)
+		}
+		return "", nil, err
+	}
+	if err := os.WriteFile(filepath.Join(staging, layoutFileName), obj.Layout, 0644); err != nil {
+		return "", nil, err
+	}
+	if err := os.Rename(staging, s.objectDir(id)); err != nil {
+		return "", nil, err
+	}
+	return id, obj, nil
+}
+
+// limitReader fails once more than limit bytes are read, unless limit is 0.
+type limitReader struct {
+	r        io.Reader
+	limit    int64
+	read     int64
+	exceeded bool
+}
+
+// Read implements io.Reader.
+func (lr *limitReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+	lr.read += int64(n)
+	if lr.limit > 0 && lr.read > lr.limit {
+		lr.exceeded = true
+		return 0, ErrObjectTooLarge
+	}
+	return n, err
+}
+
+// decode handles GET /objects/{id}.
+func (s *Server) decode(w http.ResponseWriter, r *http.Request, id string) {
+	layout, err := s.loadLayout(id)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	size := layout.TotalSize()
+	offset, length, status := uint64(0), size, http.StatusOK
+	if header := r.Header.Get("Range"); header != "" {
+		var ok bool
+		if offset, length, ok = parseRange(header, size); !ok {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			writeError(w, http.StatusRequestedRangeNotSatisfiable, fmt.Errorf("invalid range %q", header))
+			return
+		}
+		if length != size || offset != 0 {
+			status = http.StatusPartialContent
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, size))
+		}
+	}
+
+	processor, err := s.pool.AcquireConfig(r.Context(), s.decodeConfig(layout))
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	defer s.pool.Release(processor)
+
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatUint(length, 10))
+	if r.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+
+	// The status is only sent with the first decoded bytes, so that a block
+	// failing before can still be reported as an error. Decoding stops before
+	// the next block once the client goes away.
+	out := &lazyWriter{w: w, status: status}
+	dir := s.objectDir(id)
+	if _, err := processor.DecodeRangeContext(r.Context(), dir, filepath.Join(dir, layoutFileName), offset, length, out); err != nil {
+		if !out.started {
+			w.Header().Del("Content-Length")
+			w.Header().Del("Content-Range")
+			writeError(w, statusOf(err), err)
+		}
+		// Otherwise the response is cut short, which the client detects from
+		// the Content-Length.
+		return
+	}
+	if length == 0 {
+		w.WriteHeader(status)
+	}
+}
+
+// lazyWriter writes the status of a response before the first body bytes.
+type lazyWriter struct {
+	w       http.ResponseWriter
+	status  int
+	started bool
+}
+
+// Write implements io.Writer.
+func (lw *lazyWriter) Write(p []byte) (int, error) {
+	if !lw.started {
+		lw.w.WriteHeader(lw.status)
+		lw.started = true
+	}
+	return lw.w.Write(p)
+}
+
+// layout handles GET /objects/{id}/layout.
+func (s *Server) layout(w http.ResponseWriter, r *http.Request, id string) {
+	f, err := os.Open(filepath.Join(s.objectDir(id), layoutFileName))
+	if err != nil {
+		writeError(w, statusOf(notFound(err, id)), notFound(err, id))
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	http.ServeContent(w, r, "", info.ModTime(), f)
+}
+
+// getSymbol handles GET /objects/{id}/blocks/{block}/symbols/{sid}.
+func (s *Server) getSymbol(w http.ResponseWriter, r *http.Request, id string, blockID uint64, symbolID string) {
+	if _, err := os.Stat(s.objectDir(id)); err != nil {
+		writeError(w, statusOf(notFound(err, id)), notFound(err, id))
+		return
+	}
+	data, err := raptorq.NewDirStore(s.objectDir(id)).Get(r.Context(), blockID, symbolID)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(data)
+	}
+}
+
+// putSymbol handles PUT /objects/{id}/blocks/{block}/symbols/{sid}. Only the
+// symbols listed for the block in the layout are accepted, and only with the
+// content matching their ID.
+func (s *Server) putSymbol(w http.ResponseWriter, r *http.Request, id string, blockID uint64, symbolID string) {
+	layout, err := s.loadLayout(id)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	block, ok := layout.Block(blockID)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("object %s has no block %d", id, blockID))
+		return
+	}
+	listed := false
+	for _, sid := range block.Symbols {
+		listed = listed || sid == symbolID
+	}
+	if !listed {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%w: block %d has no symbol %s", raptorq.ErrSymbolNotFound, blockID, symbolID))
+		return
+	}
+	oti, err := block.OTI()
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, payloadIDSize+int64(oti.SymbolSize)))
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	if raptorq.ContentHash(data) != symbolID {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%w: content does not match symbol %s", raptorq.ErrHashMismatch, symbolID))
+		return
+	}
+	if err := raptorq.NewDirStore(s.objectDir(id)).Put(r.Context(), blockID, symbolID, data); err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// objectDir returns the directory of an object.
+func (s *Server) objectDir(id string) string {
+	return filepath.Join(s.cfg.Dir, id)
+}
+
+// loadLayout loads the layout of an object.
+func (s *Server) loadLayout(id string) (*raptorq.Layout, error) {
+	layout, err := raptorq.LoadLayout(filepath.Join(s.objectDir(id), layoutFileName))
+	if err != nil {
+		return nil, notFound(err, id)
+	}
+	return layout, nil
+}
+
+// decodeConfig returns the session configuration decoding layout: the
+// configuration of the server with the symbol size of the layout.
+func (s *Server) decodeConfig(layout *raptorq.Layout) raptorq.ProcessorConfig {
+	cfg := s.cfg.Processor
+	if len(layout.Blocks) > 0 {
+		if oti, err := layout.Blocks[0].OTI(); err == nil {
+			cfg.SymbolSize = oti.SymbolSize
+		}
+	}
+	return cfg
+}
+
+// notFound converts a missing file of object id into an error matching
+// raptorq.ErrFileNotFound.
+func notFound(err error, id string) error {
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: unknown object %s", raptorq.ErrFileNotFound, id)
+	}
+	return err
+}
+
+// newObjectID returns a random object ID.
+func newObjectID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// validObjectID reports whether id has the form of the IDs of newObjectID.
+func validObjectID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil && strings.ToLower(id) == id
+}
+
+// parseRange parses a Range header with a single byte range for a resource
+// of size bytes. Ranges reaching beyond the end are shortened.
+func parseRange(header string, size uint64) (offset, length uint64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false
+	}
+	if first == "" {
+		// Suffix range: the last n bytes
+		n, err := strconv.ParseUint(last, 10, 64)
+		if err != nil || n == 0 || size == 0 {
+			return 0, 0, false
+		}
+		n = min(n, size)
+		return size - n, n, true
+	}
+	start, err := strconv.ParseUint(first, 10, 64)
+	if err != nil || start >= size {
+		return 0, 0, false
+	}
+	end := size - 1
+	if last != "" {
+		if end, err = strconv.ParseUint(last, 10, 64); err != nil || end < start {
+			return 0, 0, false
+		}
+		end = min(end, size-1)
+	}
+	return start, end - start + 1, true
+}
+
+// statusOf returns the HTTP status reporting err.
+func statusOf(err error) int {
+	var maxBytes *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytes), errors.Is(err, ErrObjectTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, raptorq.ErrInvalidParameters), errors.Is(err, raptorq.ErrInvalidLayout),
+		errors.Is(err, raptorq.ErrInvalidPath), errors.Is(err, raptorq.ErrHashMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, raptorq.ErrFileNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, raptorq.ErrDecodingFailed), errors.Is(err, raptorq.ErrMemoryLimit),
+		errors.Is(err, raptorq.ErrConcurrencyLimit), errors.Is(err, raptorq.ErrPoolClosed):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// errorResponse is the body of error responses.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeError writes err as a JSON error response with the given status.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// writeJSON writes v as the JSON body of a response with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
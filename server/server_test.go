@@ -0,0 +1,304 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	raptorq "github.com/LumeraProtocol/rq-go"
+	"github.com/LumeraProtocol/rq-go/rqtest"
+)
+
+// newTestServer starts a server with 4KB symbols and 128KB blocks
+func newTestServer(t *testing.T, cfg Config) (*Server, *httptest.Server) {
+	t.Helper()
+	if cfg.Dir == "" {
+		cfg.Dir = t.TempDir()
+	}
+	cfg.Processor = raptorq.DefaultProcessorConfig()
+	cfg.Processor.SymbolSize = 4096
+	cfg.BlockSize = 128 * 1024
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(func() {
+		ts.Close()
+		srv.Close()
+	})
+	return srv, ts
+}
+
+// newFakePool returns a pool of sessions on an rqtest.FakeBackend, for the
+// tests that do not need the native library
+func newFakePool(t *testing.T) *raptorq.ProcessorPool {
+	t.Helper()
+	pool := raptorq.NewProcessorPool(raptorq.PoolConfig{Backend: rqtest.NewFakeBackend()})
+	t.Cleanup(func() { pool.Close() })
+	return pool
+}
+
+// do sends a request and returns the status and body of the response
+func do(t *testing.T, method, url string, body io.Reader, header ...string) (int, []byte, http.Header) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response of %s %s: %v", method, url, err)
+	}
+	return resp.StatusCode, data, resp.Header
+}
+
+// Unit test for parsing Range headers
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		header         string
+		offset, length uint64
+		ok             bool
+	}{
+		{"bytes=0-99", 0, 100, true},
+		{"bytes=100-", 100, 900, true},
+		{"bytes=-10", 990, 10, true},
+		{"bytes=-5000", 0, 1000, true},
+		{"bytes=900-5000", 900, 100, true},
+		{"bytes=1000-", 0, 0, false},
+		{"bytes=5-4", 0, 0, false},
+		{"bytes=0-1,5-6", 0, 0, false},
+		{"items=0-1", 0, 0, false},
+		{"bytes=-0", 0, 0, false},
+		{"bytes=x-", 0, 0, false},
+	}
+	for _, tt := range tests {
+		offset, length, ok := parseRange(tt.header, 1000)
+		if ok != tt.ok || offset != tt.offset || length != tt.length {
+			t.Errorf("parseRange(%q) = %d, %d, %v; expected %d, %d, %v",
+				tt.header, offset, length, ok, tt.offset, tt.length, tt.ok)
+		}
+	}
+}
+
+// System test encoding, serving, repairing and decoding an object over HTTP
+func TestSysServer(t *testing.T) {
+	srv, ts := newTestServer(t, Config{})
+	data := make([]byte, 300*1024+17)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	// Uploads of unknown length are streamed as well
+	status, body, header := do(t, http.MethodPost, ts.URL+"/objects", io.MultiReader(bytes.NewReader(data)))
+	if status != http.StatusCreated {
+		t.Fatalf("Encoding failed with %d: %s", status, body)
+	}
+	var res EncodeResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Result.Blocks) != 3 || header.Get("Location") != "/objects/"+res.ObjectID {
+		t.Fatalf("Unexpected encoding result: %s", body)
+	}
+	object := ts.URL + "/objects/" + res.ObjectID
+
+	if status, body, _ := do(t, http.MethodGet, object, nil); status != http.StatusOK || !bytes.Equal(body, data) {
+		t.Fatalf("Decoding failed with %d (%d bytes)", status, len(body))
+	}
+	status, body, header = do(t, http.MethodGet, object, nil, "Range", "bytes=200000-200999")
+	if status != http.StatusPartialContent || !bytes.Equal(body, data[200000:201000]) {
+		t.Fatalf("Range request failed with %d (%d bytes)", status, len(body))
+	}
+	if got := header.Get("Content-Range"); got != "bytes 200000-200999/307217" {
+		t.Errorf("Unexpected Content-Range: %s", got)
+	}
+	if status, body, _ := do(t, http.MethodGet, object, nil, "Range", "bytes=-17"); status != http.StatusPartialContent || !bytes.Equal(body, data[len(data)-17:]) {
+		t.Fatalf("Suffix range request failed with %d", status)
+	}
+	if status, _, _ := do(t, http.MethodGet, object, nil, "Range", "bytes=999999-"); status != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("Expected 416 for a range beyond the end, got %d", status)
+	}
+
+	status, body, _ = do(t, http.MethodGet, object+"/layout", nil)
+	layout, err := raptorq.ParseLayout(body)
+	if status != http.StatusOK || err != nil || len(layout.Blocks) != 3 {
+		t.Fatalf("Failed to get the layout (%d): %v", status, err)
+	}
+
+	// Symbols are served by block and ID, and accepted back only if intact
+	block := layout.Blocks[1]
+	symbolURL := object + "/blocks/1/symbols/" + block.Symbols[0]
+	status, symbol, _ := do(t, http.MethodGet, symbolURL, nil)
+	if status != http.StatusOK || raptorq.ContentHash(symbol) != block.Symbols[0] {
+		t.Fatalf("Failed to get a symbol (%d)", status)
+	}
+	if err := os.Remove(filepath.Join(srv.objectDir(res.ObjectID), "block_1", block.Symbols[0])); err != nil {
+		t.Fatal(err)
+	}
+	if status, _, _ := do(t, http.MethodGet, symbolURL, nil); status != http.StatusNotFound {
+		t.Fatalf("Expected 404 for a missing symbol, got %d", status)
+	}
+	if res.Result.TotalRepairSymbols == 0 {
+		if status, _, _ := do(t, http.MethodGet, object, nil, "Range", "bytes=200000-200999"); status != http.StatusServiceUnavailable {
+			t.Fatalf("Expected 503 for an undecodable block, got %d", status)
+		}
+	}
+	if status, body, _ := do(t, http.MethodPut, symbolURL, bytes.NewReader([]byte("tampered"))); status != http.StatusBadRequest {
+		t.Fatalf("Expected 400 for a corrupt symbol, got %d: %s", status, body)
+	}
+	if status, _, _ := do(t, http.MethodPut, object+"/blocks/0/symbols/"+block.Symbols[0], bytes.NewReader(symbol)); status != http.StatusNotFound {
+		t.Fatalf("Expected 404 for a symbol of another block, got %d", status)
+	}
+	if status, body, _ := do(t, http.MethodPut, symbolURL, bytes.NewReader(symbol)); status != http.StatusNoContent {
+		t.Fatalf("Failed to put a symbol (%d): %s", status, body)
+	}
+	if status, body, _ := do(t, http.MethodGet, object, nil); status != http.StatusOK || !bytes.Equal(body, data) {
+		t.Fatalf("Decoding failed after restoring the symbol with %d", status)
+	}
+
+	// Errors
+	if status, _, _ := do(t, http.MethodGet, ts.URL+"/objects/0123456789abcdef0123456789abcdef", nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown object, got %d", status)
+	}
+	if status, _, _ := do(t, http.MethodGet, ts.URL+"/objects/../etc", nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for an invalid object ID, got %d", status)
+	}
+	if status, _, header := do(t, http.MethodDelete, object, nil); status != http.StatusMethodNotAllowed || header.Get("Allow") == "" {
+		t.Errorf("Expected 405 with Allow, got %d", status)
+	}
+
+	_, limited := newTestServer(t, Config{MaxObjectSize: 1000})
+	if status, _, _ := do(t, http.MethodPost, limited.URL+"/objects", bytes.NewReader(data)); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for an oversized upload, got %d", status)
+	}
+	if status, _, _ := do(t, http.MethodPost, limited.URL+"/objects", io.MultiReader(bytes.NewReader(data))); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for an oversized upload of unknown length, got %d", status)
+	}
+}
+
+// storeObject stores data as a new object of srv, encoded without repair
+// symbols in blocks of blockSize bytes, and returns its ID
+func storeObject(t *testing.T, srv *Server, data []byte, blockSize int) string {
+	t.Helper()
+	processor, err := srv.pool.AcquireConfig(context.Background(), srv.cfg.Processor)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer srv.pool.Release(processor)
+	obj, err := processor.EncodeBytes(data, blockSize)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	layout, err := raptorq.ParseLayout(obj.Layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := newObjectID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.createObject(id, obj.Layout); err != nil {
+		t.Fatalf("Failed to create object: %v", err)
+	}
+	store := raptorq.NewDirStore(srv.objectDir(id))
+	for _, block := range layout.Blocks {
+		for _, sid := range block.Symbols {
+			if err := store.Put(context.Background(), block.BlockID, sid, obj.Symbols[sid]); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return id
+}
+
+// cancelRecorder cancels its request once the first body bytes are written
+type cancelRecorder struct {
+	*httptest.ResponseRecorder
+	cancel context.CancelFunc
+}
+
+func (w *cancelRecorder) Write(p []byte) (int, error) {
+	w.cancel()
+	return w.ResponseRecorder.Write(p)
+}
+
+// Unit test for stopping a download when its client goes away
+func TestDecodeClientGone(t *testing.T) {
+	srv, _ := newTestServer(t, Config{Pool: newFakePool(t)})
+	blockSize := 64 * 1024
+	data := make([]byte, 3*blockSize)
+	rand.New(rand.NewSource(5)).Read(data)
+	id := storeObject(t, srv, data, blockSize)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &cancelRecorder{ResponseRecorder: httptest.NewRecorder(), cancel: cancel}
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/objects/"+id, nil).WithContext(ctx))
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), data[:blockSize]) {
+		t.Fatalf("Expected decoding to stop after the first block, got status %d and %d bytes", w.Code, w.Body.Len())
+	}
+}
+
+// Unit test for capping the block size chosen from the Content-Length of an upload
+func TestEncodeMaxBlockSize(t *testing.T) {
+	data := make([]byte, 1024*1024+17)
+	rand.New(rand.NewSource(7)).Read(data)
+
+	tests := []struct {
+		name         string
+		maxBlockSize int
+		blocks       int
+	}{
+		// The fake backend recommends a single block for the whole upload
+		{"default cap", 0, 1},
+		{"configured cap", 64 * 1024, 17},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := New(Config{Dir: t.TempDir(), Pool: newFakePool(t), MaxBlockSize: tt.maxBlockSize})
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+			defer srv.Close()
+
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/objects", bytes.NewReader(data)))
+			if w.Code != http.StatusCreated {
+				t.Fatalf("Encoding failed with %d: %s", w.Code, w.Body)
+			}
+			var res EncodeResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			if len(res.Result.Blocks) != tt.blocks {
+				t.Fatalf("Expected %d blocks, got %d", tt.blocks, len(res.Result.Blocks))
+			}
+			limit := uint64(srv.cfg.MaxBlockSize)
+			for _, block := range res.Result.Blocks {
+				if block.Size > limit {
+					t.Fatalf("Block %d of %d bytes exceeds the cap of %d bytes", block.BlockID, block.Size, limit)
+				}
+			}
+		})
+	}
+
+	if _, err := New(Config{Dir: t.TempDir(), BlockSize: 128 * 1024, MaxBlockSize: 64 * 1024}); !errors.Is(err, raptorq.ErrInvalidParameters) {
+		t.Fatalf("Expected a block size above the cap to be rejected, got: %v", err)
+	}
+}
@@ -0,0 +1,264 @@
+package rq_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SymbolStore stores the symbols of encoded files, addressed by block ID and
+// symbol ID. EncodeToStore writes symbols into a SymbolStore and
+// DecodeFromStore reads them back, so symbols can live anywhere, not only in
+// a local symbols directory.
+//
+// Symbol IDs are the base58 BLAKE3 hashes of the symbol data, as listed in
+// layouts. Implementations must be safe for concurrent use.
+type SymbolStore interface {
+	// Put stores the data of a symbol, replacing any previous content.
+	Put(ctx context.Context, blockID uint64, symbolID string, data []byte) error
+
+	// Get returns the data of a symbol, or an error matching
+	// ErrSymbolNotFound if the store does not hold it.
+	Get(ctx context.Context, blockID uint64, symbolID string) ([]byte, error)
+
+	// List returns the IDs of the symbols stored for a block, sorted. A block
+	// without symbols has an empty list.
+	List(ctx context.Context, blockID uint64) ([]string, error)
+
+	// Delete removes a symbol. Deleting a symbol the store does not hold is
+	// not an error.
+	Delete(ctx context.Context, blockID uint64, symbolID string) error
+}
+
+// checkSymbolID rejects symbol IDs that cannot be used as file or object names.
+func checkSymbolID(symbolID string) error {
+	if !isValidSymbolFileName(symbolID) || strings.HasPrefix(symbolID, ".") {
+		return fmt.Errorf("%w: invalid symbol ID %q", ErrInvalidParameters, symbolID)
+	}
+	return nil
+}
+
+// symbolNotFound returns the error of a missing symbol.
+func symbolNotFound(blockID uint64, symbolID string) error {
+	return fmt.Errorf("block %d: %w: %s", blockID, ErrSymbolNotFound, symbolID)
+}
+
+// DirStore is a SymbolStore over a symbols directory in the layout written by
+// EncodeFile: the symbols of block N are the files of the block_N
+// subdirectory, named by symbol ID. Like the native decoder, reads fall back to
+// the directory itself for blocks without a subdirectory. A DirStore can
+// therefore serve the output of EncodeFile, and DecodeSymbols can decode the
+// content of a DirStore.
+type DirStore struct {
+	// Dir is the symbols directory.
+	Dir string
+}
+
+// NewDirStore returns a DirStore over dir. The directory is created on the
+// first Put.
+//
+// Example:
+//
+//	store := raptorq.NewDirStore("symbols/")
+//	err := processor.DecodeFromStore(ctx, store, "symbols/_raptorq_layout.json", "restored.dat")
+func NewDirStore(dir string) *DirStore {
+	return &DirStore{Dir: dir}
+}
+
+// Put implements SymbolStore.
+func (s *DirStore) Put(ctx context.Context, blockID uint64, symbolID string, data []byte) error {
+	if err := checkSymbolID(symbolID); err != nil {
+		return err
+	}
+	dir := filepath.Join(s.Dir, blockDirName(blockID))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ioError("Put", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, symbolID), data, 0644); err != nil {
+		return ioError("Put", err)
+	}
+	return nil
+}
+
+// Get implements SymbolStore.
+func (s *DirStore) Get(ctx context.Context, blockID uint64, symbolID string) ([]byte, error) {
+	if err := checkSymbolID(symbolID); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(blockSymbolsDir(s.Dir, blockID), symbolID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, symbolNotFound(blockID, symbolID)
+	} else if err != nil {
+		return nil, ioError("Get", err)
+	}
+	return data, nil
+}
+
+// List implements SymbolStore.
+func (s *DirStore) List(ctx context.Context, blockID uint64) ([]string, error) {
+	return listSymbolFiles(blockSymbolsDir(s.Dir, blockID))
+}
+
+// Delete implements SymbolStore.
+func (s *DirStore) Delete(ctx context.Context, blockID uint64, symbolID string) error {
+	if err := checkSymbolID(symbolID); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(blockSymbolsDir(s.Dir, blockID), symbolID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return ioError("Delete", err)
+	}
+	return nil
+}
+
+// listSymbolFiles returns the sorted names of the symbol files in dir,
+// skipping subdirectories, the layout file and hidden temporary files. A
+// missing directory has no symbols.
+func listSymbolFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, ioError("List", err)
+	}
+	ids := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == layoutFileName || strings.HasPrefix(name, ".") {
+			continue
+		}
+		ids = append(ids, name)
+	}
+	return ids, nil
+}
+
+// FlatStore is a content-addressed SymbolStore: every symbol is a file named
+// by its ID directly in Dir, whatever its block. Since symbol IDs are hashes of
+// the symbol data, symbols of different files can share one FlatStore, and
+// identical symbols are stored once.
+//
+// A FlatStore does not record which block a symbol belongs to, so List returns
+// every symbol of the store regardless of blockID; decoding only uses Get with
+// the symbol IDs of the layout.
+type FlatStore struct {
+	// Dir is the directory holding the symbols.
+	Dir string
+}
+
+// NewFlatStore returns a FlatStore over dir. The directory is created on the
+// first Put.
+func NewFlatStore(dir string) *FlatStore {
+	return &FlatStore{Dir: dir}
+}
+
+// Put implements SymbolStore.
+func (s *FlatStore) Put(ctx context.Context, blockID uint64, symbolID string, data []byte) error {
+	if err := checkSymbolID(symbolID); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return ioError("Put", err)
+	}
+	if err := writeFileAtomic(filepath.Join(s.Dir, symbolID), data, 0644); err != nil {
+		return ioError("Put", err)
+	}
+	return nil
+}
+
+// Get implements SymbolStore.
+func (s *FlatStore) Get(ctx context.Context, blockID uint64, symbolID string) ([]byte, error) {
+	if err := checkSymbolID(symbolID); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(s.Dir, symbolID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, symbolNotFound(blockID, symbolID)
+	} else if err != nil {
+		return nil, ioError("Get", err)
+	}
+	return data, nil
+}
+
+// List implements SymbolStore. It returns all symbols of the store.
+func (s *FlatStore) List(ctx context.Context, blockID uint64) ([]string, error) {
+	return listSymbolFiles(s.Dir)
+}
+
+// Delete implements SymbolStore. Since identical symbols are stored once,
+// deleting a symbol removes it for every file sharing it.
+func (s *FlatStore) Delete(ctx context.Context, blockID uint64, symbolID string) error {
+	if err := checkSymbolID(symbolID); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(s.Dir, symbolID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return ioError("Delete", err)
+	}
+	return nil
+}
+
+// MemoryStore is a SymbolStore holding symbols in memory, for tests and for
+// small objects that do not need to touch the disk.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	blocks map[uint64]map[string][]byte
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{blocks: make(map[uint64]map[string][]byte)}
+}
+
+// Put implements SymbolStore. The data is copied.
+func (s *MemoryStore) Put(ctx context.Context, blockID uint64, symbolID string, data []byte) error {
+	if err := checkSymbolID(symbolID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	block, ok := s.blocks[blockID]
+	if !ok {
+		block = make(map[string][]byte)
+		s.blocks[blockID] = block
+	}
+	block[symbolID] = append([]byte(nil), data...)
+	return nil
+}
+
+// Get implements SymbolStore. The returned data is a copy.
+func (s *MemoryStore) Get(ctx context.Context, blockID uint64, symbolID string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.blocks[blockID][symbolID]
+	if !ok {
+		return nil, symbolNotFound(blockID, symbolID)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// List implements SymbolStore.
+func (s *MemoryStore) List(ctx context.Context, blockID uint64) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.blocks[blockID]))
+	for id := range s.blocks[blockID] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Delete implements SymbolStore.
+func (s *MemoryStore) Delete(ctx context.Context, blockID uint64, symbolID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.blocks[blockID], symbolID)
+	if len(s.blocks[blockID]) == 0 {
+		delete(s.blocks, blockID)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package rq_go
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// failingStore wraps a SymbolStore and fails every Put after the first n
+type failingStore struct {
+	SymbolStore
+	n    int32
+	puts atomic.Int32
+}
+
+func (s *failingStore) Put(ctx context.Context, blockID uint64, symbolID string, data []byte) error {
+	if s.puts.Add(1) > s.n {
+		return errors.New("store is full")
+	}
+	return s.SymbolStore.Put(ctx, blockID, symbolID, data)
+}
+
+// Unit test for the Put, Get, List and Delete operations of every store
+func TestSymbolStores(t *testing.T) {
+	ctx := context.Background()
+	a, b := hashBytes([]byte("a")), hashBytes([]byte("b"))
+
+	stores := map[string]SymbolStore{
+		"dir":    NewDirStore(filepath.Join(t.TempDir(), "symbols")),
+		"flat":   NewFlatStore(filepath.Join(t.TempDir(), "symbols")),
+		"memory": NewMemoryStore(),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if ids, err := store.List(ctx, 0); err != nil || len(ids) != 0 {
+				t.Fatalf("Expected an empty store, got %v, %v", ids, err)
+			}
+			if _, err := store.Get(ctx, 0, a); !errors.Is(err, ErrSymbolNotFound) || !errors.Is(err, ErrFileNotFound) {
+				t.Fatalf("Expected ErrSymbolNotFound, got: %v", err)
+			}
+
+			for _, put := range []struct {
+				block uint64
+				id    string
+				data  string
+			}{{0, b, "b"}, {0, a, "old"}, {0, a, "a"}, {1, b, "b"}} {
+				if err := store.Put(ctx, put.block, put.id, []byte(put.data)); err != nil {
+					t.Fatalf("Failed to put %s: %v", put.id, err)
+				}
+			}
+			if data, err := store.Get(ctx, 0, a); err != nil || string(data) != "a" {
+				t.Fatalf("Expected the replaced content, got %q, %v", data, err)
+			}
+			if ids, err := store.List(ctx, 0); err != nil || !reflect.DeepEqual(ids, sortedIDs(a, b)) {
+				t.Fatalf("Unexpected symbols of block 0: %v, %v", ids, err)
+			}
+
+			if err := store.Delete(ctx, 0, a); err != nil {
+				t.Fatalf("Failed to delete: %v", err)
+			}
+			if err := store.Delete(ctx, 0, a); err != nil {
+				t.Fatalf("Deleting a missing symbol should succeed, got: %v", err)
+			}
+			if _, err := store.Get(ctx, 0, a); !errors.Is(err, ErrSymbolNotFound) {
+				t.Fatalf("Expected ErrSymbolNotFound after delete, got: %v", err)
+			}
+			if data, err := store.Get(ctx, 1, b); err != nil || string(data) != "b" {
+				t.Fatalf("Failed to get the symbol of block 1: %q, %v", data, err)
+			}
+			if err := store.Put(ctx, 0, "../escape", nil); !errors.Is(err, ErrInvalidParameters) {
+				t.Fatalf("Expected an invalid symbol ID to be rejected, got: %v", err)
+			}
+		})
+	}
+
+	// A DirStore uses the block directories of EncodeFile, a FlatStore a single directory
+	dir := stores["dir"].(*DirStore).Dir
+	if _, err := os.Stat(filepath.Join(dir, blockDirName(1), b)); err != nil {
+		t.Fatalf("Expected the symbol in the block directory: %v", err)
+	}
+	flat := stores["flat"].(*FlatStore).Dir
+	if _, err := os.Stat(filepath.Join(flat, b)); err != nil {
+		t.Fatalf("Expected the symbol in the flat directory: %v", err)
+	}
+}
+
+// sortedIDs returns the two IDs in order
+func sortedIDs(a, b string) []string {
+	if a < b {
+		return []string{a, b}
+	}
+	return []string{b, a}
+}
+
+// System test encoding into and decoding from symbol stores (3MB, 1MB blocks)
+func TestSysStoreEncodeDecode(t *testing.T) {
+	processor, err := NewDefaultRaptorQProcessor()
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	ctx := NewTestContext(t, 3*1024*1024+100)
+	defer ctx.Cleanup()
+	bg := context.Background()
+	layoutPath := filepath.Join(ctx.TempDir, "layout.json")
+
+	store := NewMemoryStore()
+	res, err := processor.EncodeToStore(bg, ctx.InputFile, layoutPath, store, 1024*1024)
+	if err != nil {
+		t.Fatalf("Failed to encode to store: %v", err)
+	}
+	if len(res.Blocks) != 4 || res.LayoutFilePath != layoutPath || res.SymbolsDirectory != "" {
+		t.Fatalf("Unexpected result: %+v", res)
+	}
+	layout, err := LoadLayout(layoutPath)
+	if err != nil {
+		t.Fatalf("Failed to load layout: %v", err)
+	}
+	stored := 0
+	for _, block := range layout.Blocks {
+		ids, err := store.List(bg, block.BlockID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored += len(ids)
+	}
+	if stored != int(res.TotalSymbolsCount) {
+		t.Fatalf("Expected %d stored symbols, got %d", res.TotalSymbolsCount, stored)
+	}
+
+	if err := processor.DecodeFromStore(bg, store, layoutPath, ctx.OutputFile); err != nil {
+		t.Fatalf("Failed to decode from store: %v", err)
+	}
+	if !ctx.VerifyFilesMatch(t) {
+		t.Fatal("Decoded file does not match the original")
+	}
+
+	// A DirStore is interchangeable with a symbols directory
+	if err := processor.DecodeFromStore(bg, NewDirStore(ctx.SymbolsDir), layoutPath, ctx.OutputFile); !errors.Is(err, ErrInsufficientSymbols) {
+		t.Fatalf("Expected ErrInsufficientSymbols from an empty store, got: %v", err)
+	}
+	if _, err := processor.EncodeToStore(bg, ctx.InputFile, layoutPath, NewDirStore(ctx.SymbolsDir), 1024*1024); err != nil {
+		t.Fatalf("Failed to encode to directory store: %v", err)
+	}
+	os.Remove(ctx.OutputFile)
+	if err := processor.DecodeSymbols(ctx.SymbolsDir, ctx.OutputFile, layoutPath); err != nil {
+		t.Fatalf("Failed to decode the directory store: %v", err)
+	}
+	if !ctx.VerifyFilesMatch(t) {
+		t.Fatal("Decoded file does not match the original")
+	}
+
+	// A failing store leaves nothing behind
+	target := NewMemoryStore()
+	failing := &failingStore{SymbolStore: target, n: 20}
+	if _, err := processor.EncodeToStore(bg, ctx.InputFile, filepath.Join(ctx.TempDir, "failed.json"), failing, 1024*1024); err == nil {
+		t.Fatal("Expected encoding into a failing store to fail")
+	}
+	for _, block := range layout.Blocks {
+		if ids, _ := target.List(bg, block.BlockID); len(ids) != 0 {
+			t.Fatalf("Expected stored symbols to be deleted, block %d has %d", block.BlockID, len(ids))
+		}
+	}
+
+	// Missing and corrupt symbols are replaced by further symbols of the block
+	skipWithoutRepairSymbols(t)
+	block := layout.Blocks[1]
+	store.Delete(bg, block.BlockID, block.Symbols[0])
+	store.Put(bg, block.BlockID, block.Symbols[1], []byte("corrupt"))
+	os.Remove(ctx.OutputFile)
+	if err := processor.DecodeFromStore(bg, store, layoutPath, ctx.OutputFile); err != nil {
+		t.Fatalf("Failed to decode from damaged store: %v", err)
+	}
+	if !ctx.VerifyFilesMatch(t) {
+		t.Fatal("Decoded file does not match the original")
+	}
+}
@@ -0,0 +1,453 @@
+package rq_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DefaultFetchMargin is the default number of symbols DecodeFromStore fetches
+// per block beyond the source symbols. RaptorQ fails to decode from K symbols
+// with a probability of about 1%, and each additional symbol lowers it about a
+// hundredfold.
+const DefaultFetchMargin = 2
+
+// DefaultFetchParallelism is the default number of concurrent store requests
+// of EncodeToStore and DecodeFromStore.
+const DefaultFetchParallelism = 4
+
+// FetchOptions controls how EncodeToStore and DecodeFromStore access a
+// SymbolStore. Set them for an operation with WithFetchOptions.
+type FetchOptions struct {
+	// Margin is the number of symbols fetched per block beyond the source
+	// symbols before the first decoding attempt. Negative values are treated
+	// as 0.
+	Margin int
+
+	// Parallelism is the maximum number of concurrent Put or Get calls. Values
+	// below 1 are treated as 1.
+	Parallelism int
+}
+
+// DefaultFetchOptions returns the FetchOptions used when none are set with
+// WithFetchOptions.
+func DefaultFetchOptions() FetchOptions {
+	return FetchOptions{Margin: DefaultFetchMargin, Parallelism: DefaultFetchParallelism}
+}
+
+// fetchOptionsKey is the context key of the FetchOptions set with
+// WithFetchOptions.
+type fetchOptionsKey struct{}
+
+// WithFetchOptions returns a copy of ctx that makes EncodeToStore and
+// DecodeFromStore access their store as set in opts.
+//
+// Example:
+//
+//	// Fetch 16 symbols at a time and 5 extra symbols per block
+//	ctx = raptorq.WithFetchOptions(ctx, raptorq.FetchOptions{Margin: 5, Parallelism: 16})
+//	err := processor.DecodeFromStore(ctx, store, "input.layout.json", "restored.dat")
+func WithFetchOptions(ctx context.Context, opts FetchOptions) context.Context {
+	return context.WithValue(ctx, fetchOptionsKey{}, opts)
+}
+
+// fetchOptions returns the FetchOptions of ctx, normalized.
+func fetchOptions(ctx context.Context) FetchOptions {
+	opts, ok := ctx.Value(fetchOptionsKey{}).(FetchOptions)
+	if !ok {
+		return DefaultFetchOptions()
+	}
+	opts.Margin = max(opts.Margin, 0)
+	opts.Parallelism = max(opts.Parallelism, 1)
+	return opts
+}
+
+// parallel calls fn for every i in [0, n) from up to workers goroutines and
+// returns the first error. Once fn fails or ctx ends, no further calls are
+// started.
+func parallel(ctx context.Context, n, workers int, fn func(i int) error) error {
+	var (
+		mu       sync.Mutex
+		next     int
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = ctx.Err()
+				}
+				if firstErr != nil || next >= n {
+					mu.Unlock()
+					return
+				}
+				i := next
+				next++
+				mu.Unlock()
+
+				if err := fn(i); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// storedSymbol identifies a symbol written to a SymbolStore.
+type storedSymbol struct {
+	blockID  uint64
+	symbolID string
+}
+
+// EncodeToStore encodes a file like EncodeFileContext, but writes the symbols
+// into store instead of a symbols directory.
+//
+// The file is encoded block by block in a private temporary directory, and the
+// symbols of each block are written to the store as soon as the block is
+// encoded, so at most one block of symbols is kept on local disk. Symbols are
+// written concurrently as set with WithFetchOptions. The layout is
+// written to layoutPath; it is needed to decode the symbols with
+// DecodeFromStore. If encoding fails or ctx ends, the symbols already written
+// to the store are deleted again.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation of the operation, also passed to the store.
+//   - inputPath: Path to the input file to be encoded.
+//   - layoutPath: Path where the layout file will be written.
+//   - store: The store receiving the symbols.
+//   - blockSize: Size of each block in bytes. If 0, a recommended block size will be used.
+//
+// Returns:
+//   - *ProcessResult: Information about the encoding, as returned by EncodeFile.
+//     SymbolsDirectory is empty.
+//   - error: The errors of EncodeFileContext, or the error of the store.
+//
+// Example:
+//
+//	store := raptorq.NewFlatStore("/var/lib/symbols")
+//	result, err := processor.EncodeToStore(ctx, "input.dat", "input.layout.json", store, 0)
+//	if err != nil {
+//	    return err
+//	}
+func (p *RaptorQProcessor) EncodeToStore(ctx context.Context, inputPath, layoutPath string, store SymbolStore, blockSize int) (*ProcessResult, error) {
+	if store == nil || layoutPath == "" {
+		return nil, fmt.Errorf("%w: store and layoutPath cannot be empty", ErrInvalidParameters)
+	}
+
+	stageDir, err := os.MkdirTemp("", "raptorq-store-")
+	if err != nil {
+		return nil, ioError("EncodeFile", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	symbolsDir := filepath.Join(stageDir, "symbols")
+	if err := os.MkdirAll(symbolsDir, 0755); err != nil {
+		return nil, ioError("EncodeFile", err)
+	}
+
+	opts := fetchOptions(ctx)
+	var (
+		mu     sync.Mutex
+		stored []storedSymbol
+	)
+	sink := func(blockID uint64, dir string) error {
+		ids, err := listSymbolFiles(dir)
+		if err != nil {
+			return err
+		}
+		return parallel(ctx, len(ids), opts.Parallelism, func(i int) error {
+			path := filepath.Join(dir, ids[i])
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return ioError("EncodeFile", err)
+			}
+			if err := store.Put(ctx, blockID, ids[i], data); err != nil {
+				return fmt.Errorf("failed to store symbol %s: %w", ids[i], err)
+			}
+			mu.Lock()
+			stored = append(stored, storedSymbol{blockID, ids[i]})
+			mu.Unlock()
+			if err := os.Remove(path); err != nil {
+				return ioError("EncodeFile", err)
+			}
+			return nil
+		})
+	}
+
+	stagedLayout := filepath.Join(symbolsDir, layoutFileName)
+	result, err := p.encodeBlocks(ctx, "EncodeFile", inputPath, symbolsDir, stagedLayout, blockSize, sink)
+	if err == nil {
+		err = copyLayout(stagedLayout, layoutPath)
+	}
+	if err != nil {
+		deleteStored(context.WithoutCancel(ctx), store, stored)
+		return nil, err
+	}
+
+	result.SymbolsDirectory = ""
+	result.LayoutFilePath = layoutPath
+	return result, nil
+}
+
+// copyLayout copies the layout file at src to dst.
+func copyLayout(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return ioError("EncodeFile", err)
+	}
+	if dir := filepath.Dir(dst); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return ioError("EncodeFile", err)
+		}
+	}
+	if err := writeFileAtomic(dst, data, 0644); err != nil {
+		return ioError("EncodeFile", err)
+	}
+	return nil
+}
+
+// deleteStored removes symbols written by an abandoned EncodeToStore,
+// ignoring errors.
+func deleteStored(ctx context.Context, store SymbolStore, stored []storedSymbol) {
+	for _, s := range stored {
+		store.Delete(ctx, s.blockID, s.symbolID)
+	}
+}
+
+// DecodeFromStore reconstructs the file described by the layout at layoutPath
+// from the symbols in store, like DecodeSymbolsContext does from a symbols
+// directory.
+//
+// Blocks are decoded one at a time. For each block, symbols are fetched in
+// layout order until the block has its source symbols plus a small safety
+// margin; symbols that are missing from the store or whose content does not
+// match their ID are skipped. The margin and the number of concurrent fetches
+// can be set with WithFetchOptions. Only if decoding fails with that many symbols are
+// the remaining symbols of the block fetched. The output file is only created
+// once every block has been decoded.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation of the operation, also passed to the store.
+//   - store: The store holding the symbols.
+//   - layoutPath: Path to the layout file written by EncodeToStore or EncodeFile.
+//   - outputPath: Path where the decoded file will be written.
+//
+// Returns:
+//   - error: An error matching ErrInsufficientSymbols if the store does not hold
+//     enough valid symbols of a block, the errors of DecodeSymbols, the error of
+//     the store, or ctx.Err().
+//
+// Example:
+//
+//	store := raptorq.NewFlatStore("/var/lib/symbols")
+//	if err := processor.DecodeFromStore(ctx, store, "input.layout.json", "restored.dat"); err != nil {
+//	    return err
+//	}
+func (p *RaptorQProcessor) DecodeFromStore(ctx context.Context, store SymbolStore, layoutPath, outputPath string) error {
+	if p.SessionID == 0 {
+		return ErrSessionClosed
+	}
+	if store == nil || layoutPath == "" || outputPath == "" {
+		return fmt.Errorf("%w: store, layoutPath and outputPath cannot be empty", ErrInvalidParameters)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	doc, err := LoadLayout(layoutPath)
+	if err != nil {
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			return ioError("DecodeSymbols", err)
+		}
+		return err
+	}
+	if err := p.CheckLayout(doc); err != nil {
+		return err
+	}
+
+	ctx, trace := p.startOperation(ctx, "DecodeFromStore", layoutAttributes(doc)...)
+	progress := p.startProgress(ctx, "DecodeSymbols", len(doc.Blocks), doc.TotalSize())
+	err = p.decodeFromStore(ctx, store, doc, outputPath, progress, trace)
+	progress.finish(err)
+	trace.end(err)
+	return err
+}
+
+// decodeFromStore decodes the blocks of doc for DecodeFromStore, reporting
+// each of them to progress and tracing each of them as a child of trace.
+func (p *RaptorQProcessor) decodeFromStore(ctx context.Context, store SymbolStore, doc *Layout, outputPath string,
+	progress *progressReporter, trace *traceSpan) error {
+	stageDir, err := os.MkdirTemp(filepath.Dir(outputPath), ".rq-staging-")
+	if err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	partialPath := filepath.Join(stageDir, "output.bin")
+	out, err := os.Create(partialPath)
+	if err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	defer out.Close()
+
+	opts := fetchOptions(ctx)
+	for _, block := range doc.Blocks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		progress.blockStarted(block.BlockID)
+		blockCtx := trace.startBlock(ctx, block.BlockID, block.Size)
+		if err := p.decodeStoreBlock(blockCtx, store, opts, stageDir, block, out); err != nil {
+			return err
+		}
+		trace.finishBlock()
+		progress.blockFinished(block.BlockID, block.Size, 0)
+	}
+
+	if err := out.Close(); err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	if err := os.Rename(partialPath, outputPath); err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	return nil
+}
+
+// decodeStoreBlock fetches the symbols of a block from store into the staging
+// directory and decodes the block into out at its offset.
+func (p *RaptorQProcessor) decodeStoreBlock(ctx context.Context, store SymbolStore, opts FetchOptions, stageDir string, block BlockLayout, out io.WriterAt) error {
+	oti, err := block.OTI()
+	if err != nil {
+		return fmt.Errorf("block %d: %w", block.BlockID, err)
+	}
+
+	symbolsDir := filepath.Join(stageDir, "symbols")
+	blockDir := filepath.Join(symbolsDir, blockDirName(block.BlockID))
+	if err := os.MkdirAll(blockDir, 0755); err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	defer os.RemoveAll(blockDir)
+
+	fetcher := &symbolFetcher{
+		store:       store,
+		block:       block,
+		dir:         blockDir,
+		parallelism: opts.Parallelism,
+		seen:        make(map[string]bool, len(block.Symbols)),
+	}
+	source := oti.SourceSymbols()
+	if err := fetcher.fetch(ctx, source+opts.Margin); err != nil {
+		return err
+	}
+
+	for {
+		if fetcher.fetched < source && fetcher.done() {
+			return fmt.Errorf("block %d: %w: %d of %d required symbols are available",
+				block.BlockID, ErrInsufficientSymbols, fetcher.fetched, source)
+		}
+		if fetcher.fetched >= source {
+			err = p.decodeStagedBlock(ctx, stageDir, symbolsDir, block, out)
+			if err == nil || !errors.Is(err, ErrDecodingFailed) || fetcher.done() {
+				return err
+			}
+		}
+		// Decoding needs more symbols than fetched so far: fetch the rest.
+		if err := fetcher.fetch(ctx, len(block.Symbols)); err != nil {
+			return err
+		}
+	}
+}
+
+// symbolFetcher fetches the symbols of a block from a store into a directory,
+// in layout order.
+type symbolFetcher struct {
+	store       SymbolStore
+	block       BlockLayout
+	dir         string
+	parallelism int
+
+	// next is the index in block.Symbols of the next symbol to fetch.
+	next int
+
+	// seen holds the symbols already tried, as layouts may list a symbol twice.
+	seen map[string]bool
+
+	// fetched is the number of valid symbols written to dir.
+	fetched int
+}
+
+// done reports whether every symbol of the block has been tried.
+func (f *symbolFetcher) done() bool {
+	return f.next >= len(f.block.Symbols)
+}
+
+// fetch fetches symbols until limit valid symbols have been written or every
+// symbol has been tried. Missing and corrupt symbols are skipped.
+//
+// Symbols are fetched in batches of as many symbols as are still needed, so
+// no more symbols are requested than necessary unless some turn out to be
+// unusable.
+func (f *symbolFetcher) fetch(ctx context.Context, limit int) error {
+	for !f.done() && f.fetched < limit {
+		var batch []string
+		for !f.done() && len(batch) < limit-f.fetched {
+			id := f.block.Symbols[f.next]
+			f.next++
+			if !isValidSymbolFileName(id) {
+				return fmt.Errorf("%w: invalid symbol ID %q in block %d", ErrInvalidParameters, id, f.block.BlockID)
+			}
+			if !f.seen[id] {
+				f.seen[id] = true
+				batch = append(batch, id)
+			}
+		}
+
+		var mu sync.Mutex
+		err := parallel(ctx, len(batch), f.parallelism, func(i int) error {
+			ok, err := f.fetchSymbol(ctx, batch[i])
+			if ok {
+				mu.Lock()
+				f.fetched++
+				mu.Unlock()
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchSymbol fetches one symbol into dir and reports whether it was valid.
+func (f *symbolFetcher) fetchSymbol(ctx context.Context, id string) (bool, error) {
+	data, err := f.store.Get(ctx, f.block.BlockID, id)
+	if errors.Is(err, ErrSymbolNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("block %d: failed to fetch symbol %s: %w", f.block.BlockID, id, err)
+	}
+	if hashBytes(data) != id {
+		return false, nil
+	}
+	if err := os.WriteFile(filepath.Join(f.dir, id), data, 0644); err != nil {
+		return false, ioError("DecodeSymbols", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,423 @@
+package rq_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// DefaultStreamBlockSize is the block size used by an Encoder when neither the
+// total length of the input nor an explicit block size is known.
+const DefaultStreamBlockSize = 16 * 1024 * 1024 // 16MB
+
+// EncodedBlock is a single block emitted by an Encoder.
+type EncodedBlock struct {
+	// Block describes the block, with BlockID and OriginalOffset relative to the
+	// whole stream.
+	Block Block
+
+	// Symbols maps each symbol ID of the block to the symbol data.
+	Symbols map[string][]byte
+}
+
+// Encoder encodes data consumed from an io.Reader one block at a time, so
+// inputs such as HTTP request bodies never have to be spooled to disk as a
+// whole. Create it with RaptorQProcessor.NewEncoder.
+type Encoder struct {
+	p         *RaptorQProcessor
+	size      int64
+	blockSize int
+}
+
+// NewEncoder creates a streaming encoder for an input of size bytes.
+//
+// When size is known (size >= 0) and blockSize is 0, the block size is chosen
+// with GetRecommendedBlockSize, so the output matches EncodeFile for a file of
+// the same content. When size is unknown (size < 0), blockSize is used, or
+// DefaultStreamBlockSize if blockSize is 0.
+//
+// Parameters:
+//   - size: Total length of the input in bytes, or -1 if unknown.
+//   - blockSize: Size of each block in bytes, or 0 to choose automatically.
+//
+// Returns:
+//   - *Encoder: A new streaming encoder bound to the processor.
+func (p *RaptorQProcessor) NewEncoder(size int64, blockSize int) *Encoder {
+	return &Encoder{p: p, size: size, blockSize: blockSize}
+}
+
+// BlockSize returns the block size the encoder cuts its input into.
+func (e *Encoder) BlockSize() int {
+	if e.blockSize > 0 {
+		return e.blockSize
+	}
+	if e.size >= 0 {
+		if recommended := e.p.GetRecommendedBlockSize(uint64(e.size)); recommended > 0 {
+			return recommended
+		}
+		if e.size > 0 {
+			// The whole input fits in a single block.
+			return int(e.size)
+		}
+	}
+	return DefaultStreamBlockSize
+}
+
+// Encode reads r until EOF (or until size bytes when the size is known), encodes
+// every block as soon as it has been read and passes it to emit before reading
+// the next one. Only one block is held in memory at a time.
+//
+// ctx is checked before every block. If emit returns an error, encoding stops
+// and that error is returned.
+//
+// Parameters:
+//   - ctx: Context controlling cancellation of the operation.
+//   - r: The input to encode.
+//   - emit: Callback receiving each encoded block in order. It may be nil.
+//
+// Returns:
+//   - *EncodedObject: The merged result and layout of all blocks. Symbols is nil
+//     because they were handed to emit.
+//   - error: An error if reading, encoding or emit fails.
+//
+// Example:
+//
+//	enc := processor.NewEncoder(req.ContentLength, 0)
+//	obj, err := enc.Encode(ctx, req.Body, func(b *raptorq.EncodedBlock) error {
+//	    return store.PutBlock(b.Block.BlockID, b.Symbols)
+//	})
+func (e *Encoder) Encode(ctx context.Context, r io.Reader, emit func(*EncodedBlock) error) (*EncodedObject, error) {
+	if e.p.SessionID == 0 {
+		return nil, ErrSessionClosed
+	}
+	if e.size >= 0 {
+		r = io.LimitReader(r, e.size)
+	}
+
+	buf := make([]byte, e.BlockSize())
+	result := &ProcessResult{}
+	doc := &Layout{}
+
+	var offset uint64
+	for blockID := uint64(0); ; blockID++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF {
+			break
+		}
+		last := err == io.ErrUnexpectedEOF
+		if err != nil && !last {
+			return nil, ioError("EncodeFile", err)
+		}
+
+		obj, err := e.p.EncodeBytes(buf[:n], n)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", blockID, err)
+		}
+
+		blockDoc, err := ParseLayout(obj.Layout)
+		if err != nil {
+			return nil, fmt.Errorf("EncodeFile: block %d: %w: %v", blockID, ErrInvalidResponse, err)
+		}
+		if len(blockDoc.Blocks) != 1 || len(obj.Result.Blocks) != 1 {
+			return nil, fmt.Errorf("EncodeFile: block %d: %w: expected a single block, got %d",
+				blockID, ErrInvalidResponse, len(blockDoc.Blocks))
+		}
+
+		layoutEntry := blockDoc.Blocks[0]
+		layoutEntry.BlockID = blockID
+		layoutEntry.OriginalOffset = offset
+		doc.Blocks = append(doc.Blocks, layoutEntry)
+
+		block := obj.Result.Blocks[0]
+		block.BlockID = blockID
+		block.OriginalOffset = offset
+		result.Blocks = append(result.Blocks, block)
+		result.TotalSymbolsCount += obj.Result.TotalSymbolsCount
+		result.TotalRepairSymbols += obj.Result.TotalRepairSymbols
+
+		if emit != nil {
+			if err := emit(&EncodedBlock{Block: block, Symbols: obj.Symbols}); err != nil {
+				return nil, err
+			}
+		}
+
+		offset += uint64(n)
+		if last {
+			break
+		}
+	}
+
+	if e.size >= 0 && offset != uint64(e.size) {
+		return nil, fmt.Errorf("EncodeFile: expected %d bytes, read %d: %w", e.size, offset, io.ErrUnexpectedEOF)
+	}
+	if len(doc.Blocks) == 0 {
+		return nil, fmt.Errorf("%w: input is empty", ErrInvalidParameters)
+	}
+
+	layout, err := doc.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize layout: %w", err)
+	}
+	return &EncodedObject{Result: result, Layout: layout}, nil
+}
+
+// Decoder reconstructs data from symbols that arrive incrementally and writes
+// the recovered blocks to an io.Writer in offset order as soon as they become
+// decodable. Create it with RaptorQProcessor.NewDecoder and always call Close.
+//
+// A Decoder is safe for concurrent use by multiple goroutines.
+type Decoder struct {
+	p        *RaptorQProcessor
+	w        io.Writer
+	stageDir string
+
+	mu       sync.Mutex
+	blocks   []*streamBlock
+	bySymbol map[string][]*streamBlock
+	next     int
+	err      error
+}
+
+// streamBlock tracks the symbols received for one block of a Decoder.
+//
+// A block is first decoded once it has received as many symbols as it has
+// source symbols (K, from its OTI). If that fails, the next attempt waits for
+// step more symbols, and step doubles after every failed attempt, so a block
+// missing many symbols is not decoded again for every symbol that arrives.
+type streamBlock struct {
+	layout   BlockLayout
+	needed   int
+	step     int
+	tried    int
+	received map[string]struct{}
+	decoded  bool
+}
+
+// NewDecoder creates a streaming decoder for the given layout (the content of a
+// _raptorq_layout.json file) that writes the recovered data to w.
+//
+// Received symbols are staged in a private temporary directory, which is
+// removed by Close.
+//
+// Parameters:
+//   - layout: The layout describing the encoded blocks.
+//   - w: Destination of the recovered data, written in offset order.
+//
+// Returns:
+//   - *Decoder: A new streaming decoder bound to the processor.
+//   - error: An error matching ErrInvalidLayout if the layout cannot be parsed
+//     or does not match the processor (see CheckLayout).
+func (p *RaptorQProcessor) NewDecoder(layout []byte, w io.Writer) (*Decoder, error) {
+	doc, err := ParseLayout(layout)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckLayout(doc); err != nil {
+		return nil, err
+	}
+
+	d := &Decoder{
+		p:        p,
+		w:        w,
+		bySymbol: make(map[string][]*streamBlock),
+	}
+
+	for _, entry := range doc.Blocks {
+		oti, _ := entry.OTI()
+
+		block := &streamBlock{
+			layout:   entry,
+			needed:   oti.SourceSymbols(),
+			step:     1,
+			received: make(map[string]struct{}),
+		}
+		d.blocks = append(d.blocks, block)
+		for _, id := range entry.Symbols {
+			d.bySymbol[id] = append(d.bySymbol[id], block)
+		}
+	}
+	sort.Slice(d.blocks, func(i, j int) bool {
+		return d.blocks[i].layout.OriginalOffset < d.blocks[j].layout.OriginalOffset
+	})
+
+	stageDir, err := os.MkdirTemp("", "raptorq-stream-")
+	if err != nil {
+		return nil, ioError("DecodeSymbols", err)
+	}
+	d.stageDir = stageDir
+
+	return d, nil
+}
+
+// AddSymbol hands a received symbol to the decoder.
+//
+// Once a block has received as many symbols as it has source symbols, it is
+// decoded. A decoding failure usually means that more symbols are needed, so
+// further attempts are made as more symbols arrive, in growing batches: after
+// 1, 2, 4, ... further symbols. Recovered blocks are written to the writer as
+// soon as all blocks before them have been written. Symbols of blocks that are
+// already recovered are ignored.
+//
+// Returns:
+//   - error: ErrInvalidParameters if the symbol is not listed in the layout, or
+//     the first error other than a decoding failure, such as a hash mismatch,
+//     an I/O error or a write error. Errors other than unknown symbols are
+//     sticky and returned by every later call.
+func (d *Decoder) AddSymbol(id string, data []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err != nil {
+		return d.err
+	}
+
+	blocks, ok := d.bySymbol[id]
+	if !ok || !isValidSymbolFileName(id) {
+		return fmt.Errorf("%w: symbol %s is not listed in the layout", ErrInvalidParameters, id)
+	}
+
+	for _, block := range blocks {
+		if block.decoded {
+			continue
+		}
+		if _, seen := block.received[id]; seen {
+			continue
+		}
+		if err := d.addToBlock(block, id, data); err != nil {
+			d.err = err
+			return err
+		}
+	}
+
+	if err := d.flush(); err != nil {
+		d.err = err
+		return err
+	}
+	return nil
+}
+
+// Done reports whether every block has been recovered and written.
+func (d *Decoder) Done() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.next == len(d.blocks)
+}
+
+// Close makes a last decoding attempt for the blocks that received symbols
+// since their last attempt, writes the blocks it recovers, and releases the
+// staging directory of the decoder.
+//
+// Returns:
+//   - error: The sticky decoding error if one occurred, an error wrapping
+//     ErrInsufficientSymbols if some blocks could not be recovered, or nil.
+func (d *Decoder) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err == nil {
+		d.err = d.finish()
+	}
+	os.RemoveAll(d.stageDir)
+
+	if d.err != nil {
+		return d.err
+	}
+	if d.next < len(d.blocks) {
+		return fmt.Errorf("%w: recovered %d of %d blocks", ErrInsufficientSymbols, d.next, len(d.blocks))
+	}
+	return nil
+}
+
+// addToBlock stages a symbol for a block and attempts to decode the block once
+// enough symbols are available.
+func (d *Decoder) addToBlock(block *streamBlock, id string, data []byte) error {
+	symbolsDir := filepath.Join(d.stageDir, "symbols")
+	blockDir := filepath.Join(symbolsDir, blockDirName(block.layout.BlockID))
+	if err := os.MkdirAll(blockDir, 0755); err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	if err := os.WriteFile(filepath.Join(blockDir, id), data, 0644); err != nil {
+		return ioError("DecodeSymbols", err)
+	}
+	block.received[id] = struct{}{}
+
+	if len(block.received) < block.needed {
+		return nil
+	}
+	return d.decodeBlock(block)
+}
+
+// decodeBlock attempts to decode a block from the symbols it has received.
+//
+// The native library reports a block that cannot be decoded yet as a plain
+// decoding failure, so any decoding failure other than a hash mismatch is
+// taken as a need for more symbols: the next attempt is scheduled step symbols
+// later and step doubles. Other errors are returned.
+func (d *Decoder) decodeBlock(block *streamBlock) error {
+	symbolsDir := filepath.Join(d.stageDir, "symbols")
+	block.tried = len(block.received)
+
+	err := d.p.decodeBlockFile(context.Background(), d.stageDir, symbolsDir, block.layout, d.decodedPath(block))
+	if errors.Is(err, ErrDecodingFailed) && !errors.Is(err, ErrHashMismatch) {
+		block.needed = len(block.received) + block.step
+		block.step *= 2
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	block.decoded = true
+	os.RemoveAll(filepath.Join(symbolsDir, blockDirName(block.layout.BlockID)))
+	return nil
+}
+
+// finish attempts once more to decode every block that received symbols since
+// its last attempt, and writes the blocks that become available.
+func (d *Decoder) finish() error {
+	for _, block := range d.blocks {
+		if block.decoded || block.tried == 0 || len(block.received) == block.tried {
+			continue
+		}
+		if err := d.decodeBlock(block); err != nil {
+			return err
+		}
+	}
+	return d.flush()
+}
+
+// flush writes every recovered block that directly follows the data already
+// written.
+func (d *Decoder) flush() error {
+	for d.next < len(d.blocks) && d.blocks[d.next].decoded {
+		path := d.decodedPath(d.blocks[d.next])
+
+		f, err := os.Open(path)
+		if err != nil {
+			return ioError("DecodeSymbols", err)
+		}
+		_, err = io.Copy(d.w, f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("DecodeSymbols: failed to write block %d: %w", d.blocks[d.next].layout.BlockID, err)
+		}
+
+		os.Remove(path)
+		d.next++
+	}
+	return nil
+}
+
+// decodedPath returns the staging path of the recovered data of a block.
+func (d *Decoder) decodedPath(block *streamBlock) string {
+	return filepath.Join(d.stageDir, fmt.Sprintf("decoded_%d.bin", block.layout.BlockID))
+}
@@ -0,0 +1,195 @@
+package rq_go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// System test for streaming encoding and incremental decoding (3MB + 100 bytes, 1MB blocks)
+func TestSysStreamEncodeDecode(t *testing.T) {
+	processor, err := NewDefaultRaptorQProcessor()
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	data := make([]byte, 3*1024*1024+100)
+	rand.New(rand.NewSource(42)).Read(data)
+
+	// Unknown length: the configured block size is used
+	enc := processor.NewEncoder(-1, 1024*1024)
+	symbols := make(map[string][]byte)
+	var blockIDs []uint64
+	obj, err := enc.Encode(context.Background(), bytes.NewReader(data), func(b *EncodedBlock) error {
+		blockIDs = append(blockIDs, b.Block.BlockID)
+		for id, symbol := range b.Symbols {
+			symbols[id] = symbol
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode stream: %v", err)
+	}
+	if len(blockIDs) != 4 || len(obj.Result.Blocks) != 4 {
+		t.Fatalf("Expected 4 blocks, got %d emitted and %d in result", len(blockIDs), len(obj.Result.Blocks))
+	}
+
+	// Feed the symbols in random order
+	ids := make([]string, 0, len(symbols))
+	for id := range symbols {
+		ids = append(ids, id)
+	}
+	ids = sortStrings(ids)
+	rand.New(rand.NewSource(7)).Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+
+	var out bytes.Buffer
+	dec, err := processor.NewDecoder(obj.Layout, &out)
+	if err != nil {
+		t.Fatalf("Failed to create decoder: %v", err)
+	}
+	for _, id := range ids {
+		if err := dec.AddSymbol(id, symbols[id]); err != nil {
+			t.Fatalf("Failed to add symbol %s: %v", id, err)
+		}
+		if dec.Done() {
+			break
+		}
+	}
+	if err := dec.Close(); err != nil {
+		t.Fatalf("Decoder did not complete: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Fatal("Decoded stream does not match original")
+	}
+}
+
+// decodeThresholdBackend fails to decode a block, with a plain decoding error
+// as the native library does, until its directory holds need symbols
+type decodeThresholdBackend struct {
+	Backend
+	need   int
+	calls  int
+	failed bool
+}
+
+func (b *decodeThresholdBackend) DecodeSymbols(sessionID uintptr, symbolsDir, outputPath, layoutPath string) ErrorCode {
+	b.calls++
+	entries, _ := os.ReadDir(filepath.Join(symbolsDir, blockDirName(0)))
+	b.failed = len(entries) < b.need
+	if b.failed {
+		return CodeDecodingFailed
+	}
+	return b.Backend.DecodeSymbols(sessionID, symbolsDir, outputPath, layoutPath)
+}
+
+func (b *decodeThresholdBackend) LastError(sessionID uintptr) (string, bool) {
+	if b.failed {
+		return "Decoding failed: block 0 could not be decoded", true
+	}
+	return b.Backend.LastError(sessionID)
+}
+
+// Unit test for retrying plain decoding failures in growing batches of symbols
+func TestDecoderRetriesDecodingFailures(t *testing.T) {
+	cfg := DefaultProcessorConfig()
+	cfg.SymbolSize = 64
+	cfg.RedundancyFactor = 0
+	encoder, err := newProcessor(NewPureGoBackend(), cfg)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer encoder.Free()
+
+	data := make([]byte, 20*64)
+	rand.New(rand.NewSource(9)).Read(data)
+	obj, err := encoder.EncodeBytes(data, 0)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	layout, err := ParseLayout(obj.Layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := &layout.Blocks[0]
+	oti, err := block.OTI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := oti.SourceSymbols()
+
+	// Extra symbols stand in for repair symbols, which only the native library
+	// generates: the decoding below only succeeds with some of them
+	ids := append([]string(nil), block.Symbols...)
+	for esi := k; esi < k+8; esi++ {
+		symbol := make([]byte, symbolHeaderSize+int(oti.SymbolSize))
+		symbol[1], symbol[2], symbol[3] = byte(esi>>16), byte(esi>>8), byte(esi)
+		rand.New(rand.NewSource(int64(esi))).Read(symbol[symbolHeaderSize:])
+		id := hashBytes(symbol)
+		obj.Symbols[id] = symbol
+		block.Symbols = append(block.Symbols, id)
+		ids = append(ids, id)
+	}
+	layoutData, err := layout.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		need   int
+		fed    int
+		calls  int
+		closed int
+	}{
+		// Attempts with K, K+1 and K+3 symbols instead of one per symbol
+		{"batched", k + 3, k + 8, 3, 3},
+		// The last symbols arrive after the last attempt: Close retries
+		{"close", k + 2, k + 2, 2, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &decodeThresholdBackend{Backend: NewPureGoBackend(), need: tt.need}
+			processor, err := newProcessor(backend, cfg)
+			if err != nil {
+				t.Fatalf("Failed to create processor: %v", err)
+			}
+			defer processor.Free()
+
+			var out bytes.Buffer
+			dec, err := processor.NewDecoder(layoutData, &out)
+			if err != nil {
+				t.Fatalf("Failed to create decoder: %v", err)
+			}
+			for _, id := range ids[:tt.fed] {
+				if err := dec.AddSymbol(id, obj.Symbols[id]); err != nil {
+					t.Fatalf("Failed to add symbol %s: %v", id, err)
+				}
+			}
+			if backend.calls != tt.calls {
+				t.Fatalf("Expected %d decoding attempts, got %d", tt.calls, backend.calls)
+			}
+			if err := dec.Close(); err != nil {
+				t.Fatalf("Decoder did not complete: %v", err)
+			}
+			if backend.calls != tt.closed {
+				t.Fatalf("Expected %d decoding attempts after Close, got %d", tt.closed, backend.calls)
+			}
+			if !bytes.Equal(out.Bytes(), data) {
+				t.Fatal("Decoded stream does not match original")
+			}
+		})
+	}
+
+	// The failure the retries rely on is not classified as insufficient symbols
+	err = newRaptorQError("DecodeSymbols", CodeDecodingFailed, func() string {
+		return "Decoding failed: block 0 could not be decoded"
+	})
+	if !errors.Is(err, ErrDecodingFailed) || errors.Is(err, ErrInsufficientSymbols) {
+		t.Fatalf("Expected a plain decoding failure, got %v", err)
+	}
+}
@@ -1,7 +0,0 @@
-module main
-
-go 1.21
-
-require github.com/LumeraProtocol/rq-go v0.2.1
-
-//replace github.com/LumeraProtocol/rq-go => ../
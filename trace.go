@@ -0,0 +1,250 @@
+package rq_go
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// Tracer starts the spans tracing the operations of the package.
+//
+// The spans form the following tree, each level being the parent of the next
+// through the context handed to Start:
+//
+//   - An operation span per call of EncodeFile, CreateMetadata, DecodeSymbols,
+//     their Context variants, DecodeFromStore and GetRecommendedBlockSize,
+//     named "rq." followed by the operation, e.g. "rq.EncodeFile".
+//   - A "rq.block" span per block, for the operations that process a file
+//     block by block. It covers staging the block on disk, fetching its
+//     symbols and the native call.
+//   - A span per call into the native library, named "rq.native." followed
+//     by the function, e.g. "rq.native.DecodeSymbols".
+//
+// Acquiring a session from a ProcessorPool is traced by a "rq.AcquireConfig"
+// span, so that the time spent waiting for the pool limits is visible.
+//
+// Install an implementation with SetTracer. The package does not depend on a
+// tracing library; an adapter for OpenTelemetry converts the attributes and
+// forwards the calls to a trace.Tracer, see the README.
+//
+// Start may be called from many goroutines at once and must be safe for
+// concurrent use. The methods of a Span are called from a single goroutine.
+type Tracer interface {
+	// Start starts a span named name as a child of the span of ctx, if any,
+	// and returns a context carrying the new span.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// SetAttributes sets attributes of the span.
+	SetAttributes(attrs ...Attribute)
+
+	// RecordError records that the operation of the span failed with err.
+	RecordError(err error)
+
+	// End completes the span. No method is called after End.
+	End()
+}
+
+// Attribute is a key-value pair describing a span. Value is an int64, a
+// string or a bool.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// The keys of the attributes set on spans.
+const (
+	// AttrSessionID is the ID of the processor session.
+	AttrSessionID = "rq.session_id"
+
+	// AttrFileSize is the size of the file being encoded or decoded, in bytes.
+	AttrFileSize = "rq.file_size"
+
+	// AttrBlockSize is the block size used to split the file, in bytes, or
+	// the size of the block of a "rq.block" span.
+	AttrBlockSize = "rq.block_size"
+
+	// AttrBlocks is the number of blocks of the file.
+	AttrBlocks = "rq.blocks"
+
+	// AttrBlockID is the ID of the block of a "rq.block" span.
+	AttrBlockID = "rq.block_id"
+
+	// AttrSymbols is the number of symbols generated, or listed in the layout
+	// when decoding.
+	AttrSymbols = "rq.symbols"
+
+	// AttrRepairSymbols is the number of repair symbols generated.
+	AttrRepairSymbols = "rq.repair_symbols"
+
+	// AttrResultCode is the ErrorCode of the result, see ErrorCodeOf.
+	AttrResultCode = "rq.result_code"
+
+	// AttrLastError is the session's last error message for failures reported
+	// by the native library, or the error message otherwise.
+	AttrLastError = "rq.last_error"
+
+	// AttrWaited tells whether acquiring a session had to wait for the limits
+	// of the pool.
+	AttrWaited = "rq.waited"
+)
+
+// tracer holds the Tracer installed with SetTracer.
+var tracer atomic.Pointer[Tracer]
+
+// SetTracer installs t to trace all processors of the package. nil removes
+// the installed Tracer. Without a Tracer, no spans are created.
+//
+// Example:
+//
+//	raptorq.SetTracer(otelTracer{otel.Tracer("github.com/LumeraProtocol/rq-go")})
+//	defer raptorq.SetTracer(nil)
+func SetTracer(t Tracer) {
+	if t == nil {
+		tracer.Store(nil)
+		return
+	}
+	tracer.Store(&t)
+}
+
+// loadTracer returns the installed Tracer, or nil.
+func loadTracer() Tracer {
+	if t := tracer.Load(); t != nil {
+		return *t
+	}
+	return nil
+}
+
+// traceSpan is a span started with the installed Tracer, together with the
+// span of the block it is processing. All methods of traceSpan accept a nil
+// receiver, which stands for a span that is not traced.
+type traceSpan struct {
+	span  Span
+	block *traceSpan
+}
+
+// startSpan starts a span with the installed Tracer and returns the context
+// carrying it, or returns ctx and nil if no Tracer is installed.
+func startSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, *traceSpan) {
+	t := loadTracer()
+	if t == nil {
+		return ctx, nil
+	}
+	ctx, span := t.Start(ctx, name, attrs...)
+	return ctx, &traceSpan{span: span}
+}
+
+// startSpan starts a span of the processor, which carries its session ID.
+func (p *RaptorQProcessor) startSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, *traceSpan) {
+	return startSpan(ctx, name, append([]Attribute{intAttr(AttrSessionID, int64(p.SessionID))}, attrs...)...)
+}
+
+// startOperation starts the span of the operation op of the processor.
+func (p *RaptorQProcessor) startOperation(ctx context.Context, op string, attrs ...Attribute) (context.Context, *traceSpan) {
+	return p.startSpan(ctx, "rq."+op, attrs...)
+}
+
+// setAttributes sets attributes of the span.
+func (s *traceSpan) setAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.span.SetAttributes(attrs...)
+}
+
+// startBlock starts the span of a block of size bytes and returns the context
+// carrying it, which ctx must be the context of s.
+func (s *traceSpan) startBlock(ctx context.Context, blockID, size uint64) context.Context {
+	if s == nil {
+		return ctx
+	}
+	s.block.end(nil)
+	ctx, s.block = startSpan(ctx, "rq.block", intAttr(AttrBlockID, int64(blockID)), intAttr(AttrBlockSize, int64(size)))
+	return ctx
+}
+
+// finishBlock sets attrs on the span of the current block and ends it.
+func (s *traceSpan) finishBlock(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.block.setAttributes(attrs...)
+	s.block.end(nil)
+	s.block = nil
+}
+
+// end sets the result of the span and ends it. The span of a block that is
+// still open ends with the same result.
+func (s *traceSpan) end(err error) {
+	if s == nil {
+		return
+	}
+	if s.block != nil {
+		s.block.end(err)
+		s.block = nil
+	}
+	s.span.SetAttributes(intAttr(AttrResultCode, int64(ErrorCodeOf(err))))
+	if err != nil {
+		msg := err.Error()
+		var rqErr *RaptorQError
+		if errors.As(err, &rqErr) && rqErr.Detail != "" {
+			msg = rqErr.Detail
+		}
+		s.span.SetAttributes(Attribute{Key: AttrLastError, Value: msg})
+		s.span.RecordError(err)
+	}
+	s.span.End()
+}
+
+// endResult sets the attributes of result, if the operation succeeded, and
+// ends the span with err.
+func (s *traceSpan) endResult(result *ProcessResult, err error) {
+	if s == nil {
+		return
+	}
+	if err == nil && result != nil {
+		s.span.SetAttributes(resultAttributes(result)...)
+	}
+	s.end(err)
+}
+
+// intAttr returns an attribute with an integer value.
+func intAttr(key string, value int64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// resultAttributes returns the attributes describing the result of an
+// EncodeFile or CreateMetadata call.
+func resultAttributes(result *ProcessResult) []Attribute {
+	var size uint64
+	for _, b := range result.Blocks {
+		size += b.Size
+	}
+	return []Attribute{
+		intAttr(AttrFileSize, int64(size)),
+		intAttr(AttrBlocks, int64(len(result.Blocks))),
+		intAttr(AttrSymbols, int64(result.TotalSymbolsCount)),
+		intAttr(AttrRepairSymbols, int64(result.TotalRepairSymbols)),
+	}
+}
+
+// layoutAttributes returns the attributes describing the file of a layout
+// being decoded.
+func layoutAttributes(layout *Layout) []Attribute {
+	return []Attribute{
+		intAttr(AttrFileSize, int64(layout.TotalSize())),
+		intAttr(AttrBlocks, int64(len(layout.Blocks))),
+		intAttr(AttrSymbols, int64(layout.TotalSymbols())),
+	}
+}
+
+// blockAttributes returns the attributes describing the result of encoding a
+// single block.
+func blockAttributes(result *ProcessResult) []Attribute {
+	return []Attribute{
+		intAttr(AttrSymbols, int64(result.TotalSymbolsCount)),
+		intAttr(AttrRepairSymbols, int64(result.TotalRepairSymbols)),
+	}
+}
@@ -0,0 +1,198 @@
+package rq_go
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// recordedSpan is a span of a recordingTracer
+type recordedSpan struct {
+	name   string
+	parent *recordedSpan
+	attrs  map[string]any
+	err    error
+	ended  bool
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) { s.err = err }
+
+func (s *recordedSpan) End() { s.ended = true }
+
+// recordedSpanKey is the context key of the span started by a recordingTracer
+type recordedSpanKey struct{}
+
+// recordingTracer is a Tracer recording every span it starts
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	parent, _ := ctx.Value(recordedSpanKey{}).(*recordedSpan)
+	s := &recordedSpan{name: name, parent: parent, attrs: make(map[string]any)}
+	s.SetAttributes(attrs...)
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+	return context.WithValue(ctx, recordedSpanKey{}, s), s
+}
+
+// named returns the spans named name
+func (t *recordingTracer) named(name string) []*recordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var spans []*recordedSpan
+	for _, s := range t.spans {
+		if s.name == name {
+			spans = append(spans, s)
+		}
+	}
+	return spans
+}
+
+// reset forgets the spans recorded so far
+func (t *recordingTracer) reset() {
+	t.mu.Lock()
+	t.spans = nil
+	t.mu.Unlock()
+}
+
+// Unit test for the span helpers
+func TestTraceSpan(t *testing.T) {
+	var untraced *traceSpan
+	ctx := context.Background()
+	if got := untraced.startBlock(ctx, 0, 10); got != ctx {
+		t.Error("Expected the context to be unchanged without a Tracer")
+	}
+	untraced.finishBlock()
+	untraced.end(errors.New("boom"))
+	if _, s := startSpan(ctx, "rq.EncodeFile"); s != nil {
+		t.Fatal("Expected no span without a Tracer")
+	}
+
+	tracer := &recordingTracer{}
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	p := &RaptorQProcessor{SessionID: 7}
+	ctx, op := p.startOperation(ctx, "DecodeSymbols")
+	op.startBlock(ctx, 0, 100)
+	op.finishBlock()
+	op.startBlock(ctx, 1, 50)
+	op.end(newRaptorQError("DecodeSymbols", CodeDecodingFailed, func() string { return "not enough symbols" }))
+
+	spans := tracer.named("rq.block")
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 block spans, got %d", len(spans))
+	}
+	root := tracer.named("rq.DecodeSymbols")[0]
+	for i, s := range spans {
+		if !s.ended || s.parent != root || s.attrs[AttrBlockID] != int64(i) {
+			t.Errorf("Unexpected block span %d: %+v", i, s)
+		}
+	}
+	if spans[0].err != nil || spans[1].err == nil {
+		t.Error("Expected only the open block to end with the error")
+	}
+	if !root.ended || root.err == nil || root.attrs[AttrSessionID] != int64(7) ||
+		root.attrs[AttrResultCode] != int64(CodeDecodingFailed) || root.attrs[AttrLastError] != "not enough symbols" {
+		t.Errorf("Unexpected operation span: %+v", root)
+	}
+}
+
+// System test tracing encoding and decoding (2MB, 1MB blocks)
+func TestSysTracing(t *testing.T) {
+	tracer := &recordingTracer{}
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	processor, err := NewDefaultRaptorQProcessor()
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	ctx := NewTestContext(t, 2*1024*1024+100)
+	defer ctx.Cleanup()
+	res, err := processor.EncodeFileContext(context.Background(), ctx.InputFile, ctx.SymbolsDir, 1024*1024)
+	if err != nil {
+		t.Fatalf("Failed to encode file: %v", err)
+	}
+
+	root := tracer.named("rq.EncodeFile")
+	if len(root) != 1 {
+		t.Fatalf("Expected one operation span, got %d", len(root))
+	}
+	for key, value := range map[string]any{
+		AttrSessionID:  int64(processor.SessionID),
+		AttrFileSize:   int64(2*1024*1024 + 100),
+		AttrBlockSize:  int64(1024 * 1024),
+		AttrBlocks:     int64(3),
+		AttrSymbols:    int64(res.TotalSymbolsCount),
+		AttrResultCode: int64(CodeSuccess),
+	} {
+		if root[0].attrs[key] != value {
+			t.Errorf("Operation span: %s = %v; expected %v", key, root[0].attrs[key], value)
+		}
+	}
+	blocks := tracer.named("rq.block")
+	native := tracer.named("rq.native.EncodeFile")
+	if len(blocks) != 3 || len(native) != 3 {
+		t.Fatalf("Expected 3 block and native spans, got %d and %d", len(blocks), len(native))
+	}
+	for i := range blocks {
+		if blocks[i].parent != root[0] || native[i].parent != blocks[i] || !blocks[i].ended || !native[i].ended {
+			t.Errorf("Unexpected spans of block %d: %+v, %+v", i, blocks[i], native[i])
+		}
+	}
+
+	// A direct call has a single native span
+	tracer.reset()
+	if _, err := processor.CreateMetadata(ctx.InputFile, filepath.Join(ctx.TempDir, "layout.json"), 0); err != nil {
+		t.Fatalf("Failed to create metadata: %v", err)
+	}
+	if op, native := tracer.named("rq.CreateMetadata"), tracer.named("rq.native.CreateMetadata"); len(op) != 1 || len(native) != 1 || native[0].parent != op[0] {
+		t.Errorf("Expected a native span below the operation span, got %d and %d spans", len(op), len(native))
+	}
+	if len(tracer.named("rq.block")) != 0 {
+		t.Error("Unexpected block spans of a direct call")
+	}
+
+	tracer.reset()
+	processor.GetRecommendedBlockSize(1024)
+	if spans := tracer.named("rq.GetRecommendedBlockSize"); len(spans) != 1 || spans[0].attrs[AttrFileSize] != int64(1024) {
+		t.Errorf("Unexpected GetRecommendedBlockSize spans: %+v", spans)
+	}
+
+	// Decoding without the symbols of a block fails in that block
+	layout, err := LoadLayout(res.LayoutFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range layout.Blocks[1].Symbols {
+		os.Remove(filepath.Join(ctx.SymbolsDir, blockDirName(1), id))
+	}
+	tracer.reset()
+	err = processor.DecodeSymbolsContext(context.Background(), ctx.SymbolsDir, ctx.OutputFile, res.LayoutFilePath)
+	if err == nil {
+		t.Fatal("Expected decoding to fail without symbols")
+	}
+	op := tracer.named("rq.DecodeSymbols")
+	if len(op) != 1 || op[0].err == nil || op[0].attrs[AttrResultCode] != int64(ErrorCodeOf(err)) || op[0].attrs[AttrBlocks] != int64(3) {
+		t.Fatalf("Unexpected operation span: %+v", op)
+	}
+	blocks = tracer.named("rq.block")
+	if len(blocks) != 2 || blocks[0].err != nil || blocks[1].err == nil || blocks[1].attrs[AttrBlockID] != int64(1) {
+		t.Errorf("Expected the second block to fail, got %d block spans", len(blocks))
+	}
+}
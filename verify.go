@@ -0,0 +1,276 @@
+package rq_go
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"lukechampine.com/blake3"
+)
+
+// VerificationReport is the result of VerifySymbols.
+type VerificationReport struct {
+	// Blocks contains one entry per block of the layout, in layout order.
+	Blocks []BlockVerification `json:"blocks"`
+
+	// Decodable is true if every block has enough valid symbols to be decoded.
+	Decodable bool `json:"decodable"`
+}
+
+// BlockVerification describes the state of the symbols of a single block.
+// All symbol lists are sorted.
+type BlockVerification struct {
+	// BlockID is the identifier of the block.
+	BlockID uint64 `json:"block_id"`
+
+	// Directory is the directory the symbols of the block were read from.
+	Directory string `json:"directory"`
+
+	// Expected is the number of distinct symbols listed in the layout.
+	Expected int `json:"expected"`
+
+	// SourceSymbols is the number of source symbols of the block, i.e. the
+	// minimum number of valid symbols needed to decode it.
+	SourceSymbols int `json:"source_symbols"`
+
+	// Valid lists the symbols whose content matches their ID.
+	Valid []string `json:"valid,omitempty"`
+
+	// Missing lists the symbols of the layout that have no file.
+	Missing []string `json:"missing,omitempty"`
+
+	// Corrupt lists the symbols whose file content does not match their ID.
+	Corrupt []string `json:"corrupt,omitempty"`
+
+	// Unexpected lists the files that are not symbols of the block.
+	Unexpected []string `json:"unexpected,omitempty"`
+
+	// Duplicate lists the symbol IDs listed more than once in the layout and
+	// the files holding a copy of a symbol that is already present under its
+	// own name. Duplicates do not count towards Valid.
+	Duplicate []string `json:"duplicate,omitempty"`
+
+	// Decodable is true if Valid holds at least SourceSymbols symbols. RaptorQ
+	// decodes from that many symbols with high probability; each additional
+	// symbol lowers the failure probability by about two orders of magnitude.
+	Decodable bool `json:"decodable"`
+}
+
+// Healthy reports whether every symbol of the block is present and intact and
+// no stray files were found.
+func (b *BlockVerification) Healthy() bool {
+	return len(b.Missing) == 0 && len(b.Corrupt) == 0 && len(b.Unexpected) == 0 && len(b.Duplicate) == 0
+}
+
+// Healthy reports whether every block of the report is healthy.
+func (r *VerificationReport) Healthy() bool {
+	for i := range r.Blocks {
+		if !r.Blocks[i].Healthy() {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifySymbols checks a symbols directory against a layout without decoding.
+//
+// Every file in the directory of each block (block_<id>, or symbolsDir itself
+// when the symbols are not split into block directories) is hashed and
+// compared with the symbol IDs listed in the layout, which are the base58
+// BLAKE3 hashes of the symbol files. Damaged symbols are reported as data in
+// the report, not as errors.
+//
+// Parameters:
+//   - symbolsDir: Directory containing the encoded symbols.
+//   - layout: The layout the symbols were encoded with.
+//
+// Returns:
+//   - *VerificationReport: The state of the symbols of every block.
+//   - error: An error if symbolsDir cannot be read (ErrFileNotFound, ErrIO) or
+//     the encoder parameters of a block cannot be decoded (ErrInvalidLayout).
+//
+// Example:
+//
+//	report, err := raptorq.VerifySymbols("symbols/", layout)
+//	if err != nil {
+//	    return err
+//	}
+//	if !report.Decodable {
+//	    return fmt.Errorf("not enough valid symbols to decode")
+//	}
+func VerifySymbols(symbolsDir string, layout *Layout) (*VerificationReport, error) {
+	if symbolsDir == "" || layout == nil {
+		return nil, fmt.Errorf("%w: symbolsDir and layout cannot be empty", ErrInvalidParameters)
+	}
+	if _, err := os.Stat(symbolsDir); err != nil {
+		return nil, ioError("VerifySymbols", err)
+	}
+
+	report := &VerificationReport{Decodable: true}
+	for _, block := range layout.Blocks {
+		result, err := verifyBlockSymbols(symbolsDir, block)
+		if err != nil {
+			return nil, err
+		}
+		report.Decodable = report.Decodable && result.Decodable
+		report.Blocks = append(report.Blocks, *result)
+	}
+	return report, nil
+}
+
+// verifyBlockSymbols checks the symbol files of a single block.
+func verifyBlockSymbols(symbolsDir string, block BlockLayout) (*BlockVerification, error) {
+	oti, err := block.OTI()
+	if err != nil {
+		return nil, fmt.Errorf("block %d: %w", block.BlockID, err)
+	}
+
+	dir := blockSymbolsDir(symbolsDir, block.BlockID)
+	result := &BlockVerification{
+		BlockID:       block.BlockID,
+		Directory:     dir,
+		SourceSymbols: oti.SourceSymbols(),
+	}
+
+	listed := make(map[string]bool, len(block.Symbols))
+	for _, id := range block.Symbols {
+		if listed[id] {
+			result.Duplicate = append(result.Duplicate, id)
+			continue
+		}
+		listed[id] = true
+	}
+	result.Expected = len(listed)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, ioError("VerifySymbols", err)
+	}
+
+	// Hash all files first: a copy stored under another name is only a
+	// duplicate if the symbol is also present under its own name.
+	present := make(map[string]bool)
+	contentOf := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == layoutFileName {
+			continue
+		}
+		hash, err := hashFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, ioError("VerifySymbols", err)
+		}
+		contentOf[name] = hash
+		if listed[name] {
+			present[name] = true
+		}
+	}
+
+	for name, hash := range contentOf {
+		switch {
+		case listed[name] && hash == name:
+			result.Valid = append(result.Valid, name)
+		case listed[name]:
+			result.Corrupt = append(result.Corrupt, name)
+		case listed[hash] && present[hash]:
+			result.Duplicate = append(result.Duplicate, name)
+		default:
+			result.Unexpected = append(result.Unexpected, name)
+		}
+	}
+	for id := range listed {
+		if !present[id] {
+			result.Missing = append(result.Missing, id)
+		}
+	}
+
+	for _, list := range [][]string{result.Valid, result.Missing, result.Corrupt, result.Unexpected, result.Duplicate} {
+		sort.Strings(list)
+	}
+	result.Decodable = len(result.Valid) >= result.SourceSymbols
+
+	return result, nil
+}
+
+// VerifyDecodedFile checks a decoded file against the block hashes of its
+// layout.
+//
+// The file must have exactly the size described by the layout, and the BLAKE3
+// hash of every block range must match the block's hash.
+//
+// Parameters:
+//   - outputPath: Path of the decoded file.
+//   - layout: The layout the file was decoded with.
+//
+// Returns:
+//   - error: nil if the file matches. Otherwise an error matching
+//     ErrHashMismatch that names every mismatching block, or an I/O error
+//     (ErrFileNotFound, ErrIO) if the file cannot be read.
+func VerifyDecodedFile(outputPath string, layout *Layout) error {
+	if outputPath == "" || layout == nil {
+		return fmt.Errorf("%w: outputPath and layout cannot be empty", ErrInvalidParameters)
+	}
+
+	f, err := os.Open(outputPath)
+	if err != nil {
+		return ioError("VerifyDecodedFile", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return ioError("VerifyDecodedFile", err)
+	}
+	if size := uint64(info.Size()); size != layout.TotalSize() {
+		return fmt.Errorf("%w: %s is %d bytes, layout describes %d bytes",
+			ErrHashMismatch, outputPath, size, layout.TotalSize())
+	}
+
+	var errs []error
+	for _, block := range layout.Blocks {
+		hash, err := hashReader(io.NewSectionReader(f, int64(block.OriginalOffset), int64(block.Size)))
+		if err != nil {
+			return ioError("VerifyDecodedFile", err)
+		}
+		if hash != block.Hash {
+			errs = append(errs, fmt.Errorf("%w: block %d: expected %s, got %s",
+				ErrHashMismatch, block.BlockID, block.Hash, hash))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// hashFile returns the base58-encoded BLAKE3 hash of a file, the form used for
+// symbol IDs and block hashes.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return hashReader(f)
+}
+
+// hashReader returns the base58-encoded BLAKE3 hash of everything read from r.
+func hashReader(r io.Reader) (string, error) {
+	h := blake3.New(HashSize, nil)
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return base58Encode(h.Sum(nil)), nil
+}
+
+// ContentHash returns the base58-encoded BLAKE3 hash of data, the form used
+// for symbol IDs and block hashes in layouts.
+func ContentHash(data []byte) string {
+	return hashBytes(data)
+}
+
+// hashBytes returns the base58-encoded BLAKE3 hash of data.
+func hashBytes(data []byte) string {
+	sum := blake3.Sum256(data)
+	return base58Encode(sum[:])
+}
@@ -0,0 +1,187 @@
+package rq_go
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestSymbols creates a block directory with one file per symbol content
+// and returns the symbol IDs
+func writeTestSymbols(t *testing.T, dir string, contents ...string) []string {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create %s: %v", dir, err)
+	}
+	var ids []string
+	for _, content := range contents {
+		id := hashBytes([]byte(content))
+		if err := os.WriteFile(filepath.Join(dir, id), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write symbol: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Unit test for classifying symbol files against a layout
+func TestVerifySymbols(t *testing.T) {
+	symbolsDir := t.TempDir()
+	blockDir := filepath.Join(symbolsDir, blockDirName(0))
+	ids := writeTestSymbols(t, blockDir, "symbol-a", "symbol-b", "symbol-c", "symbol-d")
+	missing := hashBytes([]byte("never written"))
+
+	// 128 bytes with 64-byte symbols: 2 source symbols needed
+	layout := &Layout{Blocks: []BlockLayout{{
+		BlockID: 0,
+		EncoderParameters: ObjectTransmissionInfo{
+			TransferLength: 128, SymbolSize: 64, SourceBlocks: 1, SubBlocks: 1, Alignment: 8,
+		}.Bytes(),
+		Size:    128,
+		Symbols: []string{ids[0], ids[1], ids[2], ids[3], missing, ids[0]},
+	}}}
+
+	// Corrupt one symbol, copy another under a new name and add a stray file
+	if err := os.WriteFile(filepath.Join(blockDir, ids[1]), []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(blockDir, "copy"), []byte("symbol-c"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(blockDir, "stray"), []byte("stray"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := VerifySymbols(symbolsDir, layout)
+	if err != nil {
+		t.Fatalf("Failed to verify symbols: %v", err)
+	}
+	if len(report.Blocks) != 1 {
+		t.Fatalf("Expected 1 block, got %d", len(report.Blocks))
+	}
+	block := report.Blocks[0]
+
+	if block.Expected != 5 || block.SourceSymbols != 2 {
+		t.Fatalf("Expected 5 listed and 2 source symbols, got %d and %d", block.Expected, block.SourceSymbols)
+	}
+	if len(block.Valid) != 3 {
+		t.Fatalf("Expected 3 valid symbols, got %v", block.Valid)
+	}
+	if len(block.Corrupt) != 1 || block.Corrupt[0] != ids[1] {
+		t.Fatalf("Expected %s to be corrupt, got %v", ids[1], block.Corrupt)
+	}
+	if len(block.Missing) != 1 || block.Missing[0] != missing {
+		t.Fatalf("Expected %s to be missing, got %v", missing, block.Missing)
+	}
+	if len(block.Unexpected) != 1 || block.Unexpected[0] != "stray" {
+		t.Fatalf("Expected the stray file to be unexpected, got %v", block.Unexpected)
+	}
+	// ids[0] is listed twice and "copy" duplicates ids[2]
+	if len(block.Duplicate) != 2 {
+		t.Fatalf("Expected 2 duplicates, got %v", block.Duplicate)
+	}
+	if !block.Decodable || !report.Decodable || report.Healthy() {
+		t.Fatalf("Expected a decodable but unhealthy report: %+v", report)
+	}
+
+	// Removing valid symbols below the source symbol count makes the block undecodable
+	os.Remove(filepath.Join(blockDir, ids[0]))
+	os.Remove(filepath.Join(blockDir, ids[2]))
+	report, err = VerifySymbols(symbolsDir, layout)
+	if err != nil {
+		t.Fatalf("Failed to verify symbols: %v", err)
+	}
+	if report.Decodable {
+		t.Fatalf("Expected an undecodable report: %+v", report.Blocks[0])
+	}
+
+	if _, err := VerifySymbols(filepath.Join(symbolsDir, "nope"), layout); !errors.Is(err, ErrFileNotFound) {
+		t.Fatalf("Expected ErrFileNotFound, got: %v", err)
+	}
+}
+
+// Unit test for checking a decoded file against block hashes
+func TestVerifyDecodedFile(t *testing.T) {
+	data := []byte("first block|second block")
+	layout := &Layout{Blocks: []BlockLayout{
+		{BlockID: 0, OriginalOffset: 0, Size: 12, Hash: hashBytes(data[:12])},
+		{BlockID: 1, OriginalOffset: 12, Size: uint64(len(data) - 12), Hash: hashBytes(data[12:])},
+	}}
+
+	path := filepath.Join(t.TempDir(), "output.bin")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyDecodedFile(path, layout); err != nil {
+		t.Fatalf("Expected matching hashes, got: %v", err)
+	}
+
+	data[20] ^= 0xff
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := VerifyDecodedFile(path, layout)
+	if !errors.Is(err, ErrHashMismatch) {
+		t.Fatalf("Expected ErrHashMismatch, got: %v", err)
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 1 {
+		t.Fatalf("Expected only block 1 to mismatch, got: %v", err)
+	}
+
+	if err := os.WriteFile(path, data[:10], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyDecodedFile(path, layout); !errors.Is(err, ErrHashMismatch) {
+		t.Fatalf("Expected ErrHashMismatch for a truncated file, got: %v", err)
+	}
+}
+
+// System test for verifying encoded symbols and the decoded file (3MB, 1MB blocks)
+func TestSysVerifySymbols(t *testing.T) {
+	processor, err := NewDefaultRaptorQProcessor()
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+	defer processor.Free()
+
+	ctx := NewTestContext(t, 3*1024*1024)
+	defer ctx.Cleanup()
+
+	res, err := processor.EncodeFile(ctx.InputFile, ctx.SymbolsDir, 1024*1024)
+	if err != nil {
+		t.Fatalf("Failed to encode file: %v", err)
+	}
+	layout, err := LoadLayout(res.LayoutFilePath)
+	if err != nil {
+		t.Fatalf("Failed to load layout: %v", err)
+	}
+
+	report, err := VerifySymbols(ctx.SymbolsDir, layout)
+	if err != nil {
+		t.Fatalf("Failed to verify symbols: %v", err)
+	}
+	if !report.Healthy() || !report.Decodable {
+		t.Fatalf("Expected freshly encoded symbols to be healthy: %+v", report)
+	}
+
+	// Corrupt one symbol of the first block
+	corrupt := filepath.Join(ctx.SymbolsDir, blockDirName(0), layout.Blocks[0].Symbols[0])
+	if err := os.WriteFile(corrupt, []byte("corrupted"), 0644); err != nil {
+		t.Fatalf("Failed to corrupt symbol: %v", err)
+	}
+	report, err = VerifySymbols(ctx.SymbolsDir, layout)
+	if err != nil {
+		t.Fatalf("Failed to verify symbols: %v", err)
+	}
+	if len(report.Blocks[0].Corrupt) != 1 || !report.Decodable {
+		t.Fatalf("Expected one corrupt symbol in a decodable block: %+v", report.Blocks[0])
+	}
+	os.Remove(corrupt)
+
+	if err := processor.DecodeSymbols(ctx.SymbolsDir, ctx.OutputFile, res.LayoutFilePath); err != nil {
+		t.Fatalf("Failed to decode symbols: %v", err)
+	}
+	if err := VerifyDecodedFile(ctx.OutputFile, layout); err != nil {
+		t.Fatalf("Decoded file does not match block hashes: %v", err)
+	}
+}